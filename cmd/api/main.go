@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -31,7 +32,10 @@ func main() {
 	}
 	defer pool.Close()
 
-	srv := aphttp.NewServer(cfg, pool)
+	srv, err := aphttp.NewServer(cfg, pool)
+	if err != nil {
+		log.Fatalf("server: %v", err)
+	}
 
 	httpSrv := &http.Server{
 		Addr:              ":" + cfg.Port,
@@ -39,6 +43,28 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	startWorker := func(enabled bool, run func(context.Context)) {
+		if !enabled {
+			return
+		}
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+	startWorker(cfg.WorkerEnabled, srv.RunWorker)
+	startWorker(cfg.BulkAddEnabled, srv.RunBulkAddWorker)
+	startWorker(cfg.Hooks.DeliveryEnabled, srv.RunHookDeliveries)
+	startWorker(cfg.RefreshEnabled, srv.RunRefresher)
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+
 	go func() {
 		log.Printf("api listening on %s", httpSrv.Addr)
 		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -54,4 +80,10 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 10*time.Second)
 	defer shutdownCancel()
 	_ = httpSrv.Shutdown(shutdownCtx)
+
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+	}
 }
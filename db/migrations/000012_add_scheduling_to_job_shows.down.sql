@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_job_shows_pending_run_after;
+
+ALTER TABLE job_shows
+DROP COLUMN IF EXISTS locked_by,
+DROP COLUMN IF EXISTS locked_at,
+DROP COLUMN IF EXISTS run_after;
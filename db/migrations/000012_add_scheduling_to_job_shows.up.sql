@@ -0,0 +1,7 @@
+ALTER TABLE job_shows
+ADD COLUMN run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+ADD COLUMN locked_at TIMESTAMPTZ,
+ADD COLUMN locked_by TEXT;
+
+CREATE INDEX idx_job_shows_pending_run_after ON job_shows (run_after)
+WHERE status = 'pending';
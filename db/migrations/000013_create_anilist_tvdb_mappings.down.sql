@@ -0,0 +1 @@
+DROP TABLE IF EXISTS anilist_tvdb_mappings;
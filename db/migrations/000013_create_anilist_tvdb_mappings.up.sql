@@ -0,0 +1,14 @@
+CREATE TABLE anilist_tvdb_mappings (
+  anilist_id BIGINT PRIMARY KEY,
+  tvdb_id BIGINT NOT NULL,
+  season_number BIGINT NOT NULL DEFAULT 1,
+  episode_offset BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT anilist_tvdb_mappings_anilist_id_positive CHECK (anilist_id > 0),
+  CONSTRAINT anilist_tvdb_mappings_tvdb_id_positive CHECK (tvdb_id > 0),
+  CONSTRAINT anilist_tvdb_mappings_season_number_non_negative CHECK (season_number >= 0),
+  CONSTRAINT anilist_tvdb_mappings_episode_offset_non_negative CHECK (episode_offset >= 0)
+);
+
+CREATE INDEX idx_anilist_tvdb_mappings_tvdb_id ON anilist_tvdb_mappings (tvdb_id);
@@ -0,0 +1,28 @@
+CREATE TABLE show_external_ids (
+  provider TEXT NOT NULL CHECK (provider IN ('anilist', 'anidb', 'tvdb', 'mal', 'imdb', 'tmdb')),
+  external_id TEXT NOT NULL CHECK (external_id <> ''),
+  show_id UUID NOT NULL REFERENCES shows(internal_show_id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (provider, external_id)
+);
+
+CREATE INDEX idx_show_external_ids_show_id ON show_external_ids (show_id);
+
+INSERT INTO show_external_ids (provider, external_id, show_id, created_at)
+SELECT DISTINCT ON (ref.provider, ref.external_id)
+  ref.provider,
+  ref.external_id,
+  ref.internal_show_id,
+  ref.created_at
+FROM (
+  SELECT
+    lower(split_part(s.external_ids->>'externalId', ':', 1)) AS provider,
+    substr(s.external_ids->>'externalId', strpos(s.external_ids->>'externalId', ':') + 1) AS external_id,
+    s.internal_show_id,
+    s.created_at
+  FROM shows s
+  WHERE strpos(COALESCE(s.external_ids->>'externalId', ''), ':') > 1
+) ref
+WHERE ref.provider IN ('anilist', 'anidb', 'tvdb', 'mal', 'imdb', 'tmdb')
+  AND ref.external_id <> ''
+ORDER BY ref.provider, ref.external_id, ref.created_at ASC;
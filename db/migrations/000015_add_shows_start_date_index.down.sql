@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_shows_start_date;
@@ -0,0 +1 @@
+CREATE INDEX idx_shows_start_date ON shows (start_date);
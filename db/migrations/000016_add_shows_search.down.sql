@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_shows_alt_titles_trgm;
+DROP INDEX IF EXISTS idx_shows_title_original_trgm;
+DROP INDEX IF EXISTS idx_shows_title_preferred_trgm;
+DROP INDEX IF EXISTS idx_shows_search_vector;
+DROP FUNCTION IF EXISTS show_search_vector(TEXT, TEXT, TEXT[], TEXT);
+DROP FUNCTION IF EXISTS show_alt_titles_text(TEXT[]);
@@ -0,0 +1,37 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- array_to_string is only STABLE, but joining a TEXT[] does not depend on any setting,
+-- so the wrapper is safe to mark IMMUTABLE and use in index expressions.
+CREATE FUNCTION show_alt_titles_text(alt_titles TEXT[])
+RETURNS TEXT
+LANGUAGE sql
+IMMUTABLE
+PARALLEL SAFE
+AS $$
+  SELECT COALESCE(array_to_string(alt_titles, ' '), '')
+$$;
+
+CREATE FUNCTION show_search_vector(
+  title_preferred TEXT,
+  title_original TEXT,
+  alt_titles TEXT[],
+  synopsis TEXT
+)
+RETURNS tsvector
+LANGUAGE sql
+IMMUTABLE
+PARALLEL SAFE
+AS $$
+  SELECT
+    setweight(to_tsvector('simple'::regconfig, COALESCE(title_preferred, '')), 'A')
+    || setweight(to_tsvector('simple'::regconfig, COALESCE(title_original, '')), 'A')
+    || setweight(to_tsvector('simple'::regconfig, show_alt_titles_text(alt_titles)), 'B')
+    || setweight(to_tsvector('simple'::regconfig, COALESCE(synopsis, '')), 'C')
+$$;
+
+CREATE INDEX idx_shows_search_vector ON shows
+USING GIN (show_search_vector(title_preferred, title_original, alt_titles, synopsis));
+
+CREATE INDEX idx_shows_title_preferred_trgm ON shows USING GIN (title_preferred gin_trgm_ops);
+CREATE INDEX idx_shows_title_original_trgm ON shows USING GIN (title_original gin_trgm_ops);
+CREATE INDEX idx_shows_alt_titles_trgm ON shows USING GIN (show_alt_titles_text(alt_titles) gin_trgm_ops);
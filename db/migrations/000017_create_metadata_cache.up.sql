@@ -0,0 +1,12 @@
+CREATE TABLE metadata_cache (
+  cache_key TEXT PRIMARY KEY,
+  provider TEXT NOT NULL,
+  method TEXT NOT NULL,
+  external_id TEXT,
+  payload JSONB NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_metadata_cache_provider_external_id ON metadata_cache (provider, external_id);
+CREATE INDEX idx_metadata_cache_expires_at ON metadata_cache (expires_at);
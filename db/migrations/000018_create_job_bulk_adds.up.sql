@@ -0,0 +1,15 @@
+CREATE TABLE job_bulk_adds (
+  internal_job_bulk_add_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+  items JSONB NOT NULL,
+  on_conflict TEXT NOT NULL DEFAULT '',
+  import_episodes BOOLEAN NOT NULL DEFAULT TRUE,
+  result JSONB,
+  error_message TEXT,
+  locked_at TIMESTAMPTZ,
+  locked_by TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_bulk_adds_status_created_at ON job_bulk_adds (status, created_at);
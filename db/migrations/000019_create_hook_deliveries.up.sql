@@ -0,0 +1,19 @@
+CREATE TABLE hook_deliveries (
+  internal_hook_delivery_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  event_name TEXT NOT NULL,
+  target_url TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'delivered', 'dead')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_error TEXT,
+  last_status_code INTEGER,
+  locked_at TIMESTAMPTZ,
+  locked_by TEXT,
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_hook_deliveries_status_next_attempt_at ON hook_deliveries (status, next_attempt_at);
+CREATE INDEX idx_hook_deliveries_created_at ON hook_deliveries (created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE hook_settings
+  DROP COLUMN IF EXISTS previous_signing_secret_expires_at,
+  DROP COLUMN IF EXISTS previous_signing_secret,
+  DROP COLUMN IF EXISTS signing_secret;
@@ -0,0 +1,4 @@
+ALTER TABLE hook_settings
+  ADD COLUMN signing_secret TEXT NOT NULL DEFAULT '',
+  ADD COLUMN previous_signing_secret TEXT NOT NULL DEFAULT '',
+  ADD COLUMN previous_signing_secret_expires_at TIMESTAMPTZ;
@@ -0,0 +1,40 @@
+CREATE TABLE hook_settings (
+  event_name TEXT PRIMARY KEY,
+  target_url TEXT NOT NULL DEFAULT '',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  signing_secret TEXT NOT NULL DEFAULT '',
+  previous_signing_secret TEXT NOT NULL DEFAULT '',
+  previous_signing_secret_expires_at TIMESTAMPTZ
+);
+
+-- Only the oldest subscription of each event fits the single-URL model.
+INSERT INTO hook_settings (
+  event_name,
+  target_url,
+  updated_at,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at
+)
+SELECT DISTINCT ON (event_name)
+  event_name,
+  target_url,
+  updated_at,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at
+FROM hook_subscriptions
+ORDER BY event_name, created_at;
+
+ALTER TABLE hook_deliveries ADD COLUMN target_url TEXT NOT NULL DEFAULT '';
+
+UPDATE hook_deliveries delivery
+SET target_url = subscription.target_url
+FROM hook_subscriptions subscription
+WHERE subscription.internal_hook_subscription_id = delivery.subscription_id;
+
+ALTER TABLE hook_deliveries
+  ALTER COLUMN target_url DROP DEFAULT,
+  DROP COLUMN subscription_id;
+
+DROP TABLE IF EXISTS hook_subscriptions;
@@ -0,0 +1,54 @@
+CREATE TABLE hook_subscriptions (
+  internal_hook_subscription_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  event_name TEXT NOT NULL,
+  target_url TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  headers JSONB NOT NULL DEFAULT '{}'::jsonb,
+  timeout_ms INTEGER NOT NULL DEFAULT 5000 CHECK (timeout_ms BETWEEN 100 AND 30000),
+  filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+  signing_secret TEXT NOT NULL DEFAULT '',
+  previous_signing_secret TEXT NOT NULL DEFAULT '',
+  previous_signing_secret_expires_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_hook_subscriptions_event_name_created_at ON hook_subscriptions (event_name, created_at);
+
+-- Every configured single-URL hook becomes one subscription, keeping its secrets.
+INSERT INTO hook_subscriptions (
+  event_name,
+  target_url,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  created_at,
+  updated_at
+)
+SELECT
+  event_name,
+  target_url,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  updated_at,
+  updated_at
+FROM hook_settings
+WHERE target_url <> '';
+
+-- subscription_id is NULL for deliveries whose subscription is gone: hooks whose URL had
+-- been cleared before this migration and subscriptions deleted since. They are kept as
+-- history, and the delivery worker marks any still pending as dead.
+ALTER TABLE hook_deliveries
+  ADD COLUMN subscription_id UUID REFERENCES hook_subscriptions (internal_hook_subscription_id) ON DELETE SET NULL;
+
+UPDATE hook_deliveries delivery
+SET subscription_id = subscription.internal_hook_subscription_id
+FROM hook_subscriptions subscription
+WHERE subscription.event_name = delivery.event_name;
+
+ALTER TABLE hook_deliveries DROP COLUMN target_url;
+
+CREATE INDEX idx_hook_deliveries_subscription_id ON hook_deliveries (subscription_id);
+
+DROP TABLE hook_settings;
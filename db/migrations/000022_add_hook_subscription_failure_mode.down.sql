@@ -0,0 +1,2 @@
+ALTER TABLE hook_subscriptions
+  DROP COLUMN IF EXISTS failure_mode;
@@ -0,0 +1,2 @@
+ALTER TABLE hook_subscriptions
+  ADD COLUMN failure_mode TEXT NOT NULL DEFAULT 'closed' CHECK (failure_mode IN ('open', 'closed'));
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_shows_title_sort;
+DROP INDEX IF EXISTS idx_shows_updated_at;
+DROP INDEX IF EXISTS idx_shows_created_at;
+
+DROP INDEX IF EXISTS idx_shows_type_status;
+CREATE INDEX idx_shows_type_status ON shows (type, status);
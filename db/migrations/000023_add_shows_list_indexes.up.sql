@@ -0,0 +1,10 @@
+-- GET /shows orders on one sort column plus internal_show_id and continues after a cursor
+-- on the same pair. idx_shows_type_status is extended with the default created sort so the
+-- type and status filters still lead it, each other sort gets one index on its pair, and
+-- the start date sort reads idx_shows_start_date from 000015.
+DROP INDEX IF EXISTS idx_shows_type_status;
+CREATE INDEX idx_shows_type_status ON shows (type, status, created_at DESC, internal_show_id DESC);
+
+CREATE INDEX idx_shows_created_at ON shows (created_at DESC, internal_show_id DESC);
+CREATE INDEX idx_shows_updated_at ON shows (updated_at DESC, internal_show_id DESC);
+CREATE INDEX idx_shows_title_sort ON shows (lower(title_preferred), internal_show_id);
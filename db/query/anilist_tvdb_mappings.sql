@@ -0,0 +1,48 @@
+-- name: ListAnilistTvdbMappings :many
+SELECT
+  anilist_id,
+  tvdb_id,
+  season_number,
+  episode_offset,
+  created_at,
+  updated_at
+FROM anilist_tvdb_mappings
+ORDER BY anilist_id ASC;
+
+-- name: GetAnilistTvdbMapping :one
+SELECT
+  anilist_id,
+  tvdb_id,
+  season_number,
+  episode_offset,
+  created_at,
+  updated_at
+FROM anilist_tvdb_mappings
+WHERE anilist_id = $1;
+
+-- name: UpsertAnilistTvdbMapping :one
+INSERT INTO anilist_tvdb_mappings (
+  anilist_id,
+  tvdb_id,
+  season_number,
+  episode_offset
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (anilist_id) DO UPDATE
+SET
+  tvdb_id = EXCLUDED.tvdb_id,
+  season_number = EXCLUDED.season_number,
+  episode_offset = EXCLUDED.episode_offset,
+  updated_at = NOW()
+RETURNING
+  anilist_id,
+  tvdb_id,
+  season_number,
+  episode_offset,
+  created_at,
+  updated_at;
+
+-- name: DeleteAnilistTvdbMapping :one
+DELETE FROM anilist_tvdb_mappings
+WHERE anilist_id = $1
+RETURNING anilist_id;
@@ -52,6 +52,50 @@ FROM episodes
 WHERE show_id = $1::uuid
 ORDER BY season_number ASC, episode_number ASC;
 
+-- name: ListShowEpisodesPage :many
+SELECT
+  internal_episode_id,
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids,
+  created_at,
+  updated_at
+FROM episodes
+WHERE show_id = sqlc.arg(show_id)::uuid
+  AND (sqlc.narg(season_number)::bigint IS NULL OR season_number = sqlc.narg(season_number)::bigint)
+  AND (sqlc.narg(aired_after)::text IS NULL OR air_date > sqlc.narg(aired_after)::text)
+  AND (sqlc.narg(aired_before)::text IS NULL OR air_date < sqlc.narg(aired_before)::text)
+  AND (
+    sqlc.narg(cursor_season)::bigint IS NULL
+    OR (season_number, episode_number) > (sqlc.narg(cursor_season)::bigint, sqlc.narg(cursor_episode)::bigint)
+  )
+ORDER BY season_number ASC, episode_number ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: CountShowEpisodes :one
+SELECT COUNT(*)
+FROM episodes
+WHERE show_id = sqlc.arg(show_id)::uuid
+  AND (sqlc.narg(season_number)::bigint IS NULL OR season_number = sqlc.narg(season_number)::bigint)
+  AND (sqlc.narg(aired_after)::text IS NULL OR air_date > sqlc.narg(aired_after)::text)
+  AND (sqlc.narg(aired_before)::text IS NULL OR air_date < sqlc.narg(aired_before)::text);
+
+-- name: ListSeasonSummaries :many
+SELECT
+  season_number,
+  COUNT(*) AS episode_count,
+  MIN(air_date) AS first_air_date,
+  MAX(air_date) AS last_air_date,
+  COALESCE(SUM(runtime_minutes), 0)::bigint AS total_runtime_minutes
+FROM episodes
+WHERE show_id = $1::uuid
+GROUP BY season_number
+ORDER BY season_number ASC;
+
 -- name: GetEpisodeByID :one
 SELECT
   internal_episode_id,
@@ -96,3 +140,192 @@ RETURNING
 DELETE FROM episodes
 WHERE internal_episode_id = $1::uuid
 RETURNING internal_episode_id;
+
+-- name: InsertEpisodesIfNotExist :many
+INSERT INTO episodes (
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids
+)
+SELECT
+  sqlc.arg(show_id)::uuid,
+  e.season_number,
+  e.episode_number,
+  e.title,
+  e.air_date,
+  e.runtime_minutes,
+  COALESCE(e.external_ids, '{}'::jsonb)
+FROM jsonb_to_recordset(sqlc.arg(episodes)::jsonb) AS e(
+  season_number BIGINT,
+  episode_number BIGINT,
+  title TEXT,
+  air_date TEXT,
+  runtime_minutes BIGINT,
+  external_ids JSONB
+)
+ON CONFLICT (show_id, season_number, episode_number) DO NOTHING
+RETURNING
+  internal_episode_id,
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids,
+  created_at,
+  updated_at;
+
+-- name: UpsertShowEpisodes :many
+WITH input AS (
+  SELECT
+    e.season_number,
+    e.episode_number,
+    e.title,
+    e.air_date,
+    e.runtime_minutes,
+    COALESCE(e.external_ids, '{}'::jsonb) AS external_ids
+  FROM jsonb_to_recordset(sqlc.arg(episodes)::jsonb) AS e(
+    season_number BIGINT,
+    episode_number BIGINT,
+    title TEXT,
+    air_date TEXT,
+    runtime_minutes BIGINT,
+    external_ids JSONB
+  )
+),
+upserted AS (
+  INSERT INTO episodes (
+    show_id,
+    season_number,
+    episode_number,
+    title,
+    air_date,
+    runtime_minutes,
+    external_ids
+  )
+  SELECT
+    sqlc.arg(show_id)::uuid,
+    season_number,
+    episode_number,
+    title,
+    air_date,
+    runtime_minutes,
+    external_ids
+  FROM input
+  ON CONFLICT (show_id, season_number, episode_number) DO UPDATE
+  SET
+    title = EXCLUDED.title,
+    air_date = EXCLUDED.air_date,
+    runtime_minutes = EXCLUDED.runtime_minutes,
+    external_ids = EXCLUDED.external_ids,
+    updated_at = NOW()
+  WHERE (episodes.title, episodes.air_date, episodes.runtime_minutes, episodes.external_ids)
+    IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.air_date, EXCLUDED.runtime_minutes, EXCLUDED.external_ids)
+  RETURNING
+    internal_episode_id,
+    show_id,
+    season_number,
+    episode_number,
+    title,
+    air_date,
+    runtime_minutes,
+    external_ids,
+    created_at,
+    updated_at,
+    (xmax = 0) AS inserted
+)
+SELECT
+  u.internal_episode_id,
+  u.show_id,
+  u.season_number,
+  u.episode_number,
+  u.title,
+  u.air_date,
+  u.runtime_minutes,
+  u.external_ids,
+  u.created_at,
+  u.updated_at,
+  (CASE WHEN u.inserted THEN 'created' ELSE 'updated' END)::text AS status
+FROM upserted u
+UNION ALL
+SELECT
+  e.internal_episode_id,
+  e.show_id,
+  e.season_number,
+  e.episode_number,
+  e.title,
+  e.air_date,
+  e.runtime_minutes,
+  e.external_ids,
+  e.created_at,
+  e.updated_at,
+  'unchanged'::text AS status
+FROM episodes e
+JOIN input i
+  ON i.season_number = e.season_number
+  AND i.episode_number = e.episode_number
+WHERE e.show_id = sqlc.arg(show_id)::uuid
+  AND NOT EXISTS (
+    SELECT 1
+    FROM upserted u
+    WHERE u.season_number = e.season_number
+      AND u.episode_number = e.episode_number
+  );
+
+-- name: SyncProviderEpisodes :many
+INSERT INTO episodes (
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids
+)
+SELECT
+  sqlc.arg(show_id)::uuid,
+  e.season_number,
+  e.episode_number,
+  e.title,
+  e.air_date,
+  e.runtime_minutes,
+  COALESCE(e.external_ids, '{}'::jsonb)
+FROM jsonb_to_recordset(sqlc.arg(episodes)::jsonb) AS e(
+  season_number BIGINT,
+  episode_number BIGINT,
+  title TEXT,
+  air_date TEXT,
+  runtime_minutes BIGINT,
+  external_ids JSONB
+)
+ON CONFLICT (show_id, season_number, episode_number) DO UPDATE
+SET
+  title = EXCLUDED.title,
+  air_date = EXCLUDED.air_date,
+  runtime_minutes = COALESCE(EXCLUDED.runtime_minutes, episodes.runtime_minutes),
+  external_ids = episodes.external_ids || EXCLUDED.external_ids,
+  updated_at = NOW()
+WHERE (episodes.title, episodes.air_date, episodes.runtime_minutes, episodes.external_ids)
+  IS DISTINCT FROM (
+    EXCLUDED.title,
+    EXCLUDED.air_date,
+    COALESCE(EXCLUDED.runtime_minutes, episodes.runtime_minutes),
+    episodes.external_ids || EXCLUDED.external_ids
+  )
+RETURNING
+  internal_episode_id,
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids,
+  created_at,
+  updated_at,
+  (xmax = 0) AS inserted;
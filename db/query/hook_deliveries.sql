@@ -0,0 +1,184 @@
+-- name: CreateHookDelivery :exec
+INSERT INTO hook_deliveries (subscription_id, event_name, payload)
+VALUES (sqlc.arg(subscription_id)::uuid, sqlc.arg(event_name)::text, sqlc.arg(payload)::jsonb);
+
+-- name: GetHookDeliveryByID :one
+SELECT
+  internal_hook_delivery_id,
+  subscription_id,
+  event_name,
+  payload,
+  status,
+  attempts,
+  next_attempt_at,
+  last_error,
+  last_status_code,
+  locked_at,
+  locked_by,
+  delivered_at,
+  created_at,
+  updated_at
+FROM hook_deliveries
+WHERE internal_hook_delivery_id = $1::uuid
+LIMIT 1;
+
+-- name: ListHookDeliveries :many
+SELECT
+  internal_hook_delivery_id,
+  subscription_id,
+  event_name,
+  payload,
+  status,
+  attempts,
+  next_attempt_at,
+  last_error,
+  last_status_code,
+  locked_at,
+  locked_by,
+  delivered_at,
+  created_at,
+  updated_at
+FROM hook_deliveries
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(event_name)::text IS NULL OR event_name = sqlc.narg(event_name)::text)
+  AND (sqlc.narg(subscription_id)::uuid IS NULL OR subscription_id = sqlc.narg(subscription_id)::uuid)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ClaimNextHookDelivery :one
+UPDATE hook_deliveries
+SET
+  status = 'processing',
+  attempts = attempts + 1,
+  locked_at = NOW(),
+  locked_by = sqlc.arg(locked_by)::text,
+  updated_at = NOW()
+WHERE internal_hook_delivery_id = (
+  SELECT candidate.internal_hook_delivery_id
+  FROM hook_deliveries candidate
+  WHERE (candidate.status = 'pending' AND candidate.next_attempt_at <= NOW())
+    OR (candidate.status = 'processing' AND candidate.locked_at < sqlc.arg(stale_before)::timestamptz)
+  ORDER BY candidate.next_attempt_at ASC
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING
+  internal_hook_delivery_id,
+  subscription_id,
+  event_name,
+  payload,
+  status,
+  attempts,
+  next_attempt_at,
+  last_error,
+  last_status_code,
+  locked_at,
+  locked_by,
+  delivered_at,
+  created_at,
+  updated_at;
+
+-- name: MarkHookDeliveryDelivered :one
+UPDATE hook_deliveries
+SET
+  status = 'delivered',
+  last_error = NULL,
+  last_status_code = sqlc.arg(last_status_code)::integer,
+  locked_at = NULL,
+  locked_by = NULL,
+  delivered_at = NOW(),
+  updated_at = NOW()
+WHERE internal_hook_delivery_id = sqlc.arg(internal_hook_delivery_id)::uuid
+RETURNING
+  internal_hook_delivery_id,
+  subscription_id,
+  event_name,
+  payload,
+  status,
+  attempts,
+  next_attempt_at,
+  last_error,
+  last_status_code,
+  locked_at,
+  locked_by,
+  delivered_at,
+  created_at,
+  updated_at;
+
+-- name: RetryHookDelivery :one
+UPDATE hook_deliveries
+SET
+  status = 'pending',
+  last_error = sqlc.arg(last_error)::text,
+  last_status_code = sqlc.narg(last_status_code)::integer,
+  next_attempt_at = sqlc.arg(next_attempt_at)::timestamptz,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_hook_delivery_id = sqlc.arg(internal_hook_delivery_id)::uuid
+RETURNING
+  internal_hook_delivery_id,
+  subscription_id,
+  event_name,
+  payload,
+  status,
+  attempts,
+  next_attempt_at,
+  last_error,
+  last_status_code,
+  locked_at,
+  locked_by,
+  delivered_at,
+  created_at,
+  updated_at;
+
+-- name: KillHookDelivery :one
+UPDATE hook_deliveries
+SET
+  status = 'dead',
+  last_error = sqlc.arg(last_error)::text,
+  last_status_code = sqlc.narg(last_status_code)::integer,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_hook_delivery_id = sqlc.arg(internal_hook_delivery_id)::uuid
+RETURNING
+  internal_hook_delivery_id,
+  subscription_id,
+  event_name,
+  payload,
+  status,
+  attempts,
+  next_attempt_at,
+  last_error,
+  last_status_code,
+  locked_at,
+  locked_by,
+  delivered_at,
+  created_at,
+  updated_at;
+
+-- name: RedeliverHookDelivery :one
+UPDATE hook_deliveries
+SET
+  status = 'pending',
+  attempts = 0,
+  next_attempt_at = NOW(),
+  updated_at = NOW()
+WHERE internal_hook_delivery_id = sqlc.arg(internal_hook_delivery_id)::uuid
+  AND status IN ('delivered', 'dead')
+RETURNING
+  internal_hook_delivery_id,
+  subscription_id,
+  event_name,
+  payload,
+  status,
+  attempts,
+  next_attempt_at,
+  last_error,
+  last_status_code,
+  locked_at,
+  locked_by,
+  delivered_at,
+  created_at,
+  updated_at;
@@ -0,0 +1,142 @@
+-- name: CreateHookSubscription :one
+INSERT INTO hook_subscriptions (event_name, target_url, enabled, headers, timeout_ms, filter, failure_mode, signing_secret)
+VALUES (
+  sqlc.arg(event_name)::text,
+  sqlc.arg(target_url)::text,
+  sqlc.arg(enabled)::boolean,
+  sqlc.arg(headers)::jsonb,
+  sqlc.arg(timeout_ms)::integer,
+  sqlc.arg(filter)::jsonb,
+  sqlc.arg(failure_mode)::text,
+  sqlc.arg(signing_secret)::text
+)
+RETURNING
+  internal_hook_subscription_id,
+  event_name,
+  target_url,
+  enabled,
+  headers,
+  timeout_ms,
+  filter,
+  failure_mode,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  created_at,
+  updated_at;
+
+-- name: GetHookSubscriptionByID :one
+SELECT
+  internal_hook_subscription_id,
+  event_name,
+  target_url,
+  enabled,
+  headers,
+  timeout_ms,
+  filter,
+  failure_mode,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  created_at,
+  updated_at
+FROM hook_subscriptions
+WHERE internal_hook_subscription_id = $1::uuid
+LIMIT 1;
+
+-- name: ListHookSubscriptions :many
+SELECT
+  internal_hook_subscription_id,
+  event_name,
+  target_url,
+  enabled,
+  headers,
+  timeout_ms,
+  filter,
+  failure_mode,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  created_at,
+  updated_at
+FROM hook_subscriptions
+WHERE sqlc.narg(event_name)::text IS NULL OR event_name = sqlc.narg(event_name)::text
+ORDER BY event_name ASC, created_at ASC;
+
+-- name: ListEnabledHookSubscriptionsByEvent :many
+SELECT
+  internal_hook_subscription_id,
+  event_name,
+  target_url,
+  enabled,
+  headers,
+  timeout_ms,
+  filter,
+  failure_mode,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  created_at,
+  updated_at
+FROM hook_subscriptions
+WHERE event_name = $1::text
+  AND enabled
+ORDER BY created_at ASC;
+
+-- name: UpdateHookSubscription :one
+UPDATE hook_subscriptions
+SET
+  event_name = sqlc.arg(event_name)::text,
+  target_url = sqlc.arg(target_url)::text,
+  enabled = sqlc.arg(enabled)::boolean,
+  headers = sqlc.arg(headers)::jsonb,
+  timeout_ms = sqlc.arg(timeout_ms)::integer,
+  filter = sqlc.arg(filter)::jsonb,
+  failure_mode = sqlc.arg(failure_mode)::text,
+  updated_at = NOW()
+WHERE internal_hook_subscription_id = sqlc.arg(internal_hook_subscription_id)::uuid
+RETURNING
+  internal_hook_subscription_id,
+  event_name,
+  target_url,
+  enabled,
+  headers,
+  timeout_ms,
+  filter,
+  failure_mode,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  created_at,
+  updated_at;
+
+-- name: DeleteHookSubscription :one
+DELETE FROM hook_subscriptions
+WHERE internal_hook_subscription_id = $1::uuid
+RETURNING internal_hook_subscription_id;
+
+-- name: RotateHookSubscriptionSecret :one
+UPDATE hook_subscriptions
+SET
+  previous_signing_secret = CASE WHEN sqlc.arg(grace_seconds)::bigint > 0 THEN signing_secret ELSE '' END,
+  previous_signing_secret_expires_at = CASE
+    WHEN sqlc.arg(grace_seconds)::bigint > 0 AND signing_secret <> ''
+      THEN NOW() + make_interval(secs => sqlc.arg(grace_seconds)::bigint)
+  END,
+  signing_secret = sqlc.arg(signing_secret)::text,
+  updated_at = NOW()
+WHERE internal_hook_subscription_id = sqlc.arg(internal_hook_subscription_id)::uuid
+RETURNING
+  internal_hook_subscription_id,
+  event_name,
+  target_url,
+  enabled,
+  headers,
+  timeout_ms,
+  filter,
+  failure_mode,
+  signing_secret,
+  previous_signing_secret,
+  previous_signing_secret_expires_at,
+  created_at,
+  updated_at;
@@ -0,0 +1,106 @@
+-- name: CreateJobBulkAdd :one
+INSERT INTO job_bulk_adds (items, on_conflict, import_episodes)
+VALUES (sqlc.arg(items)::jsonb, sqlc.arg(on_conflict)::text, sqlc.arg(import_episodes)::boolean)
+RETURNING
+  internal_job_bulk_add_id,
+  status,
+  items,
+  on_conflict,
+  import_episodes,
+  result,
+  error_message,
+  locked_at,
+  locked_by,
+  created_at,
+  updated_at;
+
+-- name: GetJobBulkAddByID :one
+SELECT
+  internal_job_bulk_add_id,
+  status,
+  items,
+  on_conflict,
+  import_episodes,
+  result,
+  error_message,
+  locked_at,
+  locked_by,
+  created_at,
+  updated_at
+FROM job_bulk_adds
+WHERE internal_job_bulk_add_id = $1::uuid
+LIMIT 1;
+
+-- name: ClaimNextJobBulkAdd :one
+UPDATE job_bulk_adds
+SET
+  status = 'processing',
+  locked_at = NOW(),
+  locked_by = sqlc.arg(locked_by)::text,
+  updated_at = NOW()
+WHERE internal_job_bulk_add_id = (
+  SELECT candidate.internal_job_bulk_add_id
+  FROM job_bulk_adds candidate
+  WHERE candidate.status = 'pending'
+    OR (candidate.status = 'processing' AND candidate.locked_at < sqlc.arg(stale_before)::timestamptz)
+  ORDER BY candidate.created_at ASC
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING
+  internal_job_bulk_add_id,
+  status,
+  items,
+  on_conflict,
+  import_episodes,
+  result,
+  error_message,
+  locked_at,
+  locked_by,
+  created_at,
+  updated_at;
+
+-- name: CompleteJobBulkAdd :one
+UPDATE job_bulk_adds
+SET
+  status = 'completed',
+  result = sqlc.arg(result)::jsonb,
+  error_message = NULL,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_job_bulk_add_id = sqlc.arg(internal_job_bulk_add_id)::uuid
+RETURNING
+  internal_job_bulk_add_id,
+  status,
+  items,
+  on_conflict,
+  import_episodes,
+  result,
+  error_message,
+  locked_at,
+  locked_by,
+  created_at,
+  updated_at;
+
+-- name: FailJobBulkAdd :one
+UPDATE job_bulk_adds
+SET
+  status = 'failed',
+  error_message = sqlc.arg(error_message)::text,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_job_bulk_add_id = sqlc.arg(internal_job_bulk_add_id)::uuid
+RETURNING
+  internal_job_bulk_add_id,
+  status,
+  items,
+  on_conflict,
+  import_episodes,
+  result,
+  error_message,
+  locked_at,
+  locked_by,
+  created_at,
+  updated_at;
@@ -0,0 +1,242 @@
+-- name: ClaimNextJobShow :one
+UPDATE job_shows
+SET
+  status = 'processing',
+  locked_at = NOW(),
+  locked_by = $1,
+  updated_at = NOW()
+WHERE internal_job_show_id = (
+  SELECT candidate.internal_job_show_id
+  FROM job_shows candidate
+  WHERE candidate.status = 'pending'
+    AND candidate.run_after <= NOW()
+  ORDER BY candidate.run_after ASC, candidate.created_at ASC
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by;
+
+-- name: CompleteJobShow :one
+UPDATE job_shows
+SET
+  status = 'completed',
+  error_message = NULL,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_job_show_id = $1::uuid
+RETURNING
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by;
+
+-- name: RetryJobShow :one
+UPDATE job_shows
+SET
+  status = 'pending',
+  retry_count = retry_count + 1,
+  error_message = $2,
+  run_after = $3,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_job_show_id = $1::uuid
+RETURNING
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by;
+
+-- name: FailJobShow :one
+UPDATE job_shows
+SET
+  status = 'failed',
+  retry_count = retry_count + 1,
+  error_message = $2,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_job_show_id = $1::uuid
+RETURNING
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by;
+
+-- name: FailStaleJobShows :execrows
+UPDATE job_shows stale
+SET
+  status = 'failed',
+  retry_count = stale.retry_count + 1,
+  error_message = sqlc.arg(error_message)::text,
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE stale.status = 'processing'
+  AND stale.locked_at < sqlc.arg(locked_before)::timestamptz
+  AND (
+    stale.retry_count + 1 >= sqlc.arg(max_retries)::int
+    OR EXISTS (
+      SELECT 1
+      FROM job_shows pending
+      WHERE pending.show_id = stale.show_id
+        AND pending.status = 'pending'
+    )
+  );
+
+-- name: ReleaseStaleJobShows :execrows
+UPDATE job_shows stale
+SET
+  status = 'pending',
+  retry_count = stale.retry_count + 1,
+  error_message = sqlc.arg(error_message)::text,
+  run_after = NOW(),
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE stale.status = 'processing'
+  AND stale.locked_at < sqlc.arg(locked_before)::timestamptz
+  AND NOT EXISTS (
+    SELECT 1
+    FROM job_shows pending
+    WHERE pending.show_id = stale.show_id
+      AND pending.status = 'pending'
+  );
+
+-- name: RequeueJobShow :one
+UPDATE job_shows
+SET
+  status = 'pending',
+  run_after = NOW(),
+  locked_at = NULL,
+  locked_by = NULL,
+  updated_at = NOW()
+WHERE internal_job_show_id = $1::uuid
+RETURNING
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by;
+
+-- name: LockJobShowExternalID :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text));
+
+-- name: GetActiveJobShowByExternalID :one
+SELECT
+  j.internal_job_show_id,
+  j.status,
+  j.error_message,
+  j.created_at,
+  j.updated_at,
+  j.retry_count,
+  j.show_id,
+  j.run_after,
+  j.locked_at,
+  j.locked_by
+FROM job_shows j
+JOIN shows s ON s.internal_show_id = j.show_id
+WHERE s.external_ids->>'externalId' = $1::text
+  AND j.status IN ('pending', 'processing')
+ORDER BY j.created_at DESC
+LIMIT 1;
+
+-- name: GetActiveJobShowByShowID :one
+SELECT
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by
+FROM job_shows
+WHERE show_id = $1::uuid
+  AND status IN ('pending', 'processing')
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: CreateJobShow :one
+INSERT INTO job_shows (show_id)
+VALUES ($1::uuid)
+RETURNING
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by;
+
+-- name: GetJobShowByID :one
+SELECT
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by
+FROM job_shows
+WHERE internal_job_show_id = $1::uuid
+LIMIT 1;
+
+-- name: ListJobShows :many
+SELECT
+  internal_job_show_id,
+  status,
+  error_message,
+  created_at,
+  updated_at,
+  retry_count,
+  show_id,
+  run_after,
+  locked_at,
+  locked_by
+FROM job_shows
+WHERE sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit);
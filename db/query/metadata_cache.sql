@@ -0,0 +1,44 @@
+-- name: GetMetadataCacheEntry :one
+SELECT
+  cache_key,
+  provider,
+  method,
+  external_id,
+  payload,
+  expires_at,
+  created_at
+FROM metadata_cache
+WHERE cache_key = $1
+  AND expires_at > NOW();
+
+-- name: UpsertMetadataCacheEntry :exec
+INSERT INTO metadata_cache (
+  cache_key,
+  provider,
+  method,
+  external_id,
+  payload,
+  expires_at
+)
+VALUES (
+  sqlc.arg(cache_key),
+  sqlc.arg(provider),
+  sqlc.arg(method),
+  sqlc.narg(external_id),
+  sqlc.arg(payload),
+  sqlc.arg(expires_at)
+)
+ON CONFLICT (cache_key) DO UPDATE
+SET
+  payload = EXCLUDED.payload,
+  expires_at = EXCLUDED.expires_at,
+  created_at = NOW();
+
+-- name: DeleteMetadataCacheEntries :execrows
+DELETE FROM metadata_cache
+WHERE provider = sqlc.arg(provider)
+  AND (sqlc.narg(external_id)::text IS NULL OR external_id = sqlc.narg(external_id)::text);
+
+-- name: DeleteExpiredMetadataCacheEntries :execrows
+DELETE FROM metadata_cache
+WHERE expires_at <= NOW();
@@ -0,0 +1,80 @@
+-- name: ListShowExternalIDs :many
+SELECT
+  provider,
+  external_id,
+  show_id,
+  created_at
+FROM show_external_ids
+WHERE show_id = $1::uuid
+ORDER BY provider ASC, external_id ASC;
+
+-- name: ListShowExternalIDsForRefs :many
+SELECT
+  provider,
+  external_id,
+  show_id,
+  created_at
+FROM show_external_ids
+WHERE show_id IN (
+  SELECT x.show_id
+  FROM show_external_ids x
+  JOIN unnest(sqlc.arg(providers)::text[], sqlc.arg(external_ids)::text[]) AS ref(provider, external_id)
+    ON ref.provider = x.provider
+    AND ref.external_id = x.external_id
+)
+ORDER BY show_id ASC, provider ASC, external_id ASC;
+
+-- name: GetShowByExternalID :one
+SELECT
+  s.internal_show_id,
+  s.title_preferred,
+  s.title_original,
+  s.alt_titles,
+  s.type,
+  s.status,
+  s.synopsis,
+  s.start_date,
+  s.end_date,
+  s.poster_url,
+  s.banner_url,
+  s.season_count,
+  s.episode_count,
+  s.external_ids,
+  s.created_at,
+  s.updated_at
+FROM show_external_ids x
+JOIN shows s ON s.internal_show_id = x.show_id
+WHERE x.provider = $1
+  AND x.external_id = $2;
+
+-- name: CreateShowExternalID :one
+INSERT INTO show_external_ids (
+  provider,
+  external_id,
+  show_id
+)
+VALUES ($1, $2, $3::uuid)
+RETURNING
+  provider,
+  external_id,
+  show_id,
+  created_at;
+
+-- name: LinkShowExternalID :execrows
+INSERT INTO show_external_ids (
+  provider,
+  external_id,
+  show_id
+)
+VALUES ($1, $2, $3::uuid)
+ON CONFLICT (provider, external_id) DO NOTHING;
+
+-- name: DeleteShowExternalID :one
+DELETE FROM show_external_ids
+WHERE show_id = $1::uuid
+  AND provider = $2
+  AND external_id = $3
+RETURNING external_id;
+
+-- name: LockShowDuplicateKey :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text));
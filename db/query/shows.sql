@@ -54,6 +54,115 @@ SELECT
 FROM shows
 ORDER BY created_at DESC;
 
+-- name: ListShowsByStatus :many
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at
+FROM shows
+WHERE status = $1
+ORDER BY updated_at ASC;
+
+-- name: ListShowsByStartDate :many
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at
+FROM shows
+WHERE start_date = $1
+ORDER BY created_at ASC;
+
+-- name: ListShowsSharingStartDate :many
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at
+FROM shows
+WHERE start_date IN (
+  SELECT start_date
+  FROM shows
+  WHERE start_date IS NOT NULL
+  GROUP BY start_date
+  HAVING COUNT(*) > 1
+)
+ORDER BY start_date ASC, created_at ASC;
+
+-- name: ListShowsSharingExternalID :many
+WITH shared AS (
+  SELECT
+    m.provider || ':' || m.external_id AS shared_external_id,
+    m.show_id AS owner_id,
+    s.internal_show_id AS other_id
+  FROM show_external_ids m
+  JOIN shows s ON s.external_ids->>'externalId' = m.provider || ':' || m.external_id
+  WHERE s.internal_show_id <> m.show_id
+),
+members AS (
+  SELECT shared_external_id, owner_id AS show_id FROM shared
+  UNION
+  SELECT shared_external_id, other_id FROM shared
+)
+SELECT
+  s.internal_show_id,
+  s.title_preferred,
+  s.title_original,
+  s.alt_titles,
+  s.type,
+  s.status,
+  s.synopsis,
+  s.start_date,
+  s.end_date,
+  s.poster_url,
+  s.banner_url,
+  s.season_count,
+  s.episode_count,
+  s.external_ids,
+  s.created_at,
+  s.updated_at,
+  members.shared_external_id::text AS shared_external_id
+FROM members
+JOIN shows s ON s.internal_show_id = members.show_id
+ORDER BY members.shared_external_id ASC, s.created_at ASC;
+
 -- name: GetShowByID :one
 SELECT
   internal_show_id,
@@ -116,3 +225,84 @@ RETURNING
 DELETE FROM shows
 WHERE internal_show_id = $1::uuid
 RETURNING internal_show_id;
+
+-- name: SearchShows :many
+WITH search AS (
+  SELECT
+    websearch_to_tsquery('simple', sqlc.arg(query)::text) AS tsq,
+    sqlc.arg(query)::text AS raw
+),
+scored AS (
+  SELECT
+    s.internal_show_id,
+    s.title_preferred,
+    s.title_original,
+    s.alt_titles,
+    s.type,
+    s.status,
+    s.synopsis,
+    s.start_date,
+    s.end_date,
+    s.poster_url,
+    s.banner_url,
+    s.season_count,
+    s.episode_count,
+    s.external_ids,
+    s.created_at,
+    s.updated_at,
+    (
+      similarity(s.title_preferred, search.raw)
+      + CASE WHEN to_tsvector('simple', s.title_preferred) @@ search.tsq THEN 1 ELSE 0 END
+    )::float8 AS title_preferred_score,
+    (
+      similarity(COALESCE(s.title_original, ''), search.raw)
+      + CASE WHEN to_tsvector('simple', COALESCE(s.title_original, '')) @@ search.tsq THEN 1 ELSE 0 END
+    )::float8 AS title_original_score,
+    (
+      COALESCE((SELECT MAX(similarity(alt, search.raw)) FROM unnest(s.alt_titles) AS alt), 0)
+      + CASE WHEN to_tsvector('simple', show_alt_titles_text(s.alt_titles)) @@ search.tsq THEN 1 ELSE 0 END
+    )::float8 AS alt_titles_score,
+    (CASE WHEN to_tsvector('simple', COALESCE(s.synopsis, '')) @@ search.tsq THEN 0.5 ELSE 0 END)::float8 AS synopsis_score,
+    ts_rank(show_search_vector(s.title_preferred, s.title_original, s.alt_titles, s.synopsis), search.tsq) AS text_rank
+  FROM shows s
+  CROSS JOIN search
+  WHERE show_search_vector(s.title_preferred, s.title_original, s.alt_titles, s.synopsis) @@ search.tsq
+    OR s.title_preferred % search.raw
+    OR s.title_original % search.raw
+    OR search.raw <% show_alt_titles_text(s.alt_titles)
+)
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at,
+  GREATEST(title_preferred_score, title_original_score, alt_titles_score, synopsis_score)::float8 AS score,
+  (
+    CASE GREATEST(title_preferred_score, title_original_score, alt_titles_score, synopsis_score)
+      WHEN title_preferred_score THEN 'titlePreferred'
+      WHEN title_original_score THEN 'titleOriginal'
+      WHEN alt_titles_score THEN 'altTitles'
+      ELSE 'synopsis'
+    END
+  )::text AS matched_field
+FROM scored
+ORDER BY score DESC, text_rank DESC, title_preferred ASC, internal_show_id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: TryLockShowsRefresh :one
+SELECT pg_try_advisory_lock(sqlc.arg(lock_key)::bigint);
+
+-- name: UnlockShowsRefresh :one
+SELECT pg_advisory_unlock(sqlc.arg(lock_key)::bigint);
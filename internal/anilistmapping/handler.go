@@ -0,0 +1,114 @@
+package anilistmapping
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httperr"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
+
+// ListMappings godoc
+//
+//	@Summary		List AniList to TVDB mappings
+//	@Description	List every stored AniList to TVDB cross-reference
+//	@Tags			mappings
+//	@Produce		json
+//	@Success		200	{array}		mappingResponse
+//	@Failure		500	{object}	httperr.APIErrorResponse
+//	@Router			/mappings/anilist-tvdb [get]
+func (h *Handler) ListMappings(c *gin.Context) {
+	items, err := h.svc.ListMappings(c.Request.Context())
+	if err != nil {
+		if httpx.AbortIfDBErr(c, err, "failed to list mappings") {
+			return
+		}
+		httperr.Abort(c, httperr.Internal("failed to list mappings").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toMappingResponses(items))
+}
+
+// GetMapping godoc
+//
+//	@Summary		Get AniList to TVDB mapping
+//	@Description	Get the TVDB series, season and episode offset mapped to an AniList media id
+//	@Tags			mappings
+//	@Produce		json
+//	@Param			anilistId	path		string	true	"AniList media id (123 or anilist:123)"
+//	@Success		200			{object}	mappingResponse
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		404			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Router			/mappings/anilist-tvdb/{anilistId} [get]
+func (h *Handler) GetMapping(c *gin.Context) {
+	anilistID, ok := httpx.AbortIfMissingContext[int64](c, ctxAnilistIDKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.GetMapping(c.Request.Context(), anilistID)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "mapping not found", "failed to get mapping") {
+		return
+	}
+
+	c.JSON(http.StatusOK, toMappingResponse(item))
+}
+
+// UpsertMapping godoc
+//
+//	@Summary		Create or replace AniList to TVDB mapping
+//	@Description	Map an AniList media id to a TVDB series season. AniList episode N becomes episode N+episodeOffset of seasonNumber (default 1).
+//	@Tags			mappings
+//	@Accept			json
+//	@Produce		json
+//	@Param			anilistId	path		string					true	"AniList media id (123 or anilist:123)"
+//	@Param			payload		body		upsertMappingRequest	true	"Mapping payload"
+//	@Success		200			{object}	mappingResponse
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Router			/mappings/anilist-tvdb/{anilistId} [put]
+func (h *Handler) UpsertMapping(c *gin.Context) {
+	anilistID, ok := httpx.AbortIfMissingContext[int64](c, ctxAnilistIDKey)
+	if !ok {
+		return
+	}
+	req, ok := httpx.AbortIfMissingContext[upsertMappingRequest](c, ctxUpsertRequestKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.UpsertMapping(c.Request.Context(), anilistID, req)
+	if httpx.AbortIfDBErr(c, err, "failed to save mapping") {
+		return
+	}
+
+	c.JSON(http.StatusOK, toMappingResponse(item))
+}
+
+// DeleteMapping godoc
+//
+//	@Summary		Delete AniList to TVDB mapping
+//	@Description	Remove the TVDB mapping of an AniList media id
+//	@Tags			mappings
+//	@Produce		json
+//	@Param			anilistId	path	string	true	"AniList media id (123 or anilist:123)"
+//	@Success		204
+//	@Failure		400	{object}	httperr.APIErrorResponse
+//	@Failure		404	{object}	httperr.APIErrorResponse
+//	@Failure		500	{object}	httperr.APIErrorResponse
+//	@Router			/mappings/anilist-tvdb/{anilistId} [delete]
+func (h *Handler) DeleteMapping(c *gin.Context) {
+	anilistID, ok := httpx.AbortIfMissingContext[int64](c, ctxAnilistIDKey)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeleteMapping(c.Request.Context(), anilistID)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "mapping not found", "failed to delete mapping") {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,31 @@
+package anilistmapping
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
+
+func (h *Handler) BindAnilistID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		anilistID, err := parseAnilistID(c.Param("anilistId"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		c.Set(ctxAnilistIDKey, anilistID)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindUpsertMapping() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req upsertMappingRequest
+		if httpx.AbortIfErr(c, c.ShouldBindJSON(&req)) {
+			return
+		}
+		if httpx.AbortIfErr(c, validateUpsertMappingRequest(req)) {
+			return
+		}
+		c.Set(ctxUpsertRequestKey, req)
+		c.Next()
+	}
+}
@@ -0,0 +1,10 @@
+package anilistmapping
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine, h *Handler) {
+	r.GET("/mappings/anilist-tvdb", h.ListMappings)
+	r.GET("/mappings/anilist-tvdb/:anilistId", h.BindAnilistID(), h.GetMapping)
+	r.PUT("/mappings/anilist-tvdb/:anilistId", h.BindAnilistID(), h.BindUpsertMapping(), h.UpsertMapping)
+	r.DELETE("/mappings/anilist-tvdb/:anilistId", h.BindAnilistID(), h.DeleteMapping)
+}
@@ -0,0 +1,66 @@
+package anilistmapping
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/providers/anilisttvdb"
+)
+
+func NewHandler(q *sqlc.Queries) *Handler {
+	return &Handler{
+		svc: NewService(q),
+	}
+}
+
+func NewService(q *sqlc.Queries) *Service {
+	return &Service{q: q}
+}
+
+func (h *Handler) Service() *Service {
+	return h.svc
+}
+
+func (s *Service) ListMappings(ctx context.Context) ([]sqlc.AnilistTvdbMapping, error) {
+	return s.q.ListAnilistTvdbMappings(ctx)
+}
+
+func (s *Service) GetMapping(ctx context.Context, anilistID int64) (sqlc.AnilistTvdbMapping, error) {
+	return s.q.GetAnilistTvdbMapping(ctx, anilistID)
+}
+
+func (s *Service) UpsertMapping(ctx context.Context, anilistID int64, req upsertMappingRequest) (sqlc.AnilistTvdbMapping, error) {
+	seasonNumber := int64(defaultSeasonNumber)
+	if req.SeasonNumber != nil {
+		seasonNumber = *req.SeasonNumber
+	}
+	return s.q.UpsertAnilistTvdbMapping(ctx, sqlc.UpsertAnilistTvdbMappingParams{
+		AnilistID:     anilistID,
+		TvdbID:        req.TvdbID,
+		SeasonNumber:  seasonNumber,
+		EpisodeOffset: req.EpisodeOffset,
+	})
+}
+
+func (s *Service) DeleteMapping(ctx context.Context, anilistID int64) error {
+	_, err := s.q.DeleteAnilistTvdbMapping(ctx, anilistID)
+	return err
+}
+
+// LookupTVDB implements anilisttvdb.MappingStore.
+func (s *Service) LookupTVDB(ctx context.Context, anilistID int64) (anilisttvdb.Mapping, bool, error) {
+	item, err := s.q.GetAnilistTvdbMapping(ctx, anilistID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return anilisttvdb.Mapping{}, false, nil
+	}
+	if err != nil {
+		return anilisttvdb.Mapping{}, false, err
+	}
+	return anilisttvdb.Mapping{
+		TVDBID:        item.TvdbID,
+		SeasonNumber:  item.SeasonNumber,
+		EpisodeOffset: item.EpisodeOffset,
+	}, true, nil
+}
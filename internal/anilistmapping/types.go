@@ -0,0 +1,40 @@
+package anilistmapping
+
+import (
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+)
+
+const (
+	ctxAnilistIDKey     = "anilistmapping.anilist.id"
+	ctxUpsertRequestKey = "anilistmapping.upsert.request"
+)
+
+const (
+	anilistIDPrefix     = "anilist:"
+	defaultSeasonNumber = 1
+)
+
+type Handler struct {
+	svc *Service
+}
+
+type Service struct {
+	q *sqlc.Queries
+}
+
+type upsertMappingRequest struct {
+	TvdbID        int64  `json:"tvdbId"`
+	SeasonNumber  *int64 `json:"seasonNumber,omitempty"`
+	EpisodeOffset int64  `json:"episodeOffset"`
+}
+
+type mappingResponse struct {
+	AnilistID     int64     `json:"anilistId"`
+	TvdbID        int64     `json:"tvdbId"`
+	SeasonNumber  int64     `json:"seasonNumber"`
+	EpisodeOffset int64     `json:"episodeOffset"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
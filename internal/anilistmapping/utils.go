@@ -0,0 +1,54 @@
+package anilistmapping
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
+
+// parseAnilistID accepts a bare AniList media id or the prefixed "anilist:123" form.
+func parseAnilistID(raw string) (int64, error) {
+	value := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(raw)), anilistIDPrefix)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("anilistId is invalid")
+	}
+	return id, nil
+}
+
+func validateUpsertMappingRequest(req upsertMappingRequest) error {
+	if err := httpx.ValidateVar(req.TvdbID, "gt=0", "tvdbId is invalid"); err != nil {
+		return err
+	}
+	if req.SeasonNumber != nil {
+		if err := httpx.ValidateVar(*req.SeasonNumber, "gte=0", "seasonNumber is invalid"); err != nil {
+			return err
+		}
+	}
+	if err := httpx.ValidateVar(req.EpisodeOffset, "gte=0", "episodeOffset is invalid"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func toMappingResponse(item sqlc.AnilistTvdbMapping) mappingResponse {
+	return mappingResponse{
+		AnilistID:     item.AnilistID,
+		TvdbID:        item.TvdbID,
+		SeasonNumber:  item.SeasonNumber,
+		EpisodeOffset: item.EpisodeOffset,
+		CreatedAt:     item.CreatedAt,
+		UpdatedAt:     item.UpdatedAt,
+	}
+}
+
+func toMappingResponses(items []sqlc.AnilistTvdbMapping) []mappingResponse {
+	out := make([]mappingResponse, 0, len(items))
+	for _, item := range items {
+		out = append(out, toMappingResponse(item))
+	}
+	return out
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func FromEnv() Config {
@@ -9,6 +12,28 @@ func FromEnv() Config {
 		Port:               getEnv("PORT", "8080"),
 		DatabaseURL:        mustGetEnv("DATABASE_URL"),
 		TokenEncryptionKey: firstNonEmpty(getEnv("TOKEN_ENCRYPTION_KEY", ""), getEnv("PAT_ENCRYPTION_KEY", ""), "dev-token-encryption-key"),
+		WorkerEnabled:      getEnvBool("WORKER_ENABLED", true),
+		WorkerConcurrency:  getEnvInt("WORKER_CONCURRENCY", 1),
+		WorkerPollInterval: getEnvDuration("WORKER_POLL_INTERVAL", 5*time.Second),
+		WorkerMaxRetries:   getEnvInt("WORKER_MAX_RETRIES", 5),
+		BulkAddEnabled:     getEnvBool("BULK_ADD_WORKER_ENABLED", true),
+		RefreshEnabled:     getEnvBool("REFRESH_ENABLED", true),
+		RefreshInterval:    getEnvDuration("REFRESH_INTERVAL", 6*time.Hour),
+		SearchMinScore:     getEnvFloat("METADATA_SEARCH_MIN_SCORE", 0.5),
+		SearchTimeout:      getEnvDuration("METADATA_SEARCH_TIMEOUT", 5*time.Second),
+		Cache: CacheConfig{
+			Enabled:     getEnvBool("METADATA_CACHE_ENABLED", true),
+			Postgres:    getEnvBool("METADATA_CACHE_POSTGRES", false),
+			SearchTTL:   getEnvDuration("METADATA_CACHE_SEARCH_TTL", 10*time.Minute),
+			DiscoverTTL: getEnvDuration("METADATA_CACHE_DISCOVER_TTL", 10*time.Minute),
+			ShowTTL:     getEnvDuration("METADATA_CACHE_SHOW_TTL", 6*time.Hour),
+			EpisodesTTL: getEnvDuration("METADATA_CACHE_EPISODES_TTL", 6*time.Hour),
+			MaxEntries:  getEnvInt("METADATA_CACHE_MAX_ENTRIES", 2000),
+		},
+		Hooks: HooksConfig{
+			DeliveryEnabled:     getEnvBool("HOOK_DELIVERY_ENABLED", true),
+			DeliveryMaxAttempts: getEnvInt("HOOK_DELIVERY_MAX_ATTEMPTS", 10),
+		},
 	}
 }
 
@@ -20,6 +45,54 @@ func getEnv(key, def string) string {
 	return v
 }
 
+func getEnvBool(key string, def bool) bool {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func getEnvInt(key string, def int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func getEnvFloat(key string, def float64) float64 {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, value := range values {
 		if value != "" {
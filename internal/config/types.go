@@ -1,7 +1,35 @@
 package config
 
+import "time"
+
 type Config struct {
 	Port               string
 	DatabaseURL        string
 	TokenEncryptionKey string
+	WorkerEnabled      bool
+	WorkerConcurrency  int
+	WorkerPollInterval time.Duration
+	WorkerMaxRetries   int
+	BulkAddEnabled     bool
+	RefreshEnabled     bool
+	RefreshInterval    time.Duration
+	SearchMinScore     float64
+	SearchTimeout      time.Duration
+	Cache              CacheConfig
+	Hooks              HooksConfig
+}
+
+type CacheConfig struct {
+	Enabled     bool
+	Postgres    bool
+	SearchTTL   time.Duration
+	DiscoverTTL time.Duration
+	ShowTTL     time.Duration
+	EpisodesTTL time.Duration
+	MaxEntries  int
+}
+
+type HooksConfig struct {
+	DeliveryEnabled     bool
+	DeliveryMaxAttempts int
 }
@@ -7,8 +7,37 @@ package sqlc
 
 import (
 	"context"
+	"time"
 )
 
+const countShowEpisodes = `-- name: CountShowEpisodes :one
+SELECT COUNT(*)
+FROM episodes
+WHERE show_id = $1::uuid
+  AND ($2::bigint IS NULL OR season_number = $2::bigint)
+  AND ($3::text IS NULL OR air_date > $3::text)
+  AND ($4::text IS NULL OR air_date < $4::text)
+`
+
+type CountShowEpisodesParams struct {
+	ShowID       string
+	SeasonNumber *int64
+	AiredAfter   *string
+	AiredBefore  *string
+}
+
+func (q *Queries) CountShowEpisodes(ctx context.Context, arg CountShowEpisodesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countShowEpisodes,
+		arg.ShowID,
+		arg.SeasonNumber,
+		arg.AiredAfter,
+		arg.AiredBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createEpisode = `-- name: CreateEpisode :one
 INSERT INTO episodes (
   show_id,
@@ -117,6 +146,82 @@ func (q *Queries) GetEpisodeByID(ctx context.Context, internalEpisodeID string)
 	return i, err
 }
 
+const insertEpisodesIfNotExist = `-- name: InsertEpisodesIfNotExist :many
+INSERT INTO episodes (
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids
+)
+SELECT
+  $1::uuid,
+  e.season_number,
+  e.episode_number,
+  e.title,
+  e.air_date,
+  e.runtime_minutes,
+  COALESCE(e.external_ids, '{}'::jsonb)
+FROM jsonb_to_recordset($2::jsonb) AS e(
+  season_number BIGINT,
+  episode_number BIGINT,
+  title TEXT,
+  air_date TEXT,
+  runtime_minutes BIGINT,
+  external_ids JSONB
+)
+ON CONFLICT (show_id, season_number, episode_number) DO NOTHING
+RETURNING
+  internal_episode_id,
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids,
+  created_at,
+  updated_at
+`
+
+type InsertEpisodesIfNotExistParams struct {
+	ShowID   string
+	Episodes []byte
+}
+
+func (q *Queries) InsertEpisodesIfNotExist(ctx context.Context, arg InsertEpisodesIfNotExistParams) ([]Episode, error) {
+	rows, err := q.db.Query(ctx, insertEpisodesIfNotExist, arg.ShowID, arg.Episodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Episode{}
+	for rows.Next() {
+		var i Episode
+		if err := rows.Scan(
+			&i.InternalEpisodeID,
+			&i.ShowID,
+			&i.SeasonNumber,
+			&i.EpisodeNumber,
+			&i.Title,
+			&i.AirDate,
+			&i.RuntimeMinutes,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEpisodes = `-- name: ListEpisodes :many
 SELECT
   internal_episode_id,
@@ -212,6 +317,232 @@ func (q *Queries) ListEpisodesByShowID(ctx context.Context, showID string) ([]Ep
 	return items, nil
 }
 
+const listSeasonSummaries = `-- name: ListSeasonSummaries :many
+SELECT
+  season_number,
+  COUNT(*) AS episode_count,
+  MIN(air_date) AS first_air_date,
+  MAX(air_date) AS last_air_date,
+  COALESCE(SUM(runtime_minutes), 0)::bigint AS total_runtime_minutes
+FROM episodes
+WHERE show_id = $1::uuid
+GROUP BY season_number
+ORDER BY season_number ASC
+`
+
+type ListSeasonSummariesRow struct {
+	SeasonNumber        int64
+	EpisodeCount        int64
+	FirstAirDate        *string
+	LastAirDate         *string
+	TotalRuntimeMinutes int64
+}
+
+func (q *Queries) ListSeasonSummaries(ctx context.Context, showID string) ([]ListSeasonSummariesRow, error) {
+	rows, err := q.db.Query(ctx, listSeasonSummaries, showID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSeasonSummariesRow{}
+	for rows.Next() {
+		var i ListSeasonSummariesRow
+		if err := rows.Scan(
+			&i.SeasonNumber,
+			&i.EpisodeCount,
+			&i.FirstAirDate,
+			&i.LastAirDate,
+			&i.TotalRuntimeMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShowEpisodesPage = `-- name: ListShowEpisodesPage :many
+SELECT
+  internal_episode_id,
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids,
+  created_at,
+  updated_at
+FROM episodes
+WHERE show_id = $1::uuid
+  AND ($2::bigint IS NULL OR season_number = $2::bigint)
+  AND ($3::text IS NULL OR air_date > $3::text)
+  AND ($4::text IS NULL OR air_date < $4::text)
+  AND (
+    $5::bigint IS NULL
+    OR (season_number, episode_number) > ($5::bigint, $6::bigint)
+  )
+ORDER BY season_number ASC, episode_number ASC
+LIMIT $7
+`
+
+type ListShowEpisodesPageParams struct {
+	ShowID        string
+	SeasonNumber  *int64
+	AiredAfter    *string
+	AiredBefore   *string
+	CursorSeason  *int64
+	CursorEpisode *int64
+	RowLimit      int32
+}
+
+func (q *Queries) ListShowEpisodesPage(ctx context.Context, arg ListShowEpisodesPageParams) ([]Episode, error) {
+	rows, err := q.db.Query(ctx, listShowEpisodesPage,
+		arg.ShowID,
+		arg.SeasonNumber,
+		arg.AiredAfter,
+		arg.AiredBefore,
+		arg.CursorSeason,
+		arg.CursorEpisode,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Episode{}
+	for rows.Next() {
+		var i Episode
+		if err := rows.Scan(
+			&i.InternalEpisodeID,
+			&i.ShowID,
+			&i.SeasonNumber,
+			&i.EpisodeNumber,
+			&i.Title,
+			&i.AirDate,
+			&i.RuntimeMinutes,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const syncProviderEpisodes = `-- name: SyncProviderEpisodes :many
+INSERT INTO episodes (
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids
+)
+SELECT
+  $1::uuid,
+  e.season_number,
+  e.episode_number,
+  e.title,
+  e.air_date,
+  e.runtime_minutes,
+  COALESCE(e.external_ids, '{}'::jsonb)
+FROM jsonb_to_recordset($2::jsonb) AS e(
+  season_number BIGINT,
+  episode_number BIGINT,
+  title TEXT,
+  air_date TEXT,
+  runtime_minutes BIGINT,
+  external_ids JSONB
+)
+ON CONFLICT (show_id, season_number, episode_number) DO UPDATE
+SET
+  title = EXCLUDED.title,
+  air_date = EXCLUDED.air_date,
+  runtime_minutes = COALESCE(EXCLUDED.runtime_minutes, episodes.runtime_minutes),
+  external_ids = episodes.external_ids || EXCLUDED.external_ids,
+  updated_at = NOW()
+WHERE (episodes.title, episodes.air_date, episodes.runtime_minutes, episodes.external_ids)
+  IS DISTINCT FROM (
+    EXCLUDED.title,
+    EXCLUDED.air_date,
+    COALESCE(EXCLUDED.runtime_minutes, episodes.runtime_minutes),
+    episodes.external_ids || EXCLUDED.external_ids
+  )
+RETURNING
+  internal_episode_id,
+  show_id,
+  season_number,
+  episode_number,
+  title,
+  air_date,
+  runtime_minutes,
+  external_ids,
+  created_at,
+  updated_at,
+  (xmax = 0) AS inserted
+`
+
+type SyncProviderEpisodesParams struct {
+	ShowID   string
+	Episodes []byte
+}
+
+type SyncProviderEpisodesRow struct {
+	InternalEpisodeID string
+	ShowID            string
+	SeasonNumber      int64
+	EpisodeNumber     int64
+	Title             string
+	AirDate           *string
+	RuntimeMinutes    *int64
+	ExternalIds       []byte
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Inserted          bool
+}
+
+func (q *Queries) SyncProviderEpisodes(ctx context.Context, arg SyncProviderEpisodesParams) ([]SyncProviderEpisodesRow, error) {
+	rows, err := q.db.Query(ctx, syncProviderEpisodes, arg.ShowID, arg.Episodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SyncProviderEpisodesRow{}
+	for rows.Next() {
+		var i SyncProviderEpisodesRow
+		if err := rows.Scan(
+			&i.InternalEpisodeID,
+			&i.ShowID,
+			&i.SeasonNumber,
+			&i.EpisodeNumber,
+			&i.Title,
+			&i.AirDate,
+			&i.RuntimeMinutes,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Inserted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateEpisode = `-- name: UpdateEpisode :one
 UPDATE episodes
 SET
@@ -274,3 +605,152 @@ func (q *Queries) UpdateEpisode(ctx context.Context, arg UpdateEpisodeParams) (E
 	)
 	return i, err
 }
+
+const upsertShowEpisodes = `-- name: UpsertShowEpisodes :many
+WITH input AS (
+  SELECT
+    e.season_number,
+    e.episode_number,
+    e.title,
+    e.air_date,
+    e.runtime_minutes,
+    COALESCE(e.external_ids, '{}'::jsonb) AS external_ids
+  FROM jsonb_to_recordset($1::jsonb) AS e(
+    season_number BIGINT,
+    episode_number BIGINT,
+    title TEXT,
+    air_date TEXT,
+    runtime_minutes BIGINT,
+    external_ids JSONB
+  )
+),
+upserted AS (
+  INSERT INTO episodes (
+    show_id,
+    season_number,
+    episode_number,
+    title,
+    air_date,
+    runtime_minutes,
+    external_ids
+  )
+  SELECT
+    $2::uuid,
+    season_number,
+    episode_number,
+    title,
+    air_date,
+    runtime_minutes,
+    external_ids
+  FROM input
+  ON CONFLICT (show_id, season_number, episode_number) DO UPDATE
+  SET
+    title = EXCLUDED.title,
+    air_date = EXCLUDED.air_date,
+    runtime_minutes = EXCLUDED.runtime_minutes,
+    external_ids = EXCLUDED.external_ids,
+    updated_at = NOW()
+  WHERE (episodes.title, episodes.air_date, episodes.runtime_minutes, episodes.external_ids)
+    IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.air_date, EXCLUDED.runtime_minutes, EXCLUDED.external_ids)
+  RETURNING
+    internal_episode_id,
+    show_id,
+    season_number,
+    episode_number,
+    title,
+    air_date,
+    runtime_minutes,
+    external_ids,
+    created_at,
+    updated_at,
+    (xmax = 0) AS inserted
+)
+SELECT
+  u.internal_episode_id,
+  u.show_id,
+  u.season_number,
+  u.episode_number,
+  u.title,
+  u.air_date,
+  u.runtime_minutes,
+  u.external_ids,
+  u.created_at,
+  u.updated_at,
+  (CASE WHEN u.inserted THEN 'created' ELSE 'updated' END)::text AS status
+FROM upserted u
+UNION ALL
+SELECT
+  e.internal_episode_id,
+  e.show_id,
+  e.season_number,
+  e.episode_number,
+  e.title,
+  e.air_date,
+  e.runtime_minutes,
+  e.external_ids,
+  e.created_at,
+  e.updated_at,
+  'unchanged'::text AS status
+FROM episodes e
+JOIN input i
+  ON i.season_number = e.season_number
+  AND i.episode_number = e.episode_number
+WHERE e.show_id = $2::uuid
+  AND NOT EXISTS (
+    SELECT 1
+    FROM upserted u
+    WHERE u.season_number = e.season_number
+      AND u.episode_number = e.episode_number
+  )
+`
+
+type UpsertShowEpisodesParams struct {
+	Episodes []byte
+	ShowID   string
+}
+
+type UpsertShowEpisodesRow struct {
+	InternalEpisodeID string
+	ShowID            string
+	SeasonNumber      int64
+	EpisodeNumber     int64
+	Title             string
+	AirDate           *string
+	RuntimeMinutes    *int64
+	ExternalIds       []byte
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Status            string
+}
+
+func (q *Queries) UpsertShowEpisodes(ctx context.Context, arg UpsertShowEpisodesParams) ([]UpsertShowEpisodesRow, error) {
+	rows, err := q.db.Query(ctx, upsertShowEpisodes, arg.Episodes, arg.ShowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UpsertShowEpisodesRow{}
+	for rows.Next() {
+		var i UpsertShowEpisodesRow
+		if err := rows.Scan(
+			&i.InternalEpisodeID,
+			&i.ShowID,
+			&i.SeasonNumber,
+			&i.EpisodeNumber,
+			&i.Title,
+			&i.AirDate,
+			&i.RuntimeMinutes,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -92,3 +92,89 @@ type Episode struct {
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 }
+
+type JobShow struct {
+	InternalJobShowID string
+	Status            string
+	ErrorMessage      *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	RetryCount        int32
+	ShowID            string
+	RunAfter          time.Time
+	LockedAt          *time.Time
+	LockedBy          *string
+}
+
+type AnilistTvdbMapping struct {
+	AnilistID     int64
+	TvdbID        int64
+	SeasonNumber  int64
+	EpisodeOffset int64
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type ShowExternalID struct {
+	Provider   string
+	ExternalID string
+	ShowID     string
+	CreatedAt  time.Time
+}
+
+type MetadataCache struct {
+	CacheKey   string
+	Provider   string
+	Method     string
+	ExternalID *string
+	Payload    []byte
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+type JobBulkAdd struct {
+	InternalJobBulkAddID string
+	Status               string
+	Items                []byte
+	OnConflict           string
+	ImportEpisodes       bool
+	Result               []byte
+	ErrorMessage         *string
+	LockedAt             *time.Time
+	LockedBy             *string
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+type HookDelivery struct {
+	InternalHookDeliveryID string
+	SubscriptionID         *string
+	EventName              string
+	Payload                []byte
+	Status                 string
+	Attempts               int32
+	NextAttemptAt          time.Time
+	LastError              *string
+	LastStatusCode         *int32
+	LockedAt               *time.Time
+	LockedBy               *string
+	DeliveredAt            *time.Time
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
+
+type HookSubscription struct {
+	InternalHookSubscriptionID     string
+	EventName                      string
+	TargetUrl                      string
+	Enabled                        bool
+	Headers                        []byte
+	TimeoutMs                      int32
+	Filter                         []byte
+	FailureMode                    string
+	SigningSecret                  string
+	PreviousSigningSecret          string
+	PreviousSigningSecretExpiresAt *time.Time
+	CreatedAt                      time.Time
+	UpdatedAt                      time.Time
+}
@@ -7,6 +7,7 @@ package sqlc
 
 import (
 	"context"
+	"time"
 )
 
 const createShow = `-- name: CreateShow :one
@@ -218,6 +219,451 @@ func (q *Queries) ListShows(ctx context.Context) ([]Show, error) {
 	return items, nil
 }
 
+const listShowsByStartDate = `-- name: ListShowsByStartDate :many
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at
+FROM shows
+WHERE start_date = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListShowsByStartDate(ctx context.Context, startDate *string) ([]Show, error) {
+	rows, err := q.db.Query(ctx, listShowsByStartDate, startDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Show
+	for rows.Next() {
+		var i Show
+		if err := rows.Scan(
+			&i.InternalShowID,
+			&i.TitlePreferred,
+			&i.TitleOriginal,
+			&i.AltTitles,
+			&i.Type,
+			&i.Status,
+			&i.Synopsis,
+			&i.StartDate,
+			&i.EndDate,
+			&i.PosterUrl,
+			&i.BannerUrl,
+			&i.SeasonCount,
+			&i.EpisodeCount,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShowsByStatus = `-- name: ListShowsByStatus :many
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at
+FROM shows
+WHERE status = $1
+ORDER BY updated_at ASC
+`
+
+func (q *Queries) ListShowsByStatus(ctx context.Context, status string) ([]Show, error) {
+	rows, err := q.db.Query(ctx, listShowsByStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Show
+	for rows.Next() {
+		var i Show
+		if err := rows.Scan(
+			&i.InternalShowID,
+			&i.TitlePreferred,
+			&i.TitleOriginal,
+			&i.AltTitles,
+			&i.Type,
+			&i.Status,
+			&i.Synopsis,
+			&i.StartDate,
+			&i.EndDate,
+			&i.PosterUrl,
+			&i.BannerUrl,
+			&i.SeasonCount,
+			&i.EpisodeCount,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShowsSharingExternalID = `-- name: ListShowsSharingExternalID :many
+WITH shared AS (
+  SELECT
+    m.provider || ':' || m.external_id AS shared_external_id,
+    m.show_id AS owner_id,
+    s.internal_show_id AS other_id
+  FROM show_external_ids m
+  JOIN shows s ON s.external_ids->>'externalId' = m.provider || ':' || m.external_id
+  WHERE s.internal_show_id <> m.show_id
+),
+members AS (
+  SELECT shared_external_id, owner_id AS show_id FROM shared
+  UNION
+  SELECT shared_external_id, other_id FROM shared
+)
+SELECT
+  s.internal_show_id,
+  s.title_preferred,
+  s.title_original,
+  s.alt_titles,
+  s.type,
+  s.status,
+  s.synopsis,
+  s.start_date,
+  s.end_date,
+  s.poster_url,
+  s.banner_url,
+  s.season_count,
+  s.episode_count,
+  s.external_ids,
+  s.created_at,
+  s.updated_at,
+  members.shared_external_id::text AS shared_external_id
+FROM members
+JOIN shows s ON s.internal_show_id = members.show_id
+ORDER BY members.shared_external_id ASC, s.created_at ASC
+`
+
+type ListShowsSharingExternalIDRow struct {
+	InternalShowID   string
+	TitlePreferred   string
+	TitleOriginal    *string
+	AltTitles        []string
+	Type             string
+	Status           string
+	Synopsis         *string
+	StartDate        *string
+	EndDate          *string
+	PosterUrl        *string
+	BannerUrl        *string
+	SeasonCount      *int64
+	EpisodeCount     *int64
+	ExternalIds      []byte
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	SharedExternalID string
+}
+
+func (q *Queries) ListShowsSharingExternalID(ctx context.Context) ([]ListShowsSharingExternalIDRow, error) {
+	rows, err := q.db.Query(ctx, listShowsSharingExternalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListShowsSharingExternalIDRow
+	for rows.Next() {
+		var i ListShowsSharingExternalIDRow
+		if err := rows.Scan(
+			&i.InternalShowID,
+			&i.TitlePreferred,
+			&i.TitleOriginal,
+			&i.AltTitles,
+			&i.Type,
+			&i.Status,
+			&i.Synopsis,
+			&i.StartDate,
+			&i.EndDate,
+			&i.PosterUrl,
+			&i.BannerUrl,
+			&i.SeasonCount,
+			&i.EpisodeCount,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SharedExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShowsSharingStartDate = `-- name: ListShowsSharingStartDate :many
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at
+FROM shows
+WHERE start_date IN (
+  SELECT start_date
+  FROM shows
+  WHERE start_date IS NOT NULL
+  GROUP BY start_date
+  HAVING COUNT(*) > 1
+)
+ORDER BY start_date ASC, created_at ASC
+`
+
+func (q *Queries) ListShowsSharingStartDate(ctx context.Context) ([]Show, error) {
+	rows, err := q.db.Query(ctx, listShowsSharingStartDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Show
+	for rows.Next() {
+		var i Show
+		if err := rows.Scan(
+			&i.InternalShowID,
+			&i.TitlePreferred,
+			&i.TitleOriginal,
+			&i.AltTitles,
+			&i.Type,
+			&i.Status,
+			&i.Synopsis,
+			&i.StartDate,
+			&i.EndDate,
+			&i.PosterUrl,
+			&i.BannerUrl,
+			&i.SeasonCount,
+			&i.EpisodeCount,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchShows = `-- name: SearchShows :many
+WITH search AS (
+  SELECT
+    websearch_to_tsquery('simple', $1::text) AS tsq,
+    $1::text AS raw
+),
+scored AS (
+  SELECT
+    s.internal_show_id,
+    s.title_preferred,
+    s.title_original,
+    s.alt_titles,
+    s.type,
+    s.status,
+    s.synopsis,
+    s.start_date,
+    s.end_date,
+    s.poster_url,
+    s.banner_url,
+    s.season_count,
+    s.episode_count,
+    s.external_ids,
+    s.created_at,
+    s.updated_at,
+    (
+      similarity(s.title_preferred, search.raw)
+      + CASE WHEN to_tsvector('simple', s.title_preferred) @@ search.tsq THEN 1 ELSE 0 END
+    )::float8 AS title_preferred_score,
+    (
+      similarity(COALESCE(s.title_original, ''), search.raw)
+      + CASE WHEN to_tsvector('simple', COALESCE(s.title_original, '')) @@ search.tsq THEN 1 ELSE 0 END
+    )::float8 AS title_original_score,
+    (
+      COALESCE((SELECT MAX(similarity(alt, search.raw)) FROM unnest(s.alt_titles) AS alt), 0)
+      + CASE WHEN to_tsvector('simple', show_alt_titles_text(s.alt_titles)) @@ search.tsq THEN 1 ELSE 0 END
+    )::float8 AS alt_titles_score,
+    (CASE WHEN to_tsvector('simple', COALESCE(s.synopsis, '')) @@ search.tsq THEN 0.5 ELSE 0 END)::float8 AS synopsis_score,
+    ts_rank(show_search_vector(s.title_preferred, s.title_original, s.alt_titles, s.synopsis), search.tsq) AS text_rank
+  FROM shows s
+  CROSS JOIN search
+  WHERE show_search_vector(s.title_preferred, s.title_original, s.alt_titles, s.synopsis) @@ search.tsq
+    OR s.title_preferred % search.raw
+    OR s.title_original % search.raw
+    OR search.raw <% show_alt_titles_text(s.alt_titles)
+)
+SELECT
+  internal_show_id,
+  title_preferred,
+  title_original,
+  alt_titles,
+  type,
+  status,
+  synopsis,
+  start_date,
+  end_date,
+  poster_url,
+  banner_url,
+  season_count,
+  episode_count,
+  external_ids,
+  created_at,
+  updated_at,
+  GREATEST(title_preferred_score, title_original_score, alt_titles_score, synopsis_score)::float8 AS score,
+  (
+    CASE GREATEST(title_preferred_score, title_original_score, alt_titles_score, synopsis_score)
+      WHEN title_preferred_score THEN 'titlePreferred'
+      WHEN title_original_score THEN 'titleOriginal'
+      WHEN alt_titles_score THEN 'altTitles'
+      ELSE 'synopsis'
+    END
+  )::text AS matched_field
+FROM scored
+ORDER BY score DESC, text_rank DESC, title_preferred ASC, internal_show_id ASC
+LIMIT $2
+`
+
+type SearchShowsParams struct {
+	Query    string
+	RowLimit int32
+}
+
+type SearchShowsRow struct {
+	InternalShowID string
+	TitlePreferred string
+	TitleOriginal  *string
+	AltTitles      []string
+	Type           string
+	Status         string
+	Synopsis       *string
+	StartDate      *string
+	EndDate        *string
+	PosterUrl      *string
+	BannerUrl      *string
+	SeasonCount    *int64
+	EpisodeCount   *int64
+	ExternalIds    []byte
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Score          float64
+	MatchedField   string
+}
+
+func (q *Queries) SearchShows(ctx context.Context, arg SearchShowsParams) ([]SearchShowsRow, error) {
+	rows, err := q.db.Query(ctx, searchShows, arg.Query, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchShowsRow
+	for rows.Next() {
+		var i SearchShowsRow
+		if err := rows.Scan(
+			&i.InternalShowID,
+			&i.TitlePreferred,
+			&i.TitleOriginal,
+			&i.AltTitles,
+			&i.Type,
+			&i.Status,
+			&i.Synopsis,
+			&i.StartDate,
+			&i.EndDate,
+			&i.PosterUrl,
+			&i.BannerUrl,
+			&i.SeasonCount,
+			&i.EpisodeCount,
+			&i.ExternalIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Score,
+			&i.MatchedField,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tryLockShowsRefresh = `-- name: TryLockShowsRefresh :one
+SELECT pg_try_advisory_lock($1::bigint)
+`
+
+func (q *Queries) TryLockShowsRefresh(ctx context.Context, lockKey int64) (bool, error) {
+	row := q.db.QueryRow(ctx, tryLockShowsRefresh, lockKey)
+	var pg_try_advisory_lock bool
+	err := row.Scan(&pg_try_advisory_lock)
+	return pg_try_advisory_lock, err
+}
+
+const unlockShowsRefresh = `-- name: UnlockShowsRefresh :one
+SELECT pg_advisory_unlock($1::bigint)
+`
+
+func (q *Queries) UnlockShowsRefresh(ctx context.Context, lockKey int64) (bool, error) {
+	row := q.db.QueryRow(ctx, unlockShowsRefresh, lockKey)
+	var pg_advisory_unlock bool
+	err := row.Scan(&pg_advisory_unlock)
+	return pg_advisory_unlock, err
+}
+
 const updateShow = `-- name: UpdateShow :one
 UPDATE shows
 SET
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/hooks"
 	"github.com/keithics/devops-dashboard/api/internal/httperr"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
 )
@@ -18,6 +19,8 @@ import (
 //	@Param			payload	body		createEpisodeRequest	true	"Episode payload"
 //	@Success		201		{object}	episodeResponse
 //	@Failure		400		{object}	httperr.APIErrorResponse
+//	@Failure		422		{object}	httperr.APIErrorResponse
+//	@Failure		502		{object}	httperr.APIErrorResponse
 //	@Failure		500		{object}	httperr.APIErrorResponse
 //	@Router			/episodes [post]
 func (h *Handler) CreateEpisode(c *gin.Context) {
@@ -28,6 +31,9 @@ func (h *Handler) CreateEpisode(c *gin.Context) {
 
 	created, err := h.svc.CreateEpisode(c.Request.Context(), req)
 	if err != nil {
+		if hooks.AbortIfHookErr(c, err) {
+			return
+		}
 		if httpx.AbortIfDBErr(c, err, "failed to create episode") {
 			return
 		}
@@ -120,6 +126,8 @@ func (h *Handler) GetEpisode(c *gin.Context) {
 //	@Success		200				{object}	episodeResponse
 //	@Failure		400				{object}	httperr.APIErrorResponse
 //	@Failure		404				{object}	httperr.APIErrorResponse
+//	@Failure		422				{object}	httperr.APIErrorResponse
+//	@Failure		502				{object}	httperr.APIErrorResponse
 //	@Failure		500				{object}	httperr.APIErrorResponse
 //	@Router			/episodes/{internalEpisodeId} [put]
 func (h *Handler) UpdateEpisode(c *gin.Context) {
@@ -134,6 +142,9 @@ func (h *Handler) UpdateEpisode(c *gin.Context) {
 
 	updated, err := h.svc.UpdateEpisode(c.Request.Context(), episodeID, req)
 	if err != nil {
+		if hooks.AbortIfHookErr(c, err) {
+			return
+		}
 		if httpx.AbortIfDBErr(c, err, "failed to update episode") {
 			return
 		}
@@ -160,6 +171,8 @@ func (h *Handler) UpdateEpisode(c *gin.Context) {
 //	@Success		204
 //	@Failure		400	{object}	httperr.APIErrorResponse
 //	@Failure		404	{object}	httperr.APIErrorResponse
+//	@Failure		422	{object}	httperr.APIErrorResponse
+//	@Failure		502	{object}	httperr.APIErrorResponse
 //	@Failure		500	{object}	httperr.APIErrorResponse
 //	@Router			/episodes/{internalEpisodeId} [delete]
 func (h *Handler) DeleteEpisode(c *gin.Context) {
@@ -169,6 +182,9 @@ func (h *Handler) DeleteEpisode(c *gin.Context) {
 	}
 
 	if err := h.svc.DeleteEpisode(c.Request.Context(), episodeID); err != nil {
+		if hooks.AbortIfHookErr(c, err) {
+			return
+		}
 		if httpx.AbortIfDBErr(c, err, "failed to delete episode") {
 			return
 		}
@@ -178,3 +194,121 @@ func (h *Handler) DeleteEpisode(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// ListShowEpisodes godoc
+//
+//	@Summary		List show episodes
+//	@Description	List the episodes of a show in season and episode order, one page at a time. Pass nextCursor back as cursor to get the next page.
+//	@Tags			episodes
+//	@Produce		json
+//	@Param			internalShowId	path		string	true	"Internal show UUID"
+//	@Param			season			query		int		false	"Season number"
+//	@Param			airedAfter		query		string	false	"Only episodes aired after this date (YYYY-MM-DD, exclusive)"
+//	@Param			airedBefore		query		string	false	"Only episodes aired before this date (YYYY-MM-DD, exclusive)"
+//	@Param			limit			query		int		false	"Page size (default 100, max 500)"
+//	@Param			cursor			query		string	false	"nextCursor of the previous page"
+//	@Success		200				{object}	listEpisodesResponse
+//	@Failure		400				{object}	httperr.APIErrorResponse
+//	@Failure		404				{object}	httperr.APIErrorResponse
+//	@Failure		500				{object}	httperr.APIErrorResponse
+//	@Router			/shows/{internalShowId}/episodes [get]
+func (h *Handler) ListShowEpisodes(c *gin.Context) {
+	showID, ok := httpx.AbortIfMissingContext[string](c, ctxShowIDKey)
+	if !ok {
+		return
+	}
+	opts, ok := httpx.AbortIfMissingContext[ListShowEpisodesOpts](c, ctxListOptsKey)
+	if !ok {
+		return
+	}
+
+	page, err := h.svc.ListShowEpisodes(c.Request.Context(), showID, opts)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "show not found", "failed to list show episodes") {
+		return
+	}
+
+	items, err := httpx.MapSliceE(page.Items, toEpisodeResponse)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to format episode response").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, listEpisodesResponse{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	})
+}
+
+// ListSeasons godoc
+//
+//	@Summary		List show seasons
+//	@Description	Summarize each season of a show: episode count, first and last air date and total runtime
+//	@Tags			episodes
+//	@Produce		json
+//	@Param			internalShowId	path		string	true	"Internal show UUID"
+//	@Success		200				{array}		seasonSummaryResponse
+//	@Failure		400				{object}	httperr.APIErrorResponse
+//	@Failure		404				{object}	httperr.APIErrorResponse
+//	@Failure		500				{object}	httperr.APIErrorResponse
+//	@Router			/shows/{internalShowId}/seasons [get]
+func (h *Handler) ListSeasons(c *gin.Context) {
+	showID, ok := httpx.AbortIfMissingContext[string](c, ctxShowIDKey)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListSeasons(c.Request.Context(), showID)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "show not found", "failed to list show seasons") {
+		return
+	}
+
+	response := make([]seasonSummaryResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toSeasonSummaryResponse(item))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// BulkUpsertEpisodes godoc
+//
+//	@Summary		Bulk upsert show episodes
+//	@Description	Create or update many episodes of a show in one statement, keyed on season and episode number. Invalid items are reported per item and skipped; one aggregated hook event covers the batch.
+//	@Tags			episodes
+//	@Accept			json
+//	@Produce		json
+//	@Param			internalShowId	path		string				true	"Internal show UUID"
+//	@Param			payload			body		[]bulkEpisodeItem	true	"Episodes (1 to 2000)"
+//	@Success		200				{object}	bulkUpsertEpisodesResponse
+//	@Failure		400				{object}	httperr.APIErrorResponse
+//	@Failure		404				{object}	httperr.APIErrorResponse
+//	@Failure		422				{object}	httperr.APIErrorResponse
+//	@Failure		502				{object}	httperr.APIErrorResponse
+//	@Failure		500				{object}	httperr.APIErrorResponse
+//	@Router			/shows/{internalShowId}/episodes:bulk [put]
+func (h *Handler) BulkUpsertEpisodes(c *gin.Context) {
+	showID, ok := httpx.AbortIfMissingContext[string](c, ctxShowIDKey)
+	if !ok {
+		return
+	}
+	items, ok := httpx.AbortIfMissingContext[[]bulkEpisodeItem](c, ctxBulkEpisodesRequestKey)
+	if !ok {
+		return
+	}
+
+	result, err := h.svc.BulkUpsertEpisodes(c.Request.Context(), showID, items)
+	if hooks.AbortIfHookErr(c, err) {
+		return
+	}
+	if httpx.AbortDBErrNotFoundMsg(c, err, "show not found", "failed to upsert episodes") {
+		return
+	}
+
+	response, err := toBulkUpsertEpisodesResponse(result)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to format episode response").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
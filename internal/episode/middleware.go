@@ -45,3 +45,46 @@ func (h *Handler) BindEpisodeID() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (h *Handler) BindShowID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		showID := c.Param("internalShowId")
+		if httpx.AbortIfErr(c, validateShowID(showID)) {
+			return
+		}
+		c.Set(ctxShowIDKey, showID)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindListShowEpisodes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseListShowEpisodesOpts(
+			c.Query("season"),
+			c.Query("airedAfter"),
+			c.Query("airedBefore"),
+			c.Query("limit"),
+			c.Query("cursor"),
+		)
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		c.Set(ctxListOptsKey, opts)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindBulkUpsertEpisodes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var items []bulkEpisodeItem
+		if httpx.AbortIfErr(c, c.ShouldBindJSON(&items)) {
+			return
+		}
+		if httpx.AbortIfErr(c, validateBulkEpisodeItems(items)) {
+			return
+		}
+		normalizeBulkEpisodeItems(items)
+		c.Set(ctxBulkEpisodesRequestKey, items)
+		c.Next()
+	}
+}
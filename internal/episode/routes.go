@@ -1,6 +1,9 @@
 package episode
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
 
 func RegisterRoutes(r *gin.Engine, h *Handler) {
 	r.GET("/episodes", h.ListEpisodes)
@@ -8,4 +11,7 @@ func RegisterRoutes(r *gin.Engine, h *Handler) {
 	r.POST("/episodes", h.BindCreateEpisode(), h.CreateEpisode)
 	r.PUT("/episodes/:internalEpisodeId", h.BindEpisodeID(), h.BindUpdateEpisode(), h.UpdateEpisode)
 	r.DELETE("/episodes/:internalEpisodeId", h.BindEpisodeID(), h.DeleteEpisode)
+	r.GET("/shows/:internalShowId/episodes", h.BindShowID(), h.BindListShowEpisodes(), h.ListShowEpisodes)
+	r.GET("/shows/:internalShowId/seasons", h.BindShowID(), h.ListSeasons)
+	r.PUT("/shows/:internalShowId/:action", httpx.RequireParam("action", "episodes:bulk"), h.BindShowID(), h.BindBulkUpsertEpisodes(), h.BulkUpsertEpisodes)
 }
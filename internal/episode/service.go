@@ -2,51 +2,80 @@ package episode
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/db"
 	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/hooks"
 )
 
-func NewHandler(q *sqlc.Queries) *Handler {
-	return NewHandlerWithHooks(q, hooks.NoopDispatcher{})
+func NewHandler(pool *pgxpool.Pool) *Handler {
+	return NewHandlerWithHooks(pool, hooks.NoopDispatcher{})
 }
 
-func NewHandlerWithHooks(q *sqlc.Queries, dispatcher hooks.Dispatcher) *Handler {
+func NewHandlerWithHooks(pool *pgxpool.Pool, dispatcher hooks.Dispatcher) *Handler {
 	if dispatcher == nil {
 		dispatcher = hooks.NoopDispatcher{}
 	}
 	return &Handler{
-		svc: &Service{
-			q:     q,
-			hooks: dispatcher,
-		},
+		svc: NewService(pool, dispatcher),
 	}
 }
 
+func NewService(pool *pgxpool.Pool, dispatcher hooks.Dispatcher) *Service {
+	if dispatcher == nil {
+		dispatcher = hooks.NoopDispatcher{}
+	}
+	return &Service{
+		pool:  pool,
+		q:     sqlc.New(pool),
+		hooks: dispatcher,
+	}
+}
+
+func (h *Handler) Service() *Service {
+	return h.svc
+}
+
 func (s *Service) CreateEpisode(ctx context.Context, req createEpisodeRequest) (sqlc.Episode, error) {
-	if err := s.hooks.DispatchPre(ctx, hooks.EventEpisodeCreatePre, req); err != nil {
+	patched, err := s.hooks.DispatchPre(ctx, hooks.EventEpisodeCreatePre, &req)
+	if err != nil {
 		return sqlc.Episode{}, err
 	}
+	if patched {
+		normalizeCreateEpisodeRequest(&req)
+		if err := validateCreateEpisodeRequest(req); err != nil {
+			return sqlc.Episode{}, hooks.InvalidPatchError(hooks.EventEpisodeCreatePre, err)
+		}
+	}
 
 	externalIDs, err := marshalExternalIDs(req.ExternalIDs)
 	if err != nil {
 		return sqlc.Episode{}, err
 	}
 
-	created, err := s.q.CreateEpisode(ctx, sqlc.CreateEpisodeParams{
-		ShowID:         req.ShowID,
-		SeasonNumber:   req.SeasonNumber,
-		EpisodeNumber:  req.EpisodeNumber,
-		Title:          req.Title,
-		AirDate:        req.AirDate,
-		RuntimeMinutes: req.RuntimeMinutes,
-		ExternalIds:    externalIDs,
+	var created sqlc.Episode
+	err = s.inTx(ctx, func(tx *Service) error {
+		created, err = tx.q.CreateEpisode(ctx, sqlc.CreateEpisodeParams{
+			ShowID:         req.ShowID,
+			SeasonNumber:   req.SeasonNumber,
+			EpisodeNumber:  req.EpisodeNumber,
+			Title:          req.Title,
+			AirDate:        req.AirDate,
+			RuntimeMinutes: req.RuntimeMinutes,
+			ExternalIds:    externalIDs,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.hooks.DispatchPost(ctx, hooks.EventEpisodeCreatePost, created)
 	})
 	if err != nil {
 		return sqlc.Episode{}, err
 	}
-
-	s.hooks.DispatchPost(ctx, hooks.EventEpisodeCreatePost, created)
 	return created, nil
 }
 
@@ -54,55 +83,319 @@ func (s *Service) ListEpisodes(ctx context.Context) ([]sqlc.Episode, error) {
 	return s.q.ListEpisodes(ctx)
 }
 
+// ListShowEpisodes returns one page of a show's episodes in season and episode order,
+// plus the number of episodes matching opts. It returns pgx.ErrNoRows for unknown shows.
+func (s *Service) ListShowEpisodes(ctx context.Context, showID string, opts ListShowEpisodesOpts) (EpisodesPage, error) {
+	if _, err := s.q.GetShowByID(ctx, showID); err != nil {
+		return EpisodesPage{}, err
+	}
+
+	items, err := s.q.ListShowEpisodesPage(ctx, sqlc.ListShowEpisodesPageParams{
+		ShowID:        showID,
+		SeasonNumber:  opts.SeasonNumber,
+		AiredAfter:    opts.AiredAfter,
+		AiredBefore:   opts.AiredBefore,
+		CursorSeason:  opts.CursorSeason,
+		CursorEpisode: opts.CursorEpisode,
+		RowLimit:      int32(opts.Limit + 1),
+	})
+	if err != nil {
+		return EpisodesPage{}, err
+	}
+
+	total, err := s.q.CountShowEpisodes(ctx, sqlc.CountShowEpisodesParams{
+		ShowID:       showID,
+		SeasonNumber: opts.SeasonNumber,
+		AiredAfter:   opts.AiredAfter,
+		AiredBefore:  opts.AiredBefore,
+	})
+	if err != nil {
+		return EpisodesPage{}, err
+	}
+
+	page := EpisodesPage{Items: items, Total: total}
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		cursor, err := encodeEpisodeCursor(episodeCursor{Season: last.SeasonNumber, Episode: last.EpisodeNumber})
+		if err != nil {
+			return EpisodesPage{}, err
+		}
+		page.NextCursor = &cursor
+	}
+	return page, nil
+}
+
+// ListSeasons summarizes each season of a show. It returns pgx.ErrNoRows for unknown shows.
+func (s *Service) ListSeasons(ctx context.Context, showID string) ([]sqlc.ListSeasonSummariesRow, error) {
+	if _, err := s.q.GetShowByID(ctx, showID); err != nil {
+		return nil, err
+	}
+	return s.q.ListSeasonSummaries(ctx, showID)
+}
+
 func (s *Service) GetEpisodeByID(ctx context.Context, episodeID string) (sqlc.Episode, error) {
 	return s.q.GetEpisodeByID(ctx, episodeID)
 }
 
 func (s *Service) UpdateEpisode(ctx context.Context, episodeID string, req updateEpisodeRequest) (sqlc.Episode, error) {
-	if err := s.hooks.DispatchPre(ctx, hooks.EventEpisodeUpdatePre, map[string]any{
-		"internalEpisodeId": episodeID,
-		"request":           req,
-	}); err != nil {
+	patched, err := s.hooks.DispatchPre(ctx, hooks.EventEpisodeUpdatePre, &updateEpisodeHookPayload{
+		InternalEpisodeID: episodeID,
+		Request:           &req,
+	})
+	if err != nil {
 		return sqlc.Episode{}, err
 	}
+	if patched {
+		normalizeUpdateEpisodeRequest(&req)
+		if err := validateUpdateEpisodeRequest(req); err != nil {
+			return sqlc.Episode{}, hooks.InvalidPatchError(hooks.EventEpisodeUpdatePre, err)
+		}
+	}
 
 	externalIDs, err := marshalExternalIDs(req.ExternalIDs)
 	if err != nil {
 		return sqlc.Episode{}, err
 	}
 
-	updated, err := s.q.UpdateEpisode(ctx, sqlc.UpdateEpisodeParams{
-		InternalEpisodeID: episodeID,
-		ShowID:            req.ShowID,
-		SeasonNumber:      req.SeasonNumber,
-		EpisodeNumber:     req.EpisodeNumber,
-		Title:             req.Title,
-		AirDate:           req.AirDate,
-		RuntimeMinutes:    req.RuntimeMinutes,
-		ExternalIds:       externalIDs,
+	var updated sqlc.Episode
+	err = s.inTx(ctx, func(tx *Service) error {
+		updated, err = tx.q.UpdateEpisode(ctx, sqlc.UpdateEpisodeParams{
+			InternalEpisodeID: episodeID,
+			ShowID:            req.ShowID,
+			SeasonNumber:      req.SeasonNumber,
+			EpisodeNumber:     req.EpisodeNumber,
+			Title:             req.Title,
+			AirDate:           req.AirDate,
+			RuntimeMinutes:    req.RuntimeMinutes,
+			ExternalIds:       externalIDs,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.hooks.DispatchPost(ctx, hooks.EventEpisodeUpdatePost, updated)
 	})
 	if err != nil {
 		return sqlc.Episode{}, err
 	}
-
-	s.hooks.DispatchPost(ctx, hooks.EventEpisodeUpdatePost, updated)
 	return updated, nil
 }
 
 func (s *Service) DeleteEpisode(ctx context.Context, episodeID string) error {
-	if err := s.hooks.DispatchPre(ctx, hooks.EventEpisodeDeletePre, map[string]any{
+	if _, err := s.hooks.DispatchPre(ctx, hooks.EventEpisodeDeletePre, map[string]any{
 		"internalEpisodeId": episodeID,
 	}); err != nil {
 		return err
 	}
 
-	_, err := s.q.DeleteEpisode(ctx, episodeID)
+	return s.inTx(ctx, func(tx *Service) error {
+		if _, err := tx.q.DeleteEpisode(ctx, episodeID); err != nil {
+			return err
+		}
+		return tx.hooks.DispatchPost(ctx, hooks.EventEpisodeDeletePost, map[string]any{
+			"internalEpisodeId": episodeID,
+		})
+	})
+}
+
+// ImportEpisodes bulk-inserts provider episodes for a show, skipping rows that already
+// exist, and returns only the rows it created. Every created row emits
+// episode.create.post in the same transaction; no pre hooks run.
+func (s *Service) ImportEpisodes(ctx context.Context, showID string, items []ImportEpisode) ([]sqlc.Episode, error) {
+	if len(items) == 0 {
+		return []sqlc.Episode{}, nil
+	}
+
+	payload, err := json.Marshal(items)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	s.hooks.DispatchPost(ctx, hooks.EventEpisodeDeletePost, map[string]any{
-		"internalEpisodeId": episodeID,
+	var created []sqlc.Episode
+	err = s.inTx(ctx, func(tx *Service) error {
+		created, err = tx.q.InsertEpisodesIfNotExist(ctx, sqlc.InsertEpisodesIfNotExistParams{
+			ShowID:   showID,
+			Episodes: payload,
+		})
+		if err != nil {
+			return err
+		}
+		for _, item := range created {
+			if err := tx.hooks.DispatchPost(ctx, hooks.EventEpisodeCreatePost, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// SyncEpisodes brings a show's episodes in line with its provider: missing rows are
+// inserted and stored rows take the provider's title and air date, its runtime when it
+// has one, and its external ids merged over the stored ones. Rows that would not change
+// are left alone. Created rows emit episode.create.post and changed rows
+// episode.update.post in the same transaction; no pre hooks run.
+func (s *Service) SyncEpisodes(ctx context.Context, showID string, items []ImportEpisode) (SyncResult, error) {
+	result := SyncResult{Created: []sqlc.Episode{}, Updated: []sqlc.Episode{}}
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	payload, err := json.Marshal(items)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	err = s.inTx(ctx, func(tx *Service) error {
+		rows, err := tx.q.SyncProviderEpisodes(ctx, sqlc.SyncProviderEpisodesParams{
+			ShowID:   showID,
+			Episodes: payload,
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			episode := episodeFromSyncRow(row)
+			event := hooks.EventEpisodeUpdatePost
+			if row.Inserted {
+				event = hooks.EventEpisodeCreatePost
+				result.Created = append(result.Created, episode)
+			} else {
+				result.Updated = append(result.Updated, episode)
+			}
+			if err := tx.hooks.DispatchPost(ctx, event, episode); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return result, nil
+}
+
+// BulkUpsertEpisodes creates or updates a show's episodes keyed on season and episode
+// number. All valid items are written by one statement, so either all of them land or
+// none do; invalid and repeated items are reported as errors and skipped. A single
+// aggregated pre and post hook covers the whole batch. It returns pgx.ErrNoRows for
+// unknown shows.
+func (s *Service) BulkUpsertEpisodes(ctx context.Context, showID string, items []bulkEpisodeItem) (BulkUpsertResult, error) {
+	if _, err := s.q.GetShowByID(ctx, showID); err != nil {
+		return BulkUpsertResult{}, err
+	}
+
+	results := make([]BulkEpisodeResult, len(items))
+	positions := make(map[episodeKey]int, len(items))
+	valid := make([]bulkEpisodeItem, 0, len(items))
+	rows := make([]ImportEpisode, 0, len(items))
+	for i, item := range items {
+		results[i] = BulkEpisodeResult{
+			Index:         i,
+			SeasonNumber:  item.SeasonNumber,
+			EpisodeNumber: item.EpisodeNumber,
+		}
+		if err := validateBulkEpisodeItem(showID, item); err != nil {
+			results[i].Status = BulkStatusError
+			results[i].Error = err.Error()
+			continue
+		}
+		key := episodeKey{season: item.SeasonNumber, episode: item.EpisodeNumber}
+		if first, ok := positions[key]; ok {
+			results[i].Status = BulkStatusError
+			results[i].Error = fmt.Sprintf("duplicates item %d", first)
+			continue
+		}
+		positions[key] = i
+		valid = append(valid, item)
+		rows = append(rows, ImportEpisode{
+			SeasonNumber:   item.SeasonNumber,
+			EpisodeNumber:  item.EpisodeNumber,
+			Title:          item.Title,
+			AirDate:        item.AirDate,
+			RuntimeMinutes: item.RuntimeMinutes,
+			ExternalIDs:    item.ExternalIDs,
+		})
+	}
+
+	if len(rows) > 0 {
+		// Results are reported per request item, so the batch can be vetoed but not patched.
+		if _, err := s.hooks.DispatchPre(ctx, hooks.EventEpisodeBulkUpsertPre, map[string]any{
+			"internalShowId": showID,
+			"items":          valid,
+		}); err != nil {
+			return BulkUpsertResult{}, err
+		}
+
+		payload, err := json.Marshal(rows)
+		if err != nil {
+			return BulkUpsertResult{}, err
+		}
+		err = s.inTx(ctx, func(tx *Service) error {
+			upserted, err := tx.q.UpsertShowEpisodes(ctx, sqlc.UpsertShowEpisodesParams{
+				Episodes: payload,
+				ShowID:   showID,
+			})
+			if err != nil {
+				return err
+			}
+
+			var created, updated []sqlc.Episode
+			for _, row := range upserted {
+				i, ok := positions[episodeKey{season: row.SeasonNumber, episode: row.EpisodeNumber}]
+				if !ok {
+					continue
+				}
+				episode := episodeFromUpsertRow(row)
+				results[i].Status = row.Status
+				results[i].Episode = &episode
+				switch row.Status {
+				case BulkStatusCreated:
+					created = append(created, episode)
+				case BulkStatusUpdated:
+					updated = append(updated, episode)
+				}
+			}
+			if len(created) == 0 && len(updated) == 0 {
+				return nil
+			}
+
+			summary := summarizeBulkUpsert(results)
+			return tx.hooks.DispatchPost(ctx, hooks.EventEpisodeBulkUpsertPost, map[string]any{
+				"internalShowId": showID,
+				"created":        created,
+				"updated":        updated,
+				"unchanged":      summary.Unchanged,
+				"failed":         summary.Failed,
+			})
+		})
+		if err != nil {
+			return BulkUpsertResult{}, err
+		}
+	}
+
+	return summarizeBulkUpsert(results), nil
+}
+
+// WithTx returns a copy of the service whose queries and post hooks run inside tx.
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		q:     s.q.WithTx(tx),
+		hooks: s.hooks.WithTx(tx),
+	}
+}
+
+// inTx runs fn with a copy of the service bound to a new transaction, so a write commits
+// together with the post hooks it records. A service already bound to a transaction
+// passes itself.
+func (s *Service) inTx(ctx context.Context, fn func(tx *Service) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(s.WithTx(tx))
 	})
-	return nil
 }
@@ -3,6 +3,7 @@ package episode
 import (
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/hooks"
 )
@@ -11,6 +12,23 @@ const (
 	ctxCreateEpisodeRequestKey = "episode.create.request"
 	ctxUpdateEpisodeRequestKey = "episode.update.request"
 	ctxEpisodeIDKey            = "episode.id"
+	ctxShowIDKey               = "episode.show.id"
+	ctxListOptsKey             = "episode.list.opts"
+	ctxBulkEpisodesRequestKey  = "episode.bulk.request"
+)
+
+const (
+	BulkStatusCreated   = "created"
+	BulkStatusUpdated   = "updated"
+	BulkStatusUnchanged = "unchanged"
+	BulkStatusError     = "error"
+)
+
+const maxBulkEpisodes = 2000
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 500
 )
 
 type Handler struct {
@@ -18,15 +36,83 @@ type Handler struct {
 }
 
 type Service struct {
+	// pool is nil in copies bound to a transaction by WithTx.
+	pool  *pgxpool.Pool
 	q     *sqlc.Queries
 	hooks hooks.Dispatcher
 }
 
-type externalIDs struct {
+type ExternalIDs struct {
+	Anidb   *int64 `json:"anidb,omitempty"`
 	Anilist *int64 `json:"anilist,omitempty"`
 	Tvdb    *int64 `json:"tvdb,omitempty"`
 }
 
+// ImportEpisode is one row of a bulk import; the json tags match the insert's recordset columns.
+type ImportEpisode struct {
+	SeasonNumber   int64       `json:"season_number"`
+	EpisodeNumber  int64       `json:"episode_number"`
+	Title          string      `json:"title"`
+	AirDate        *string     `json:"air_date"`
+	RuntimeMinutes *int64      `json:"runtime_minutes"`
+	ExternalIDs    ExternalIDs `json:"external_ids"`
+}
+
+// SyncResult is what SyncEpisodes wrote: the provider episodes it created and the stored
+// ones whose provider fields changed.
+type SyncResult struct {
+	Created []sqlc.Episode
+	Updated []sqlc.Episode
+}
+
+// ListShowEpisodesOpts filters and pages the episodes of one show. CursorSeason and
+// CursorEpisode come from the previous page's cursor.
+type ListShowEpisodesOpts struct {
+	SeasonNumber  *int64
+	AiredAfter    *string
+	AiredBefore   *string
+	Limit         int
+	CursorSeason  *int64
+	CursorEpisode *int64
+}
+
+type EpisodesPage struct {
+	Items      []sqlc.Episode
+	NextCursor *string
+	Total      int64
+}
+
+// episodeCursor is the opaque nextCursor: the position of the last episode on a page.
+type episodeCursor struct {
+	Season  int64 `json:"s"`
+	Episode int64 `json:"e"`
+}
+
+// BulkEpisodeResult is the outcome of one bulk upsert item. Episode is nil for errors.
+type BulkEpisodeResult struct {
+	Index         int
+	SeasonNumber  int64
+	EpisodeNumber int64
+	Status        string
+	Episode       *sqlc.Episode
+	Error         string
+}
+
+// BulkUpsertResult lists the item outcomes in request order with per-status totals.
+type BulkUpsertResult struct {
+	Items     []BulkEpisodeResult
+	Created   int
+	Updated   int
+	Unchanged int
+	Failed    int
+}
+
+// episodeKey identifies an episode within one show.
+type episodeKey struct {
+	season  int64
+	episode int64
+}
+
 type createEpisodeRequest struct {
 	ShowID         string      `json:"showId"`
 	SeasonNumber   int64       `json:"seasonNumber"`
@@ -34,7 +120,7 @@ type createEpisodeRequest struct {
 	Title          string      `json:"title"`
 	AirDate        *string     `json:"airDate"`
 	RuntimeMinutes *int64      `json:"runtimeMinutes"`
-	ExternalIDs    externalIDs `json:"externalIds"`
+	ExternalIDs    ExternalIDs `json:"externalIds"`
 }
 
 type updateEpisodeRequest struct {
@@ -44,7 +130,24 @@ type updateEpisodeRequest struct {
 	Title          string      `json:"title"`
 	AirDate        *string     `json:"airDate"`
 	RuntimeMinutes *int64      `json:"runtimeMinutes"`
-	ExternalIDs    externalIDs `json:"externalIds"`
+	ExternalIDs    ExternalIDs `json:"externalIds"`
+}
+
+// updateEpisodeHookPayload is the episode.update.pre payload. A pre hook can patch
+// Request; the episode being updated stays the one in the path.
+type updateEpisodeHookPayload struct {
+	InternalEpisodeID string                `json:"internalEpisodeId"`
+	Request           *updateEpisodeRequest `json:"request"`
+}
+
+// bulkEpisodeItem is one element of the bulk upsert body; the show comes from the path.
+type bulkEpisodeItem struct {
+	SeasonNumber   int64       `json:"seasonNumber"`
+	EpisodeNumber  int64       `json:"episodeNumber"`
+	Title          string      `json:"title"`
+	AirDate        *string     `json:"airDate"`
+	RuntimeMinutes *int64      `json:"runtimeMinutes"`
+	ExternalIDs    ExternalIDs `json:"externalIds"`
 }
 
 type episodeResponse struct {
@@ -55,7 +158,38 @@ type episodeResponse struct {
 	Title             string      `json:"title"`
 	AirDate           *string     `json:"airDate,omitempty"`
 	RuntimeMinutes    *int64      `json:"runtimeMinutes,omitempty"`
-	ExternalIDs       externalIDs `json:"externalIds"`
+	ExternalIDs       ExternalIDs `json:"externalIds"`
 	CreatedAt         time.Time   `json:"createdAt"`
 	UpdatedAt         time.Time   `json:"updatedAt"`
 }
+
+type listEpisodesResponse struct {
+	Items      []episodeResponse `json:"items"`
+	NextCursor *string           `json:"nextCursor"`
+	Total      int64             `json:"total"`
+}
+
+type seasonSummaryResponse struct {
+	SeasonNumber        int64   `json:"seasonNumber"`
+	EpisodeCount        int64   `json:"episodeCount"`
+	FirstAirDate        *string `json:"firstAirDate,omitempty"`
+	LastAirDate         *string `json:"lastAirDate,omitempty"`
+	TotalRuntimeMinutes int64   `json:"totalRuntimeMinutes"`
+}
+
+type bulkEpisodeResultResponse struct {
+	Index         int              `json:"index"`
+	SeasonNumber  int64            `json:"seasonNumber"`
+	EpisodeNumber int64            `json:"episodeNumber"`
+	Status        string           `json:"status"`
+	Episode       *episodeResponse `json:"episode,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+type bulkUpsertEpisodesResponse struct {
+	Items     []bulkEpisodeResultResponse `json:"items"`
+	Created   int                         `json:"created"`
+	Updated   int                         `json:"updated"`
+	Unchanged int                         `json:"unchanged"`
+	Failed    int                         `json:"failed"`
+}
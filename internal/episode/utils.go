@@ -1,8 +1,11 @@
 package episode
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
@@ -39,7 +42,7 @@ func validateUpdateEpisodeRequest(req updateEpisodeRequest) error {
 	return validateEpisodePayload(req.ShowID, req.SeasonNumber, req.EpisodeNumber, req.Title, req.AirDate, req.RuntimeMinutes, req.ExternalIDs)
 }
 
-func validateEpisodePayload(showID string, seasonNumber, episodeNumber int64, title string, airDate *string, runtimeMinutes *int64, externalIDs externalIDs) error {
+func validateEpisodePayload(showID string, seasonNumber, episodeNumber int64, title string, airDate *string, runtimeMinutes *int64, externalIDs ExternalIDs) error {
 	if err := httpx.ValidateVar(showID, "required,uuid4", "showId is invalid"); err != nil {
 		return err
 	}
@@ -66,14 +69,93 @@ func validateEpisodePayload(showID string, seasonNumber, episodeNumber int64, ti
 	return nil
 }
 
+func normalizeBulkEpisodeItems(items []bulkEpisodeItem) {
+	for i := range items {
+		items[i].Title = normalizeutil.String(items[i].Title)
+		items[i].AirDate = normalizeutil.StringPtr(items[i].AirDate)
+	}
+}
+
+func validateBulkEpisodeItems(items []bulkEpisodeItem) error {
+	if len(items) == 0 || len(items) > maxBulkEpisodes {
+		return fmt.Errorf("episodes must contain between 1 and %d items", maxBulkEpisodes)
+	}
+	return nil
+}
+
+func validateBulkEpisodeItem(showID string, item bulkEpisodeItem) error {
+	return validateEpisodePayload(showID, item.SeasonNumber, item.EpisodeNumber, item.Title, item.AirDate, item.RuntimeMinutes, item.ExternalIDs)
+}
+
 func validateEpisodeID(id string) error {
 	return httpx.ValidateVar(id, "required,uuid4", "internalEpisodeId is invalid")
 }
 
-func validateExternalIDs(ids externalIDs) error {
-	if ids.Anilist == nil && ids.Tvdb == nil {
+func validateShowID(id string) error {
+	return httpx.ValidateVar(id, "required,uuid4", "internalShowId is invalid")
+}
+
+func parseListShowEpisodesOpts(season string, airedAfter string, airedBefore string, limit string, cursor string) (ListShowEpisodesOpts, error) {
+	opts := ListShowEpisodesOpts{
+		AiredAfter:  normalizeutil.StringValuePtr(airedAfter),
+		AiredBefore: normalizeutil.StringValuePtr(airedBefore),
+		Limit:       normalizeutil.Limit(httpx.ParsePositiveInt(limit, defaultListLimit), defaultListLimit, maxListLimit),
+	}
+
+	if season = normalizeutil.String(season); season != "" {
+		value, err := strconv.ParseInt(season, 10, 64)
+		if err != nil || value < 0 {
+			return ListShowEpisodesOpts{}, errors.New("season is invalid")
+		}
+		opts.SeasonNumber = &value
+	}
+	if err := httpx.ValidateOptionalDate(opts.AiredAfter, "airedAfter is invalid"); err != nil {
+		return ListShowEpisodesOpts{}, err
+	}
+	if err := httpx.ValidateOptionalDate(opts.AiredBefore, "airedBefore is invalid"); err != nil {
+		return ListShowEpisodesOpts{}, err
+	}
+
+	if cursor = normalizeutil.String(cursor); cursor != "" {
+		decoded, err := decodeEpisodeCursor(cursor)
+		if err != nil {
+			return ListShowEpisodesOpts{}, err
+		}
+		opts.CursorSeason = &decoded.Season
+		opts.CursorEpisode = &decoded.Episode
+	}
+	return opts, nil
+}
+
+func encodeEpisodeCursor(cursor episodeCursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeEpisodeCursor(value string) (episodeCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return episodeCursor{}, errors.New("cursor is invalid")
+	}
+	var cursor episodeCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return episodeCursor{}, errors.New("cursor is invalid")
+	}
+	return cursor, nil
+}
+
+func validateExternalIDs(ids ExternalIDs) error {
+	if ids.Anidb == nil && ids.Anilist == nil && ids.Tvdb == nil {
 		return errors.New("externalIds must include at least one provider id")
 	}
+	if ids.Anidb != nil {
+		if err := httpx.ValidateVar(*ids.Anidb, "gt=0", "externalIds.anidb is invalid"); err != nil {
+			return err
+		}
+	}
 	if ids.Anilist != nil {
 		if err := httpx.ValidateVar(*ids.Anilist, "gt=0", "externalIds.anilist is invalid"); err != nil {
 			return err
@@ -87,17 +169,17 @@ func validateExternalIDs(ids externalIDs) error {
 	return nil
 }
 
-func marshalExternalIDs(ids externalIDs) ([]byte, error) {
+func marshalExternalIDs(ids ExternalIDs) ([]byte, error) {
 	return json.Marshal(ids)
 }
 
-func unmarshalExternalIDs(raw []byte) (externalIDs, error) {
+func unmarshalExternalIDs(raw []byte) (ExternalIDs, error) {
 	if len(raw) == 0 {
-		return externalIDs{}, nil
+		return ExternalIDs{}, nil
 	}
-	var ids externalIDs
+	var ids ExternalIDs
 	if err := json.Unmarshal(raw, &ids); err != nil {
-		return externalIDs{}, err
+		return ExternalIDs{}, err
 	}
 	return ids, nil
 }
@@ -120,3 +202,94 @@ func toEpisodeResponse(item sqlc.Episode) (episodeResponse, error) {
 		UpdatedAt:         item.UpdatedAt,
 	}, nil
 }
+
+func toSeasonSummaryResponse(item sqlc.ListSeasonSummariesRow) seasonSummaryResponse {
+	return seasonSummaryResponse{
+		SeasonNumber:        item.SeasonNumber,
+		EpisodeCount:        item.EpisodeCount,
+		FirstAirDate:        item.FirstAirDate,
+		LastAirDate:         item.LastAirDate,
+		TotalRuntimeMinutes: item.TotalRuntimeMinutes,
+	}
+}
+
+func episodeFromUpsertRow(row sqlc.UpsertShowEpisodesRow) sqlc.Episode {
+	return sqlc.Episode{
+		InternalEpisodeID: row.InternalEpisodeID,
+		ShowID:            row.ShowID,
+		SeasonNumber:      row.SeasonNumber,
+		EpisodeNumber:     row.EpisodeNumber,
+		Title:             row.Title,
+		AirDate:           row.AirDate,
+		RuntimeMinutes:    row.RuntimeMinutes,
+		ExternalIds:       row.ExternalIds,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}
+}
+
+func episodeFromSyncRow(row sqlc.SyncProviderEpisodesRow) sqlc.Episode {
+	return sqlc.Episode{
+		InternalEpisodeID: row.InternalEpisodeID,
+		ShowID:            row.ShowID,
+		SeasonNumber:      row.SeasonNumber,
+		EpisodeNumber:     row.EpisodeNumber,
+		Title:             row.Title,
+		AirDate:           row.AirDate,
+		RuntimeMinutes:    row.RuntimeMinutes,
+		ExternalIds:       row.ExternalIds,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+	}
+}
+
+// summarizeBulkUpsert counts the outcome of every item. Valid items the upsert did not
+// return are marked unchanged: a row inserted concurrently after the statement's snapshot
+// matches on conflict but is not returned; it was left as the other writer stored it.
+func summarizeBulkUpsert(results []BulkEpisodeResult) BulkUpsertResult {
+	result := BulkUpsertResult{Items: results}
+	for i := range results {
+		if results[i].Status == "" {
+			results[i].Status = BulkStatusUnchanged
+		}
+		switch results[i].Status {
+		case BulkStatusCreated:
+			result.Created++
+		case BulkStatusUpdated:
+			result.Updated++
+		case BulkStatusUnchanged:
+			result.Unchanged++
+		case BulkStatusError:
+			result.Failed++
+		}
+	}
+	return result
+}
+
+func toBulkUpsertEpisodesResponse(result BulkUpsertResult) (bulkUpsertEpisodesResponse, error) {
+	items := make([]bulkEpisodeResultResponse, 0, len(result.Items))
+	for _, item := range result.Items {
+		response := bulkEpisodeResultResponse{
+			Index:         item.Index,
+			SeasonNumber:  item.SeasonNumber,
+			EpisodeNumber: item.EpisodeNumber,
+			Status:        item.Status,
+			Error:         item.Error,
+		}
+		if item.Episode != nil {
+			episode, err := toEpisodeResponse(*item.Episode)
+			if err != nil {
+				return bulkUpsertEpisodesResponse{}, err
+			}
+			response.Episode = &episode
+		}
+		items = append(items, response)
+	}
+	return bulkUpsertEpisodesResponse{
+		Items:     items,
+		Created:   result.Created,
+		Updated:   result.Updated,
+		Unchanged: result.Unchanged,
+		Failed:    result.Failed,
+	}, nil
+}
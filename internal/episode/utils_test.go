@@ -0,0 +1,89 @@
+package episode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBulkEpisodeItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		wantErr bool
+	}{
+		{name: "empty", count: 0, wantErr: true},
+		{name: "one", count: 1},
+		{name: "at the limit", count: maxBulkEpisodes},
+		{name: "over the limit", count: maxBulkEpisodes + 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBulkEpisodeItems(make([]bulkEpisodeItem, tt.count))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBulkEpisodeItem(t *testing.T) {
+	const showID = "3cb5e44c-9cb6-4eb1-b34d-9c57e513c127"
+	airDate := "2023-09-29"
+	badDate := "29/09/2023"
+	negative := int64(-1)
+	tvdbID := int64(349232)
+	ids := ExternalIDs{Tvdb: &tvdbID}
+
+	tests := []struct {
+		name    string
+		showID  string
+		item    bulkEpisodeItem
+		wantErr string
+	}{
+		{name: "valid", showID: showID, item: bulkEpisodeItem{SeasonNumber: 1, EpisodeNumber: 1, Title: "Pilot", AirDate: &airDate, ExternalIDs: ids}},
+		{name: "specials season", showID: showID, item: bulkEpisodeItem{SeasonNumber: 0, EpisodeNumber: 3, Title: "OVA", ExternalIDs: ids}},
+		{name: "bad show id", showID: "nope", item: bulkEpisodeItem{Title: "Pilot"}, wantErr: "showId is invalid"},
+		{name: "negative season", showID: showID, item: bulkEpisodeItem{SeasonNumber: -1, Title: "Pilot"}, wantErr: "seasonNumber is invalid"},
+		{name: "missing title", showID: showID, item: bulkEpisodeItem{SeasonNumber: 1, EpisodeNumber: 1}, wantErr: "title is invalid"},
+		{name: "no external ids", showID: showID, item: bulkEpisodeItem{SeasonNumber: 1, EpisodeNumber: 1, Title: "Pilot"}, wantErr: "externalIds"},
+		{name: "bad air date", showID: showID, item: bulkEpisodeItem{Title: "Pilot", AirDate: &badDate}, wantErr: "airDate is invalid"},
+		{name: "negative runtime", showID: showID, item: bulkEpisodeItem{Title: "Pilot", RuntimeMinutes: &negative}, wantErr: "runtimeMinutes is invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBulkEpisodeItem(tt.showID, tt.item)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSummarizeBulkUpsert(t *testing.T) {
+	results := []BulkEpisodeResult{
+		{Index: 0, Status: BulkStatusCreated},
+		{Index: 1, Status: BulkStatusUpdated},
+		{Index: 2, Status: BulkStatusError, Error: "title is invalid"},
+		{Index: 3},
+		{Index: 4, Status: BulkStatusCreated},
+		{Index: 5, Status: BulkStatusUnchanged},
+	}
+
+	got := summarizeBulkUpsert(results)
+	if got.Created != 2 || got.Updated != 1 || got.Unchanged != 2 || got.Failed != 1 {
+		t.Errorf("totals = created %d updated %d unchanged %d failed %d, want 2 1 2 1",
+			got.Created, got.Updated, got.Unchanged, got.Failed)
+	}
+	if got.Items[3].Status != BulkStatusUnchanged {
+		t.Errorf("item 3 status = %q, want %q", got.Items[3].Status, BulkStatusUnchanged)
+	}
+	if len(got.Items) != len(results) {
+		t.Errorf("len(Items) = %d, want %d", len(got.Items), len(results))
+	}
+}
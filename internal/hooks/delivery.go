@@ -0,0 +1,188 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/utils/workerid"
+)
+
+func NewDeliveryWorker(pool *pgxpool.Pool, cipher *cryptox.Cipher, opts DeliveryWorkerOptions) *DeliveryWorker {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultDeliveryPollInterval
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = defaultDeliveryMaxAttempts
+	}
+	return &DeliveryWorker{
+		q:      sqlc.New(pool),
+		cipher: cipher,
+		client: &http.Client{Timeout: maxHookRequestTimeout},
+		id:     workerid.New(),
+		opts:   opts,
+	}
+}
+
+// Run sends due hook deliveries until ctx is cancelled. Claims use SKIP LOCKED, so
+// several instances can share the queue; a delivery is sent at least once and the
+// receiver can drop repeats by its X-Hook-Delivery-Id header.
+func (w *DeliveryWorker) Run(ctx context.Context) {
+	log.Printf("hook delivery worker %s started", w.id)
+
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("hook delivery worker %s stopped", w.id)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *DeliveryWorker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := w.processNext(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("hook delivery worker %s: %v", w.id, err)
+			}
+			return
+		}
+		if !processed {
+			return
+		}
+	}
+}
+
+func (w *DeliveryWorker) processNext(ctx context.Context) (bool, error) {
+	item, err := w.q.ClaimNextHookDelivery(ctx, sqlc.ClaimNextHookDeliveryParams{
+		LockedBy:    w.id,
+		StaleBefore: time.Now().Add(-deliveryStaleAfter),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim hook delivery: %w", err)
+	}
+
+	statusCode, sendErr := w.send(ctx, item)
+	// On shutdown the delivery stays processing and is claimed again once it goes stale.
+	if ctx.Err() != nil {
+		return true, ctx.Err()
+	}
+	if err := w.finish(ctx, item, statusCode, sendErr); err != nil {
+		return true, fmt.Errorf("record result for hook delivery %s: %w", item.InternalHookDeliveryID, err)
+	}
+	return true, nil
+}
+
+// send posts the delivery to its subscription, stamped with the time the event was
+// recorded. The subscription's URL, headers, timeout and secrets are read as they are
+// now, so a retry after an edit or a rotation uses the new settings. It returns
+// errSubscriptionRemoved once the subscription has been deleted and
+// errSubscriptionDisabled while it is disabled.
+func (w *DeliveryWorker) send(ctx context.Context, item sqlc.HookDelivery) (int, error) {
+	event := Event(item.EventName)
+	if item.SubscriptionID == nil {
+		return 0, errSubscriptionRemoved
+	}
+	subscription, err := w.q.GetHookSubscriptionByID(ctx, *item.SubscriptionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, errSubscriptionRemoved
+	}
+	if err != nil {
+		return 0, fmt.Errorf("load hook subscription %s: %w", *item.SubscriptionID, err)
+	}
+	if !subscription.Enabled {
+		return 0, errSubscriptionDisabled
+	}
+	target, err := subscriptionTarget(w.cipher, subscription, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(dispatchBody{
+		Event:     event,
+		Payload:   json.RawMessage(item.Payload),
+		Timestamp: item.CreatedAt.UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	res, err := postHook(ctx, w.client, target, event, item.InternalHookDeliveryID, body)
+	return res.StatusCode, err
+}
+
+func (w *DeliveryWorker) finish(ctx context.Context, item sqlc.HookDelivery, statusCode int, sendErr error) error {
+	if sendErr == nil {
+		_, err := w.q.MarkHookDeliveryDelivered(ctx, sqlc.MarkHookDeliveryDeliveredParams{
+			LastStatusCode:         int32(statusCode),
+			InternalHookDeliveryID: item.InternalHookDeliveryID,
+		})
+		return err
+	}
+
+	lastStatusCode := deliveryStatusCode(statusCode)
+	if int(item.Attempts) >= w.opts.MaxAttempts || errors.Is(sendErr, errSubscriptionRemoved) || errors.Is(sendErr, errSubscriptionDisabled) {
+		log.Printf("hook delivery %s event=%s dead after %d attempts: %v", item.InternalHookDeliveryID, item.EventName, item.Attempts, sendErr)
+		_, err := w.q.KillHookDelivery(ctx, sqlc.KillHookDeliveryParams{
+			LastError:              deliveryErrorMessage(sendErr),
+			LastStatusCode:         lastStatusCode,
+			InternalHookDeliveryID: item.InternalHookDeliveryID,
+		})
+		return err
+	}
+
+	delay := deliveryRetryDelay(item.Attempts)
+	log.Printf("hook delivery %s event=%s attempt %d failed, retrying in %s: %v", item.InternalHookDeliveryID, item.EventName, item.Attempts, delay, sendErr)
+	_, err := w.q.RetryHookDelivery(ctx, sqlc.RetryHookDeliveryParams{
+		LastError:              deliveryErrorMessage(sendErr),
+		LastStatusCode:         lastStatusCode,
+		NextAttemptAt:          time.Now().Add(delay),
+		InternalHookDeliveryID: item.InternalHookDeliveryID,
+	})
+	return err
+}
+
+// deliveryRetryDelay doubles the base delay for every attempt after the first, capped
+// at maxDeliveryRetryDelay.
+func deliveryRetryDelay(attempts int32) time.Duration {
+	delay := baseDeliveryRetryDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxDeliveryRetryDelay {
+			return maxDeliveryRetryDelay
+		}
+	}
+	return delay
+}
+
+func deliveryStatusCode(statusCode int) *int32 {
+	if statusCode == 0 {
+		return nil
+	}
+	code := int32(statusCode)
+	return &code
+}
+
+func deliveryErrorMessage(err error) string {
+	message := err.Error()
+	if len(message) > maxDeliveryErrorLen {
+		message = message[:maxDeliveryErrorLen]
+	}
+	return message
+}
@@ -1,6 +1,10 @@
 package hooks
 
-import "context"
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
 
 type Event string
 
@@ -17,17 +21,34 @@ const (
 	EventEpisodeUpdatePost Event = "episode.update.post"
 	EventEpisodeDeletePre  Event = "episode.delete.pre"
 	EventEpisodeDeletePost Event = "episode.delete.post"
+
+	EventEpisodeBulkUpsertPre  Event = "episode.bulk_upsert.pre"
+	EventEpisodeBulkUpsertPost Event = "episode.bulk_upsert.post"
 )
 
+// Dispatcher runs pre hooks inline and records post events for delivery. DispatchPre
+// returns a *RejectedError when a receiver vetoes the change and a *FailedError when a
+// fail-closed receiver fails. When payload is a pointer, payloads returned by receivers
+// are decoded into it and patched is true; callers then validate it again. DispatchPost
+// fails only when the event could not be recorded; callers return that error so the
+// mutation is rolled back with it.
 type Dispatcher interface {
-	DispatchPre(ctx context.Context, event Event, payload any) error
-	DispatchPost(ctx context.Context, event Event, payload any)
+	DispatchPre(ctx context.Context, event Event, payload any) (patched bool, err error)
+	DispatchPost(ctx context.Context, event Event, payload any) error
+	// WithTx returns a copy of the dispatcher that records post events inside tx.
+	WithTx(tx pgx.Tx) Dispatcher
 }
 
 type NoopDispatcher struct{}
 
-func (NoopDispatcher) DispatchPre(context.Context, Event, any) error {
+func (NoopDispatcher) DispatchPre(context.Context, Event, any) (bool, error) {
+	return false, nil
+}
+
+func (NoopDispatcher) DispatchPost(context.Context, Event, any) error {
 	return nil
 }
 
-func (NoopDispatcher) DispatchPost(context.Context, Event, any) {}
+func (d NoopDispatcher) WithTx(pgx.Tx) Dispatcher {
+	return d
+}
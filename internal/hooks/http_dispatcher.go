@@ -5,18 +5,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 )
 
-const hookRequestTimeout = 5 * time.Second
+// maxHookRequestTimeout caps every hook request; subscriptions pick a shorter timeout.
+const maxHookRequestTimeout = 30 * time.Second
 
 type HTTPDispatcher struct {
-	pool   *pgxpool.Pool
+	q      *sqlc.Queries
+	cipher *cryptox.Cipher
 	client *http.Client
 }
 
@@ -26,75 +31,161 @@ type dispatchBody struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
-func NewHTTPDispatcher(pool *pgxpool.Pool) (*HTTPDispatcher, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := EnsureStore(ctx, pool); err != nil {
-		return nil, err
-	}
-
+// NewHTTPDispatcher returns a dispatcher reading hook subscriptions from pool. cipher
+// decrypts their signing secrets.
+func NewHTTPDispatcher(pool *pgxpool.Pool, cipher *cryptox.Cipher) *HTTPDispatcher {
 	return &HTTPDispatcher{
-		pool: pool,
+		q:      sqlc.New(pool),
+		cipher: cipher,
 		client: &http.Client{
-			Timeout: hookRequestTimeout,
+			Timeout: maxHookRequestTimeout,
 		},
-	}, nil
+	}
 }
 
-func (d *HTTPDispatcher) DispatchPre(ctx context.Context, event Event, payload any) error {
-	return d.dispatch(ctx, event, payload)
+// DispatchPre calls every enabled subscription of event whose filter matches, one after
+// another, each with the payload as patched by the ones before it. A receiver failure
+// stops the change unless the subscription fails open, in which case it is logged and
+// skipped.
+func (d *HTTPDispatcher) DispatchPre(ctx context.Context, event Event, payload any) (bool, error) {
+	if !IsValidEvent(event) {
+		return false, fmt.Errorf("invalid hook event %q", event)
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+	subscriptions, err := matchingSubscriptions(ctx, d.q, event, payloadBytes)
+	if err != nil {
+		return false, err
+	}
+
+	patched := false
+	for _, subscription := range subscriptions {
+		response, err := d.callPreHook(ctx, subscription, event, payloadBytes)
+		if err == nil && !response.allowed() {
+			return false, &RejectedError{Event: event, Reason: response.rejectReason()}
+		}
+		applied := false
+		if err == nil && response.hasPatch() {
+			applied, err = applyPatch(payload, response.Payload)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			if subscription.FailureMode == FailureModeOpen {
+				log.Printf("%s hook %s failed open: %v", event, subscription.InternalHookSubscriptionID, err)
+				continue
+			}
+			return false, &FailedError{Event: event, Err: err}
+		}
+		if applied {
+			patched = true
+			if payloadBytes, err = json.Marshal(payload); err != nil {
+				return false, err
+			}
+		}
+	}
+	return patched, nil
 }
 
-func (d *HTTPDispatcher) DispatchPost(ctx context.Context, event Event, payload any) {
-	if err := d.dispatch(ctx, event, payload); err != nil {
-		log.Printf("hook dispatch failed event=%s err=%v", event, err)
+// callPreHook sends payload to one subscription and reads its answer.
+func (d *HTTPDispatcher) callPreHook(ctx context.Context, subscription sqlc.HookSubscription, event Event, payload []byte) (preHookResponse, error) {
+	target, err := subscriptionTarget(d.cipher, subscription, time.Now())
+	if err != nil {
+		return preHookResponse{}, err
+	}
+	deliveryID, err := newDeliveryID()
+	if err != nil {
+		return preHookResponse{}, err
 	}
+	body, err := json.Marshal(dispatchBody{
+		Event:     event,
+		Payload:   json.RawMessage(payload),
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		return preHookResponse{}, err
+	}
+
+	res, err := postHook(ctx, d.client, target, event, deliveryID, body)
+	if err != nil {
+		return preHookResponse{}, err
+	}
+	return decodePreHookResponse(res.Header, res.Body)
 }
 
-func (d *HTTPDispatcher) dispatch(ctx context.Context, event Event, payload any) error {
+// DispatchPost queues one hook_deliveries row per enabled subscription of event whose
+// filter matches. The DeliveryWorker sends them once the surrounding transaction commits.
+func (d *HTTPDispatcher) DispatchPost(ctx context.Context, event Event, payload any) error {
 	if !IsValidEvent(event) {
 		return fmt.Errorf("invalid hook event %q", event)
 	}
 
-	var targetURL string
-	if err := d.pool.QueryRow(ctx, `
-SELECT target_url
-FROM hook_settings
-WHERE event_name = $1
-`, string(event)).Scan(&targetURL); err != nil {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
 		return err
 	}
-	targetURL = strings.TrimSpace(targetURL)
-	if targetURL == "" {
-		return nil
-	}
-
-	bodyBytes, err := json.Marshal(dispatchBody{
-		Event:     event,
-		Payload:   payload,
-		Timestamp: time.Now().UTC(),
-	})
+	subscriptions, err := matchingSubscriptions(ctx, d.q, event, payloadBytes)
 	if err != nil {
 		return err
 	}
+	for _, subscription := range subscriptions {
+		if err := d.q.CreateHookDelivery(ctx, sqlc.CreateHookDeliveryParams{
+			SubscriptionID: subscription.InternalHookSubscriptionID,
+			EventName:      string(event),
+			Payload:        payloadBytes,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *HTTPDispatcher) WithTx(tx pgx.Tx) Dispatcher {
+	return &HTTPDispatcher{
+		q:      d.q.WithTx(tx),
+		cipher: d.cipher,
+		client: d.client,
+	}
+}
+
+// postHook sends one signed hook request within the target's timeout. The response
+// status is 0 when no response arrived. Non-2xx responses are returned as errors.
+func postHook(ctx context.Context, client *http.Client, target hookTarget, event Event, deliveryID string, body []byte) (hookResponse, error) {
+	if target.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, target.Timeout)
+		defer cancel()
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
 	if err != nil {
-		return err
+		return hookResponse{}, err
+	}
+	for name, value := range target.Headers {
+		req.Header.Set(name, value)
 	}
 	setHookRequestHeaders(req, event)
+	signHookRequest(req, deliveryID, body, target.Secrets, time.Now())
 
-	res, err := d.client.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
-		return err
+		return hookResponse{}, err
 	}
 	defer res.Body.Close()
 
+	response := hookResponse{StatusCode: res.StatusCode, Header: res.Header}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return fmt.Errorf("hook endpoint returned status %d", res.StatusCode)
+		return response, fmt.Errorf("hook endpoint returned status %d", res.StatusCode)
 	}
-	return nil
+	response.Body, err = io.ReadAll(io.LimitReader(res.Body, maxHookResponseBytes))
+	if err != nil {
+		return response, fmt.Errorf("read hook response: %w", err)
+	}
+	return response, nil
 }
 
 func setHookRequestHeaders(req *http.Request, event Event) {
@@ -0,0 +1,105 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httperr"
+)
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s hook rejected the request: %s", e.Event, e.Reason)
+}
+
+func (e *FailedError) Error() string {
+	return fmt.Sprintf("%s hook failed: %v", e.Event, e.Err)
+}
+
+func (e *FailedError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidPatchError is the *FailedError for a patched payload that no longer validates.
+func InvalidPatchError(event Event, err error) error {
+	return &FailedError{Event: event, Err: fmt.Errorf("patched payload is invalid: %w", err)}
+}
+
+// AbortIfHookErr answers a *RejectedError with 422 and a *FailedError with 502.
+func AbortIfHookErr(c *gin.Context, err error) bool {
+	httpErr, ok := HTTPError(err)
+	if !ok {
+		return false
+	}
+	httperr.Abort(c, httpErr)
+	return true
+}
+
+// HTTPError maps pre-hook errors to their response, for callers that report the error
+// somewhere other than the response status.
+func HTTPError(err error) (*httperr.HTTPError, bool) {
+	var rejected *RejectedError
+	if errors.As(err, &rejected) {
+		return httperr.HookRejected(rejected.Error()).WithCause(err), true
+	}
+	var failed *FailedError
+	if errors.As(err, &failed) {
+		return httperr.HookFailed(failed.Error()).WithCause(err), true
+	}
+	return nil, false
+}
+
+// decodePreHookResponse reads a receiver's answer. Bodies that are empty or not JSON
+// allow the change unchanged, so receivers that only acknowledge keep working.
+func decodePreHookResponse(header http.Header, body []byte) (preHookResponse, error) {
+	var response preHookResponse
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType != "application/json" || len(bytes.TrimSpace(body)) == 0 {
+		return response, nil
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return preHookResponse{}, fmt.Errorf("decode hook response: %w", err)
+	}
+	return response, nil
+}
+
+func (r preHookResponse) allowed() bool {
+	return r.Allow == nil || *r.Allow
+}
+
+func (r preHookResponse) rejectReason() string {
+	reason := r.Reason
+	if reason == "" {
+		return defaultRejectReason
+	}
+	if len(reason) > maxRejectReasonLen {
+		reason = reason[:maxRejectReasonLen]
+	}
+	return reason
+}
+
+func (r preHookResponse) hasPatch() bool {
+	return len(r.Payload) > 0 && string(r.Payload) != "null"
+}
+
+// applyPatch decodes patch over payload. The patch is first decoded into a zero value of
+// the payload's type, so one that does not fit leaves payload untouched. Patches for
+// payloads that are not pointers are ignored.
+func applyPatch(payload any, patch json.RawMessage) (bool, error) {
+	value := reflect.ValueOf(payload)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return false, nil
+	}
+	if err := json.Unmarshal(patch, reflect.New(value.Elem().Type()).Interface()); err != nil {
+		return false, fmt.Errorf("decode patched payload: %w", err)
+	}
+	if err := json.Unmarshal(patch, payload); err != nil {
+		return false, fmt.Errorf("decode patched payload: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,162 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodePreHookResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantAllowed bool
+		wantReason  string
+		wantPatch   bool
+		wantErr     bool
+	}{
+		{name: "empty body", contentType: "application/json", body: "", wantAllowed: true, wantReason: defaultRejectReason},
+		{name: "whitespace body", contentType: "application/json", body: " \n", wantAllowed: true, wantReason: defaultRejectReason},
+		{name: "not json", contentType: "text/plain", body: `{"allow":false}`, wantAllowed: true, wantReason: defaultRejectReason},
+		{name: "no content type", contentType: "", body: `{"allow":false}`, wantAllowed: true, wantReason: defaultRejectReason},
+		{name: "allow omitted", contentType: "application/json", body: `{"reason":"ok"}`, wantAllowed: true, wantReason: "ok"},
+		{name: "rejected", contentType: "application/json", body: `{"allow":false,"reason":"no sequels"}`, wantAllowed: false, wantReason: "no sequels"},
+		{name: "rejected without reason", contentType: "application/json; charset=utf-8", body: `{"allow":false}`, wantAllowed: false, wantReason: defaultRejectReason},
+		{name: "patch", contentType: "application/json", body: `{"allow":true,"payload":{"title":"New"}}`, wantAllowed: true, wantReason: defaultRejectReason, wantPatch: true},
+		{name: "null patch", contentType: "application/json", body: `{"payload":null}`, wantAllowed: true, wantReason: defaultRejectReason},
+		{name: "malformed json", contentType: "application/json", body: `{"allow":`, wantErr: true},
+		{name: "allow is not a boolean", contentType: "application/json", body: `{"allow":"no"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.contentType != "" {
+				header.Set("Content-Type", tt.contentType)
+			}
+			response, err := decodePreHookResponse(header, []byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decodePreHookResponse = %+v, want error", response)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodePreHookResponse: %v", err)
+			}
+			if got := response.allowed(); got != tt.wantAllowed {
+				t.Errorf("allowed = %v, want %v", got, tt.wantAllowed)
+			}
+			if got := response.rejectReason(); got != tt.wantReason {
+				t.Errorf("rejectReason = %q, want %q", got, tt.wantReason)
+			}
+			if got := response.hasPatch(); got != tt.wantPatch {
+				t.Errorf("hasPatch = %v, want %v", got, tt.wantPatch)
+			}
+		})
+	}
+}
+
+func TestRejectReasonIsTruncated(t *testing.T) {
+	response := preHookResponse{Reason: strings.Repeat("x", maxRejectReasonLen+10)}
+	if got := len(response.rejectReason()); got != maxRejectReasonLen {
+		t.Fatalf("len(rejectReason) = %d, want %d", got, maxRejectReasonLen)
+	}
+}
+
+type testPatchPayload struct {
+	Title    string   `json:"title"`
+	Episodes int      `json:"episodes"`
+	Tags     []string `json:"tags"`
+}
+
+func TestApplyPatch(t *testing.T) {
+	original := testPatchPayload{Title: "Frieren", Episodes: 28, Tags: []string{"fantasy"}}
+
+	tests := []struct {
+		name        string
+		patch       string
+		wantApplied bool
+		wantErr     bool
+		want        testPatchPayload
+	}{
+		{
+			name:        "patches the listed fields only",
+			patch:       `{"title":"Frieren: Beyond Journey's End"}`,
+			wantApplied: true,
+			want:        testPatchPayload{Title: "Frieren: Beyond Journey's End", Episodes: 28, Tags: []string{"fantasy"}},
+		},
+		{
+			name:        "replaces slices",
+			patch:       `{"tags":["adventure","drama"]}`,
+			wantApplied: true,
+			want:        testPatchPayload{Title: "Frieren", Episodes: 28, Tags: []string{"adventure", "drama"}},
+		},
+		{
+			name:        "unknown fields are ignored",
+			patch:       `{"studio":"Madhouse"}`,
+			wantApplied: true,
+			want:        original,
+		},
+		{
+			name:    "field of the wrong type",
+			patch:   `{"title":"Changed","episodes":"twenty-eight"}`,
+			wantErr: true,
+			want:    original,
+		},
+		{
+			name:    "not an object",
+			patch:   `["Changed"]`,
+			wantErr: true,
+			want:    original,
+		},
+		{
+			name:    "malformed json",
+			patch:   `{"title":`,
+			wantErr: true,
+			want:    original,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := original
+			payload.Tags = append([]string(nil), original.Tags...)
+
+			applied, err := applyPatch(&payload, json.RawMessage(tt.patch))
+			if tt.wantErr && err == nil {
+				t.Fatal("applyPatch succeeded, want error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("applyPatch: %v", err)
+			}
+			if applied != tt.wantApplied {
+				t.Errorf("applied = %v, want %v", applied, tt.wantApplied)
+			}
+			if !reflect.DeepEqual(payload, tt.want) {
+				t.Errorf("payload = %+v, want %+v", payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPatchIgnoresNonPointers(t *testing.T) {
+	var nilPayload *testPatchPayload
+	tests := []struct {
+		name    string
+		payload any
+	}{
+		{name: "value", payload: testPatchPayload{Title: "Frieren"}},
+		{name: "nil pointer", payload: nilPayload},
+		{name: "nil", payload: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied, err := applyPatch(tt.payload, json.RawMessage(`{"title":"Changed"}`))
+			if err != nil || applied {
+				t.Fatalf("applyPatch = %v, %v, want false, nil", applied, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package hooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signHookRequest sets X-Hook-Timestamp, X-Hook-Delivery-Id and, when the hook has
+// secrets, X-Hook-Signature. The signature header holds one "sha256=<hex>" entry per
+// secret, comma separated, each the HMAC-SHA256 of "<timestamp>.<body>"; a receiver
+// accepts the request when any entry matches a secret it knows.
+func signHookRequest(req *http.Request, deliveryID string, body []byte, secrets []string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("X-Hook-Timestamp", timestamp)
+	req.Header.Set("X-Hook-Delivery-Id", deliveryID)
+	if len(secrets) == 0 {
+		return
+	}
+
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, "sha256="+hookSignature(secret, timestamp, body))
+	}
+	req.Header.Set("X-Hook-Signature", strings.Join(signatures, ","))
+}
+
+func hookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSigningSecret returns a new random secret for signing hook requests.
+func GenerateSigningSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// newDeliveryID returns a random UUID for pre hooks, which have no hook_deliveries row.
+func newDeliveryID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	buf[6] = buf[6]&0x0f | 0x40
+	buf[8] = buf[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16]), nil
+}
@@ -0,0 +1,131 @@
+package hooks
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+)
+
+const (
+	testBody              = `{"event":"show.create.post"}`
+	testTimestamp         = "1700000000"
+	testCurrentSignature  = "b3caabe4cbf30dc7f6a9b04147e02895cb2ee9fb2991a7934b8c70db630c8459"
+	testPreviousSignature = "cbb688cfe0f8624c0371cfd8c0056d3c95b84b5a80684feed2f6c9efef8b6b6e"
+)
+
+func TestHookSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{name: "current secret", secret: "whsec_current", timestamp: testTimestamp, body: testBody, want: testCurrentSignature},
+		{name: "previous secret", secret: "whsec_previous", timestamp: testTimestamp, body: testBody, want: testPreviousSignature},
+		{name: "empty body", secret: "whsec_current", timestamp: testTimestamp, body: "", want: "e34f13cf9a0598678d39619fd36d912bb109ba8b21f6578647a0e1b9b5b80d5d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hookSignature(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Fatalf("hookSignature = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if hookSignature("whsec_current", "1700000001", []byte(testBody)) == testCurrentSignature {
+		t.Fatal("the timestamp must be part of the signature")
+	}
+}
+
+func TestSignHookRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		secrets   []string
+		signature string
+	}{
+		{name: "unsigned without secrets", secrets: nil, signature: ""},
+		{name: "current secret", secrets: []string{"whsec_current"}, signature: "sha256=" + testCurrentSignature},
+		{
+			name:      "current and previous secret",
+			secrets:   []string{"whsec_current", "whsec_previous"},
+			signature: "sha256=" + testCurrentSignature + ",sha256=" + testPreviousSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://hooks.test", strings.NewReader(testBody))
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			signHookRequest(req, "delivery-1", []byte(testBody), tt.secrets, time.Unix(1700000000, 0))
+
+			if got := req.Header.Get("X-Hook-Timestamp"); got != testTimestamp {
+				t.Errorf("X-Hook-Timestamp = %q, want %q", got, testTimestamp)
+			}
+			if got := req.Header.Get("X-Hook-Delivery-Id"); got != "delivery-1" {
+				t.Errorf("X-Hook-Delivery-Id = %q, want %q", got, "delivery-1")
+			}
+			if got := req.Header.Get("X-Hook-Signature"); got != tt.signature {
+				t.Errorf("X-Hook-Signature = %q, want %q", got, tt.signature)
+			}
+		})
+	}
+}
+
+func TestSubscriptionTargetSecretRotation(t *testing.T) {
+	cipher, err := cryptox.NewCipher("test-key")
+	if err != nil {
+		t.Fatalf("new cipher: %v", err)
+	}
+	current := mustEncrypt(t, cipher, "whsec_current")
+	previous := mustEncrypt(t, cipher, "whsec_previous")
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		previous  string
+		expiresAt *time.Time
+		want      []string
+	}{
+		{name: "no previous secret", want: []string{"whsec_current"}},
+		{name: "inside the grace window", previous: previous, expiresAt: &later, want: []string{"whsec_current", "whsec_previous"}},
+		{name: "grace window over", previous: previous, expiresAt: &earlier, want: []string{"whsec_current"}},
+		{name: "grace window ends now", previous: previous, expiresAt: &now, want: []string{"whsec_current"}},
+		{name: "previous secret without expiry", previous: previous, want: []string{"whsec_current"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := subscriptionTarget(cipher, sqlc.HookSubscription{
+				TargetUrl:                      "http://hooks.test",
+				Headers:                        []byte(`{}`),
+				TimeoutMs:                      5000,
+				SigningSecret:                  current,
+				PreviousSigningSecret:          tt.previous,
+				PreviousSigningSecretExpiresAt: tt.expiresAt,
+			}, now)
+			if err != nil {
+				t.Fatalf("subscriptionTarget: %v", err)
+			}
+			if got := strings.Join(target.Secrets, ","); got != strings.Join(tt.want, ",") {
+				t.Fatalf("Secrets = %q, want %q", target.Secrets, tt.want)
+			}
+		})
+	}
+}
+
+func mustEncrypt(t *testing.T, cipher *cryptox.Cipher, plain string) string {
+	t.Helper()
+	encrypted, err := cipher.Encrypt(plain)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	return encrypted
+}
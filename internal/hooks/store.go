@@ -2,20 +2,16 @@ package hooks
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 )
 
-type Config struct {
-	Event     Event     `json:"event"`
-	URL       string    `json:"url"`
-	UpdatedAt time.Time `json:"updatedAt"`
-}
-
 var allEvents = []Event{
 	EventShowCreatePre,
 	EventShowCreatePost,
@@ -29,6 +25,8 @@ var allEvents = []Event{
 	EventEpisodeUpdatePost,
 	EventEpisodeDeletePre,
 	EventEpisodeDeletePost,
+	EventEpisodeBulkUpsertPre,
+	EventEpisodeBulkUpsertPost,
 }
 
 func AllEvents() []Event {
@@ -46,72 +44,100 @@ func IsValidEvent(event Event) bool {
 	return false
 }
 
-func EnsureStore(ctx context.Context, pool *pgxpool.Pool) error {
-	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+// matchingSubscriptions returns the enabled subscriptions of event whose filter accepts
+// payload, the JSON the receivers will get, in creation order.
+func matchingSubscriptions(ctx context.Context, q *sqlc.Queries, event Event, payload []byte) ([]sqlc.HookSubscription, error) {
+	subscriptions, err := q.ListEnabledHookSubscriptionsByEvent(ctx, string(event))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() { _ = tx.Rollback(ctx) }()
 
-	for _, event := range allEvents {
-		if _, err := tx.Exec(ctx, `
-INSERT INTO hook_settings (event_name, target_url)
-VALUES ($1, '')
-ON CONFLICT (event_name) DO NOTHING
-`, string(event)); err != nil {
-			return err
+	var fields map[string]string
+	out := make([]sqlc.HookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		var filter Filter
+		if err := json.Unmarshal(subscription.Filter, &filter); err != nil {
+			return nil, fmt.Errorf("decode filter of hook subscription %s: %w", subscription.InternalHookSubscriptionID, err)
+		}
+		if len(filter) > 0 && fields == nil {
+			fields = payloadFields(payload)
+		}
+		if filter.Matches(fields) {
+			out = append(out, subscription)
 		}
 	}
+	return out, nil
+}
 
-	return tx.Commit(ctx)
+// Matches reports whether every filter key names a payload field holding one of the
+// key's values. fields comes from payloadFields; an empty filter matches everything.
+func (f Filter) Matches(fields map[string]string) bool {
+	for key, values := range f {
+		value, ok := fields[strings.ToLower(key)]
+		if !ok {
+			return false
+		}
+		matched := false
+		for _, candidate := range values {
+			if candidate == value {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
 }
 
-func ListConfigs(ctx context.Context, pool *pgxpool.Pool) ([]Config, error) {
-	rows, err := pool.Query(ctx, `
-SELECT event_name, target_url, updated_at
-FROM hook_settings
-ORDER BY event_name ASC
-`)
-	if err != nil {
-		return nil, err
+// payloadFields maps the lowercased names of a JSON object's top-level string, number
+// and boolean fields to their values as text. Other fields, and non-object payloads,
+// give nothing a filter can match.
+func payloadFields(payload []byte) map[string]string {
+	var object map[string]any
+	if err := json.Unmarshal(payload, &object); err != nil {
+		return map[string]string{}
 	}
-	defer rows.Close()
 
-	out := make([]Config, 0, len(allEvents))
-	for rows.Next() {
-		var item Config
-		var eventName string
-		if err := rows.Scan(&eventName, &item.URL, &item.UpdatedAt); err != nil {
-			return nil, err
+	fields := make(map[string]string, len(object))
+	for key, raw := range object {
+		switch value := raw.(type) {
+		case string:
+			fields[strings.ToLower(key)] = value
+		case float64:
+			fields[strings.ToLower(key)] = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			fields[strings.ToLower(key)] = strconv.FormatBool(value)
 		}
-		item.Event = Event(eventName)
-		out = append(out, item)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
 	}
-	return out, nil
+	return fields
 }
 
-func UpsertConfig(ctx context.Context, pool *pgxpool.Pool, event Event, rawURL string) (Config, error) {
-	if !IsValidEvent(event) {
-		return Config{}, fmt.Errorf("invalid hook event %q", event)
+// subscriptionTarget decrypts what a request to subscription needs: its current secret,
+// then the previous one while its grace window lasts.
+func subscriptionTarget(cipher *cryptox.Cipher, subscription sqlc.HookSubscription, now time.Time) (hookTarget, error) {
+	target := hookTarget{
+		URL:     subscription.TargetUrl,
+		Timeout: time.Duration(subscription.TimeoutMs) * time.Millisecond,
 	}
-
-	url := strings.TrimSpace(rawURL)
-	var updated Config
-	var eventName string
-	err := pool.QueryRow(ctx, `
-INSERT INTO hook_settings (event_name, target_url, updated_at)
-VALUES ($1, $2, now())
-ON CONFLICT (event_name)
-DO UPDATE SET target_url = EXCLUDED.target_url, updated_at = now()
-RETURNING event_name, target_url, updated_at
-`, string(event), url).Scan(&eventName, &updated.URL, &updated.UpdatedAt)
-	if err != nil {
-		return Config{}, err
+	if err := json.Unmarshal(subscription.Headers, &target.Headers); err != nil {
+		return hookTarget{}, fmt.Errorf("decode headers of hook subscription %s: %w", subscription.InternalHookSubscriptionID, err)
 	}
 
-	updated.Event = Event(eventName)
-	return updated, nil
+	encrypted := []string{subscription.SigningSecret}
+	if expiresAt := subscription.PreviousSigningSecretExpiresAt; expiresAt != nil && expiresAt.After(now) {
+		encrypted = append(encrypted, subscription.PreviousSigningSecret)
+	}
+	for _, value := range encrypted {
+		if value == "" {
+			continue
+		}
+		secret, err := cipher.Decrypt(value)
+		if err != nil {
+			return hookTarget{}, fmt.Errorf("decrypt signing secret of hook subscription %s: %w", subscription.InternalHookSubscriptionID, err)
+		}
+		target.Secrets = append(target.Secrets, secret)
+	}
+	return target, nil
 }
@@ -0,0 +1,59 @@
+package hooks
+
+import "testing"
+
+func TestFilterMatches(t *testing.T) {
+	payload := []byte(`{"type":"tv","status":"airing","seasonCount":2,"adult":false,"titles":["Frieren"],"meta":{"type":"movie"}}`)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "empty filter", filter: Filter{}, want: true},
+		{name: "nil filter", filter: nil, want: true},
+		{name: "string field", filter: Filter{"type": {"tv"}}, want: true},
+		{name: "one of several values", filter: Filter{"type": {"movie", "tv"}}, want: true},
+		{name: "value not listed", filter: Filter{"type": {"movie"}}, want: false},
+		{name: "key case is ignored", filter: Filter{"SeasonCount": {"2"}}, want: true},
+		{name: "value case matters", filter: Filter{"type": {"TV"}}, want: false},
+		{name: "boolean field", filter: Filter{"adult": {"false"}}, want: true},
+		{name: "every key must match", filter: Filter{"type": {"tv"}, "status": {"finished"}}, want: false},
+		{name: "missing field", filter: Filter{"format": {"tv"}}, want: false},
+		{name: "array field", filter: Filter{"titles": {"Frieren"}}, want: false},
+		{name: "nested field", filter: Filter{"meta": {"movie"}}, want: false},
+		{name: "no values", filter: Filter{"type": {}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(payloadFields(payload)); got != tt.want {
+				t.Fatalf("Matches(%v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadFieldsOfNonObjects(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "array", payload: `[{"type":"tv"}]`},
+		{name: "string", payload: `"tv"`},
+		{name: "invalid json", payload: `{"type":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := payloadFields([]byte(tt.payload))
+			if len(fields) != 0 {
+				t.Fatalf("payloadFields = %v, want none", fields)
+			}
+			if (Filter{"type": {"tv"}}).Matches(fields) {
+				t.Fatal("a filter must not match a payload without fields")
+			}
+			if !(Filter{}).Matches(fields) {
+				t.Fatal("an empty filter must match every payload")
+			}
+		})
+	}
+}
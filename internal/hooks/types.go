@@ -0,0 +1,103 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+)
+
+const (
+	DeliveryStatusPending    = "pending"
+	DeliveryStatusProcessing = "processing"
+	DeliveryStatusDelivered  = "delivered"
+	DeliveryStatusDead       = "dead"
+)
+
+// A failing pre-event receiver blocks the change under FailureModeClosed and is skipped
+// under FailureModeOpen.
+const (
+	FailureModeOpen   = "open"
+	FailureModeClosed = "closed"
+)
+
+const (
+	maxHookResponseBytes = 1 << 20
+	maxRejectReasonLen   = 500
+	defaultRejectReason  = "rejected by hook"
+)
+
+const (
+	defaultDeliveryPollInterval = 5 * time.Second
+	defaultDeliveryMaxAttempts  = 10
+	deliveryStaleAfter          = 5 * time.Minute
+	baseDeliveryRetryDelay      = 10 * time.Second
+	maxDeliveryRetryDelay       = time.Hour
+	maxDeliveryErrorLen         = 1000
+)
+
+// errSubscriptionRemoved fails a delivery whose subscription was deleted, or that was
+// queued for a hook with no URL before subscriptions existed. It is not retried.
+var errSubscriptionRemoved = errors.New("subscription removed")
+
+// errSubscriptionDisabled fails a delivery whose subscription was disabled after it was
+// queued. It is not retried; redeliver it once the subscription is enabled again.
+var errSubscriptionDisabled = errors.New("subscription disabled")
+
+type DeliveryWorkerOptions struct {
+	PollInterval time.Duration
+	// MaxAttempts is how many sends a delivery gets before it is marked dead.
+	MaxAttempts int
+}
+
+// DeliveryWorker sends queued post-event hooks from the hook_deliveries table.
+type DeliveryWorker struct {
+	q      *sqlc.Queries
+	cipher *cryptox.Cipher
+	client *http.Client
+	id     string
+	opts   DeliveryWorkerOptions
+}
+
+// Filter limits a subscription to events whose payload has, for every key, a top-level
+// field of that name (compared case-insensitively) holding one of the listed values, e.g.
+// {"type": ["anime"]} or {"internalShowId": ["3cb5e44c-..."]}.
+type Filter map[string][]string
+
+type hookTarget struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+	Secrets []string
+}
+
+type hookResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// preHookResponse is the optional JSON answer of a pre-event receiver. Allow defaults to
+// true. Payload, when set, is decoded over the payload the receiver was sent: the fields
+// it has replace the request's and the ones it omits are kept.
+type preHookResponse struct {
+	Allow   *bool           `json:"allow"`
+	Reason  string          `json:"reason"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// RejectedError reports a pre-event receiver that answered {"allow": false}.
+type RejectedError struct {
+	Event  Event
+	Reason string
+}
+
+// FailedError reports a fail-closed pre-event receiver that could not be called, answered
+// with a non-2xx status or an unreadable body, or returned a payload that does not fit.
+type FailedError struct {
+	Event Event
+	Err   error
+}
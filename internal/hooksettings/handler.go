@@ -1,65 +1,331 @@
 package hooksettings
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/httperr"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
 )
 
+// ListHookKeys godoc
+//
+//	@Summary		List hook keys
+//	@Description	View all predefined hook event keys
+//	@Tags			settings
+//	@Produce		json
+//	@Success		200	{array}	string
+//	@Router			/settings/hooks/keys [get]
+func (h *Handler) ListHookKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, h.svc.ListKeys())
+}
+
 // ListHooks godoc
 //
 //	@Summary		List hook settings
-//	@Description	View predefined hooks and configured target URLs
+//	@Description	View the first subscription of every configured event in the one-URL-per-event shape used before subscriptions. Kept for compatibility; use /settings/hooks/subscriptions.
 //	@Tags			settings
 //	@Produce		json
-//	@Success		200	{array}	hooks.Config
+//	@Success		200	{array}		HookConfigResponse
 //	@Failure		500	{object}	httperr.APIErrorResponse
 //	@Router			/settings/hooks [get]
 func (h *Handler) ListHooks(c *gin.Context) {
-	items, err := h.svc.List(c.Request.Context())
+	items, err := h.svc.ListHookConfigs(c.Request.Context())
 	if err != nil {
 		httperr.Abort(c, httperr.Internal("failed to list hook settings").WithCause(err))
 		return
 	}
-	c.JSON(http.StatusOK, items)
-}
-
-// ListHookKeys godoc
-//
-//	@Summary		List hook keys
-//	@Description	View all predefined hook event keys
-//	@Tags			settings
-//	@Produce		json
-//	@Success		200	{array}	string
-//	@Router			/settings/hooks/keys [get]
-func (h *Handler) ListHookKeys(c *gin.Context) {
-	c.JSON(http.StatusOK, h.svc.ListKeys())
+	c.JSON(http.StatusOK, toHookConfigResponses(items))
 }
 
 // UpsertHooks godoc
 //
 //	@Summary		Upsert hook settings
-//	@Description	Upsert target URL by predefined hook event name
+//	@Description	Set the target URL of each event's first subscription, creating it when the event has none. An empty URL disables it. Kept for compatibility; use /settings/hooks/subscriptions.
 //	@Tags			settings
 //	@Accept			json
 //	@Produce		json
 //	@Param			payload	body		upsertHooksRequest	true	"Hooks settings payload"
-//	@Success		200		{array}		hooks.Config
+//	@Success		200		{array}		HookConfigResponse
 //	@Failure		400		{object}	httperr.APIErrorResponse
 //	@Failure		500		{object}	httperr.APIErrorResponse
 //	@Router			/settings/hooks [put]
 func (h *Handler) UpsertHooks(c *gin.Context) {
-	var req upsertHooksRequest
-	if httpx.AbortIfErr(c, c.ShouldBindJSON(&req)) {
+	req, ok := httpx.AbortIfMissingContext[upsertHooksRequest](c, ctxUpsertHooksRequestKey)
+	if !ok {
 		return
 	}
 
-	items, err := h.svc.Upsert(c.Request.Context(), req)
+	items, err := h.svc.UpsertHookConfigs(c.Request.Context(), req)
 	if err != nil {
-		httperr.Abort(c, httperr.BadRequest(err.Error()))
+		httperr.Abort(c, httperr.Internal("failed to upsert hook settings").WithCause(err))
 		return
 	}
 	c.JSON(http.StatusOK, items)
 }
+
+// ListHookSubscriptions godoc
+//
+//	@Summary		List hook subscriptions
+//	@Description	List hook subscriptions by event, oldest first within an event. Signing secrets are never returned.
+//	@Tags			settings
+//	@Produce		json
+//	@Param			event	query		string	false	"Hook event name"
+//	@Success		200		{array}		SubscriptionResponse
+//	@Failure		400		{object}	httperr.APIErrorResponse
+//	@Failure		500		{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/subscriptions [get]
+func (h *Handler) ListHookSubscriptions(c *gin.Context) {
+	event, ok := httpx.AbortIfMissingContext[*string](c, ctxSubscriptionEventKey)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListSubscriptions(c.Request.Context(), event)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to list hook subscriptions").WithCause(err))
+		return
+	}
+	response, err := toSubscriptionResponses(items)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to list hook subscriptions").WithCause(err))
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateHookSubscription godoc
+//
+//	@Summary		Create hook subscription
+//	@Description	Subscribe a target URL to a hook event. The generated signing secret is returned only in this response.
+//	@Tags			settings
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		subscriptionRequest	true	"Hook subscription payload"
+//	@Success		201		{object}	CreateSubscriptionResponse
+//	@Failure		400		{object}	httperr.APIErrorResponse
+//	@Failure		500		{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/subscriptions [post]
+func (h *Handler) CreateHookSubscription(c *gin.Context) {
+	req, ok := httpx.AbortIfMissingContext[subscriptionRequest](c, ctxSubscriptionRequestKey)
+	if !ok {
+		return
+	}
+
+	item, secret, err := h.svc.CreateSubscription(c.Request.Context(), req)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to create hook subscription").WithCause(err))
+		return
+	}
+	response, err := toSubscriptionResponse(item)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to create hook subscription").WithCause(err))
+		return
+	}
+	c.JSON(http.StatusCreated, CreateSubscriptionResponse{SubscriptionResponse: response, Secret: secret})
+}
+
+// GetHookSubscription godoc
+//
+//	@Summary		Get hook subscription
+//	@Description	Get one hook subscription
+//	@Tags			settings
+//	@Produce		json
+//	@Param			internalHookSubscriptionId	path		string	true	"Internal hook subscription UUID"
+//	@Success		200							{object}	SubscriptionResponse
+//	@Failure		400							{object}	httperr.APIErrorResponse
+//	@Failure		404							{object}	httperr.APIErrorResponse
+//	@Failure		500							{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/subscriptions/{internalHookSubscriptionId} [get]
+func (h *Handler) GetHookSubscription(c *gin.Context) {
+	subscriptionID, ok := httpx.AbortIfMissingContext[string](c, ctxSubscriptionIDKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.GetSubscription(c.Request.Context(), subscriptionID)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "hook subscription not found", "failed to get hook subscription") {
+		return
+	}
+	h.respondSubscription(c, item, "failed to get hook subscription")
+}
+
+// UpdateHookSubscription godoc
+//
+//	@Summary		Update hook subscription
+//	@Description	Replace the event, URL, enabled state, headers, timeout and filter of a hook subscription. Deliveries already queued are still sent after it is disabled.
+//	@Tags			settings
+//	@Accept			json
+//	@Produce		json
+//	@Param			internalHookSubscriptionId	path		string				true	"Internal hook subscription UUID"
+//	@Param			payload						body		subscriptionRequest	true	"Hook subscription payload"
+//	@Success		200							{object}	SubscriptionResponse
+//	@Failure		400							{object}	httperr.APIErrorResponse
+//	@Failure		404							{object}	httperr.APIErrorResponse
+//	@Failure		500							{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/subscriptions/{internalHookSubscriptionId} [put]
+func (h *Handler) UpdateHookSubscription(c *gin.Context) {
+	subscriptionID, ok := httpx.AbortIfMissingContext[string](c, ctxSubscriptionIDKey)
+	if !ok {
+		return
+	}
+	req, ok := httpx.AbortIfMissingContext[subscriptionRequest](c, ctxSubscriptionRequestKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.UpdateSubscription(c.Request.Context(), subscriptionID, req)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "hook subscription not found", "failed to update hook subscription") {
+		return
+	}
+	h.respondSubscription(c, item, "failed to update hook subscription")
+}
+
+// DeleteHookSubscription godoc
+//
+//	@Summary		Delete hook subscription
+//	@Description	Delete a hook subscription. Its deliveries are kept with no subscription; queued ones are marked dead.
+//	@Tags			settings
+//	@Param			internalHookSubscriptionId	path	string	true	"Internal hook subscription UUID"
+//	@Success		204
+//	@Failure		400	{object}	httperr.APIErrorResponse
+//	@Failure		404	{object}	httperr.APIErrorResponse
+//	@Failure		500	{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/subscriptions/{internalHookSubscriptionId} [delete]
+func (h *Handler) DeleteHookSubscription(c *gin.Context) {
+	subscriptionID, ok := httpx.AbortIfMissingContext[string](c, ctxSubscriptionIDKey)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeleteSubscription(c.Request.Context(), subscriptionID)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "hook subscription not found", "failed to delete hook subscription") {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RotateHookSubscriptionSecret godoc
+//
+//	@Summary		Rotate hook subscription signing secret
+//	@Description	Generate a new signing secret for a hook subscription and return it once. The previous secret keeps signing requests until the grace window ends.
+//	@Tags			settings
+//	@Produce		json
+//	@Param			internalHookSubscriptionId	path		string	true	"Internal hook subscription UUID"
+//	@Param			grace						query		string	false	"How long the previous secret stays valid, e.g. 24h (default 24h, max 168h, 0 to revoke at once)"
+//	@Success		200							{object}	SecretRotationResponse
+//	@Failure		400							{object}	httperr.APIErrorResponse
+//	@Failure		404							{object}	httperr.APIErrorResponse
+//	@Failure		500							{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/subscriptions/{internalHookSubscriptionId}/rotate-secret [post]
+func (h *Handler) RotateHookSubscriptionSecret(c *gin.Context) {
+	subscriptionID, ok := httpx.AbortIfMissingContext[string](c, ctxSubscriptionIDKey)
+	if !ok {
+		return
+	}
+	grace, ok := httpx.AbortIfMissingContext[time.Duration](c, ctxSecretGraceKey)
+	if !ok {
+		return
+	}
+
+	rotation, err := h.svc.RotateSecret(c.Request.Context(), subscriptionID, grace)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "hook subscription not found", "failed to rotate hook secret") {
+		return
+	}
+	c.JSON(http.StatusOK, rotation)
+}
+
+func (h *Handler) respondSubscription(c *gin.Context, item sqlc.HookSubscription, internalMsg string) {
+	response, err := toSubscriptionResponse(item)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal(internalMsg).WithCause(err))
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListHookDeliveries godoc
+//
+//	@Summary		List hook deliveries
+//	@Description	List queued post-event hook deliveries, newest first, optionally filtered by status, event and subscription
+//	@Tags			settings
+//	@Produce		json
+//	@Param			status			query		string	false	"Delivery status: pending|processing|delivered|dead"
+//	@Param			event			query		string	false	"Hook event name"
+//	@Param			subscriptionId	query		string	false	"Internal hook subscription UUID"
+//	@Param			limit			query		int		false	"Limit (default 50, max 200)"
+//	@Success		200				{array}		DeliveryResponse
+//	@Failure		400				{object}	httperr.APIErrorResponse
+//	@Failure		500				{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/deliveries [get]
+func (h *Handler) ListHookDeliveries(c *gin.Context) {
+	opts, ok := httpx.AbortIfMissingContext[DeliveryListOpts](c, ctxDeliveryListOptsKey)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListDeliveries(c.Request.Context(), opts)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to list hook deliveries").WithCause(err))
+		return
+	}
+	c.JSON(http.StatusOK, toDeliveryResponses(items))
+}
+
+// GetHookDelivery godoc
+//
+//	@Summary		Get hook delivery
+//	@Description	Get one post-event hook delivery, including its payload and last error
+//	@Tags			settings
+//	@Produce		json
+//	@Param			internalHookDeliveryId	path		string	true	"Internal hook delivery UUID"
+//	@Success		200						{object}	DeliveryResponse
+//	@Failure		400						{object}	httperr.APIErrorResponse
+//	@Failure		404						{object}	httperr.APIErrorResponse
+//	@Failure		500						{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/deliveries/{internalHookDeliveryId} [get]
+func (h *Handler) GetHookDelivery(c *gin.Context) {
+	deliveryID, ok := httpx.AbortIfMissingContext[string](c, ctxDeliveryIDKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.GetDelivery(c.Request.Context(), deliveryID)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "hook delivery not found", "failed to get hook delivery") {
+		return
+	}
+	c.JSON(http.StatusOK, toDeliveryResponse(item))
+}
+
+// RedeliverHookDelivery godoc
+//
+//	@Summary		Redeliver hook delivery
+//	@Description	Queue a delivered or dead hook delivery to be sent again now, with a fresh attempt budget
+//	@Tags			settings
+//	@Produce		json
+//	@Param			internalHookDeliveryId	path		string	true	"Internal hook delivery UUID"
+//	@Success		202						{object}	DeliveryResponse
+//	@Failure		400						{object}	httperr.APIErrorResponse
+//	@Failure		404						{object}	httperr.APIErrorResponse
+//	@Failure		409						{object}	httperr.APIErrorResponse
+//	@Failure		500						{object}	httperr.APIErrorResponse
+//	@Router			/settings/hooks/deliveries/{internalHookDeliveryId}/redeliver [post]
+func (h *Handler) RedeliverHookDelivery(c *gin.Context) {
+	deliveryID, ok := httpx.AbortIfMissingContext[string](c, ctxDeliveryIDKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.Redeliver(c.Request.Context(), deliveryID)
+	if errors.Is(err, errDeliveryQueued) {
+		httperr.Abort(c, httperr.Conflict(err.Error()))
+		return
+	}
+	if httpx.AbortDBErrNotFoundMsg(c, err, "hook delivery not found", "failed to redeliver hook delivery") {
+		return
+	}
+	c.JSON(http.StatusAccepted, toDeliveryResponse(item))
+}
@@ -0,0 +1,104 @@
+package hooksettings
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/hooks"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+)
+
+func (h *Handler) BindDeliveryID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deliveryID := c.Param("internalHookDeliveryId")
+		if httpx.AbortIfErr(c, validateDeliveryID(deliveryID)) {
+			return
+		}
+		c.Set(ctxDeliveryIDKey, deliveryID)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindDeliveryListOpts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := DeliveryListOpts{
+			Status:         normalizeutil.StringValuePtr(normalizeutil.LowerString(c.Query("status"))),
+			Event:          normalizeutil.StringValuePtr(normalizeutil.LowerString(c.Query("event"))),
+			SubscriptionID: normalizeutil.StringValuePtr(normalizeutil.LowerString(c.Query("subscriptionId"))),
+			Limit:          normalizeutil.Limit(httpx.ParsePositiveInt(c.Query("limit"), defaultDeliveryListLimit), defaultDeliveryListLimit, maxDeliveryListLimit),
+		}
+		if httpx.AbortIfErr(c, validateDeliveryListOpts(opts)) {
+			return
+		}
+		c.Set(ctxDeliveryListOptsKey, opts)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindSubscriptionID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptionID := c.Param("internalHookSubscriptionId")
+		if httpx.AbortIfErr(c, validateSubscriptionID(subscriptionID)) {
+			return
+		}
+		c.Set(ctxSubscriptionIDKey, subscriptionID)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindSubscriptionEvent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event := normalizeutil.StringValuePtr(normalizeutil.LowerString(c.Query("event")))
+		if event != nil {
+			if httpx.AbortIfErr(c, validateEvent(hooks.Event(*event))) {
+				return
+			}
+		}
+		c.Set(ctxSubscriptionEventKey, event)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindSubscriptionRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req subscriptionRequest
+		if httpx.AbortIfErr(c, c.ShouldBindJSON(&req)) {
+			return
+		}
+
+		normalizeSubscriptionRequest(&req)
+		if httpx.AbortIfErr(c, validateSubscriptionRequest(req)) {
+			return
+		}
+
+		c.Set(ctxSubscriptionRequestKey, req)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindUpsertHooksRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req upsertHooksRequest
+		if httpx.AbortIfErr(c, c.ShouldBindJSON(&req)) {
+			return
+		}
+
+		normalizeUpsertHooksRequest(&req)
+		if httpx.AbortIfErr(c, validateUpsertHooksRequest(req)) {
+			return
+		}
+
+		c.Set(ctxUpsertHooksRequestKey, req)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindSecretGrace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		grace, err := parseSecretGrace(c.Query("grace"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		c.Set(ctxSecretGraceKey, grace)
+		c.Next()
+	}
+}
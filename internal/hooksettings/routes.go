@@ -4,6 +4,15 @@ import "github.com/gin-gonic/gin"
 
 func RegisterRoutes(r *gin.Engine, h *Handler) {
 	r.GET("/settings/hooks", h.ListHooks)
+	r.PUT("/settings/hooks", h.BindUpsertHooksRequest(), h.UpsertHooks)
 	r.GET("/settings/hooks/keys", h.ListHookKeys)
-	r.PUT("/settings/hooks", h.UpsertHooks)
+	r.GET("/settings/hooks/subscriptions", h.BindSubscriptionEvent(), h.ListHookSubscriptions)
+	r.POST("/settings/hooks/subscriptions", h.BindSubscriptionRequest(), h.CreateHookSubscription)
+	r.GET("/settings/hooks/subscriptions/:internalHookSubscriptionId", h.BindSubscriptionID(), h.GetHookSubscription)
+	r.PUT("/settings/hooks/subscriptions/:internalHookSubscriptionId", h.BindSubscriptionID(), h.BindSubscriptionRequest(), h.UpdateHookSubscription)
+	r.DELETE("/settings/hooks/subscriptions/:internalHookSubscriptionId", h.BindSubscriptionID(), h.DeleteHookSubscription)
+	r.POST("/settings/hooks/subscriptions/:internalHookSubscriptionId/rotate-secret", h.BindSubscriptionID(), h.BindSecretGrace(), h.RotateHookSubscriptionSecret)
+	r.GET("/settings/hooks/deliveries", h.BindDeliveryListOpts(), h.ListHookDeliveries)
+	r.GET("/settings/hooks/deliveries/:internalHookDeliveryId", h.BindDeliveryID(), h.GetHookDelivery)
+	r.POST("/settings/hooks/deliveries/:internalHookDeliveryId/redeliver", h.BindDeliveryID(), h.RedeliverHookDelivery)
 }
@@ -1,42 +1,202 @@
 package hooksettings
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/hooks"
 )
 
-func NewHandler(pool *pgxpool.Pool) (*Handler, error) {
-	if err := hooks.EnsureStore(context.Background(), pool); err != nil {
-		return nil, err
-	}
+// NewHandler returns the hook settings handler. cipher encrypts the signing secrets it
+// generates.
+func NewHandler(pool *pgxpool.Pool, cipher *cryptox.Cipher) *Handler {
 	return &Handler{
-		svc: &Service{pool: pool},
-	}, nil
-}
-
-func (s *Service) List(ctx context.Context) ([]hooks.Config, error) {
-	return hooks.ListConfigs(ctx, s.pool)
+		svc: &Service{q: sqlc.New(pool), cipher: cipher},
+	}
 }
 
 func (s *Service) ListKeys() []hooks.Event {
 	return hooks.AllEvents()
 }
 
-func (s *Service) Upsert(ctx context.Context, req upsertHooksRequest) ([]hooks.Config, error) {
-	if len(req.Hooks) == 0 {
-		return nil, errors.New("hooks payload is required")
+func (s *Service) ListSubscriptions(ctx context.Context, event *string) ([]sqlc.HookSubscription, error) {
+	return s.q.ListHookSubscriptions(ctx, event)
+}
+
+func (s *Service) GetSubscription(ctx context.Context, subscriptionID string) (sqlc.HookSubscription, error) {
+	return s.q.GetHookSubscriptionByID(ctx, subscriptionID)
+}
+
+// ListHookConfigs returns the first subscription of every event that has one.
+func (s *Service) ListHookConfigs(ctx context.Context) ([]sqlc.HookSubscription, error) {
+	items, err := s.q.ListHookSubscriptions(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
+	return firstSubscriptionPerEvent(items), nil
+}
 
-	updated := make([]hooks.Config, 0, len(req.Hooks))
-	for _, item := range req.Hooks {
-		config, err := hooks.UpsertConfig(ctx, s.pool, item.Event, item.URL)
+// UpsertHookConfigs sets the URL of each event's first subscription, creating one with
+// the default settings when the event has none. An empty URL disables the subscription
+// and keeps its URL; it creates nothing.
+func (s *Service) UpsertHookConfigs(ctx context.Context, req upsertHooksRequest) ([]HookConfigResponse, error) {
+	items, err := s.q.ListHookSubscriptions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	first := make(map[hooks.Event]sqlc.HookSubscription)
+	for _, item := range firstSubscriptionPerEvent(items) {
+		first[hooks.Event(item.EventName)] = item
+	}
+
+	out := make([]HookConfigResponse, 0, len(req.Hooks))
+	for _, hook := range req.Hooks {
+		current, ok := first[hook.Event]
+		var item sqlc.HookSubscription
+		switch {
+		case !ok && hook.URL == "":
+			out = append(out, HookConfigResponse{Event: hook.Event})
+			continue
+		case !ok:
+			item, _, err = s.CreateSubscription(ctx, newHookConfigSubscription(hook))
+		default:
+			item, err = s.q.UpdateHookSubscription(ctx, sqlc.UpdateHookSubscriptionParams{
+				EventName:                  current.EventName,
+				TargetUrl:                  cmp.Or(hook.URL, current.TargetUrl),
+				Enabled:                    hook.URL != "",
+				Headers:                    current.Headers,
+				TimeoutMs:                  current.TimeoutMs,
+				Filter:                     current.Filter,
+				FailureMode:                current.FailureMode,
+				InternalHookSubscriptionID: current.InternalHookSubscriptionID,
+			})
+		}
 		if err != nil {
 			return nil, err
 		}
-		updated = append(updated, config)
+		first[hook.Event] = item
+		out = append(out, toHookConfigResponse(item))
+	}
+	return out, nil
+}
+
+// CreateSubscription stores the subscription with a new signing secret and returns the
+// secret in plain text.
+func (s *Service) CreateSubscription(ctx context.Context, req subscriptionRequest) (sqlc.HookSubscription, string, error) {
+	headers, filter, err := encodeSubscriptionSettings(req)
+	if err != nil {
+		return sqlc.HookSubscription{}, "", err
+	}
+	secret, encrypted, err := s.newSigningSecret()
+	if err != nil {
+		return sqlc.HookSubscription{}, "", err
+	}
+
+	item, err := s.q.CreateHookSubscription(ctx, sqlc.CreateHookSubscriptionParams{
+		EventName:     string(req.Event),
+		TargetUrl:     req.URL,
+		Enabled:       *req.Enabled,
+		Headers:       headers,
+		TimeoutMs:     *req.TimeoutMs,
+		Filter:        filter,
+		FailureMode:   req.FailureMode,
+		SigningSecret: encrypted,
+	})
+	if err != nil {
+		return sqlc.HookSubscription{}, "", err
+	}
+	return item, secret, nil
+}
+
+// UpdateSubscription replaces every setting of the subscription but its signing secrets.
+// Deliveries already queued for it are still sent after it is disabled.
+func (s *Service) UpdateSubscription(ctx context.Context, subscriptionID string, req subscriptionRequest) (sqlc.HookSubscription, error) {
+	headers, filter, err := encodeSubscriptionSettings(req)
+	if err != nil {
+		return sqlc.HookSubscription{}, err
+	}
+	return s.q.UpdateHookSubscription(ctx, sqlc.UpdateHookSubscriptionParams{
+		EventName:                  string(req.Event),
+		TargetUrl:                  req.URL,
+		Enabled:                    *req.Enabled,
+		Headers:                    headers,
+		TimeoutMs:                  *req.TimeoutMs,
+		Filter:                     filter,
+		FailureMode:                req.FailureMode,
+		InternalHookSubscriptionID: subscriptionID,
+	})
+}
+
+// DeleteSubscription removes the subscription. Its deliveries are kept without it, and the
+// delivery worker marks the ones still queued as dead.
+func (s *Service) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	_, err := s.q.DeleteHookSubscription(ctx, subscriptionID)
+	return err
+}
+
+// RotateSecret gives the subscription a new signing secret and returns it in plain text.
+// The previous secret keeps signing requests for grace; a zero grace drops it at once.
+func (s *Service) RotateSecret(ctx context.Context, subscriptionID string, grace time.Duration) (SecretRotationResponse, error) {
+	secret, encrypted, err := s.newSigningSecret()
+	if err != nil {
+		return SecretRotationResponse{}, err
+	}
+
+	item, err := s.q.RotateHookSubscriptionSecret(ctx, sqlc.RotateHookSubscriptionSecretParams{
+		GraceSeconds:               int64(grace / time.Second),
+		SigningSecret:              encrypted,
+		InternalHookSubscriptionID: subscriptionID,
+	})
+	if err != nil {
+		return SecretRotationResponse{}, err
+	}
+	return SecretRotationResponse{
+		InternalHookSubscriptionID: item.InternalHookSubscriptionID,
+		Secret:                     secret,
+		PreviousSecretExpiresAt:    item.PreviousSigningSecretExpiresAt,
+	}, nil
+}
+
+func (s *Service) newSigningSecret() (string, string, error) {
+	secret, err := hooks.GenerateSigningSecret()
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err := s.cipher.Encrypt(secret)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, encrypted, nil
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, opts DeliveryListOpts) ([]sqlc.HookDelivery, error) {
+	return s.q.ListHookDeliveries(ctx, sqlc.ListHookDeliveriesParams{
+		Status:         opts.Status,
+		EventName:      opts.Event,
+		SubscriptionID: opts.SubscriptionID,
+		RowLimit:       int32(opts.Limit),
+	})
+}
+
+func (s *Service) GetDelivery(ctx context.Context, deliveryID string) (sqlc.HookDelivery, error) {
+	return s.q.GetHookDeliveryByID(ctx, deliveryID)
+}
+
+// Redeliver queues a delivered or dead delivery to be sent again now with a fresh attempt
+// budget. It returns errDeliveryQueued when the delivery is still pending or being sent.
+func (s *Service) Redeliver(ctx context.Context, deliveryID string) (sqlc.HookDelivery, error) {
+	item, err := s.q.RedeliverHookDelivery(ctx, deliveryID)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return item, err
+	}
+	if _, err := s.q.GetHookDeliveryByID(ctx, deliveryID); err != nil {
+		return sqlc.HookDelivery{}, err
 	}
-	return updated, nil
+	return sqlc.HookDelivery{}, errDeliveryQueued
 }
@@ -1,16 +1,67 @@
 package hooksettings
 
 import (
-	"github.com/jackc/pgx/v5/pgxpool"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/hooks"
 )
 
+const (
+	defaultDeliveryListLimit     = 50
+	maxDeliveryListLimit         = 200
+	defaultSecretGrace           = 24 * time.Hour
+	maxSecretGrace               = 7 * 24 * time.Hour
+	defaultSubscriptionTimeoutMs = 5000
+	minSubscriptionTimeoutMs     = 100
+	maxSubscriptionTimeoutMs     = 30000
+	maxSubscriptionHeaders       = 20
+	maxFilterKeys                = 10
+	maxFilterValues              = 100
+)
+
+const (
+	ctxDeliveryIDKey          = "hooksettings.delivery.id"
+	ctxDeliveryListOptsKey    = "hooksettings.delivery.list.opts"
+	ctxSubscriptionIDKey      = "hooksettings.subscription.id"
+	ctxSubscriptionEventKey   = "hooksettings.subscription.event"
+	ctxSubscriptionRequestKey = "hooksettings.subscription.request"
+	ctxSecretGraceKey         = "hooksettings.secret.grace"
+	ctxUpsertHooksRequestKey  = "hooksettings.hooks.upsert"
+)
+
+var errDeliveryQueued = errors.New("hook delivery is already queued")
+
 type Handler struct {
 	svc *Service
 }
 
 type Service struct {
-	pool *pgxpool.Pool
+	q      *sqlc.Queries
+	cipher *cryptox.Cipher
+}
+
+// subscriptionRequest creates a subscription or replaces all of its settings. Enabled
+// defaults to true, TimeoutMs to defaultSubscriptionTimeoutMs and FailureMode, which only
+// matters for pre events, to hooks.FailureModeClosed.
+type subscriptionRequest struct {
+	Event       hooks.Event       `json:"event"`
+	URL         string            `json:"url"`
+	Enabled     *bool             `json:"enabled"`
+	Headers     map[string]string `json:"headers"`
+	TimeoutMs   *int32            `json:"timeoutMs"`
+	Filter      hooks.Filter      `json:"filter"`
+	FailureMode string            `json:"failureMode"`
+}
+
+// upsertHooksRequest is the body of PUT /settings/hooks, the one-URL-per-event API from
+// before subscriptions. Each item sets the URL of the event's first subscription; an
+// empty URL disables it.
+type upsertHooksRequest struct {
+	Hooks []upsertHookItem `json:"hooks"`
 }
 
 type upsertHookItem struct {
@@ -18,6 +69,67 @@ type upsertHookItem struct {
 	URL   string      `json:"url"`
 }
 
-type upsertHooksRequest struct {
-	Hooks []upsertHookItem `json:"hooks"`
+// HookConfigResponse is an event's first subscription as GET /settings/hooks reported
+// hooks before subscriptions. URL is empty while the subscription is disabled.
+type HookConfigResponse struct {
+	Event                      hooks.Event `json:"event"`
+	URL                        string      `json:"url"`
+	InternalHookSubscriptionID string      `json:"internalHookSubscriptionId,omitempty"`
+	HasSecret                  bool        `json:"hasSecret"`
+	PreviousSecretExpiresAt    *time.Time  `json:"previousSecretExpiresAt,omitempty"`
+	UpdatedAt                  time.Time   `json:"updatedAt"`
+}
+
+type SubscriptionResponse struct {
+	InternalHookSubscriptionID string            `json:"internalHookSubscriptionId"`
+	Event                      string            `json:"event"`
+	URL                        string            `json:"url"`
+	Enabled                    bool              `json:"enabled"`
+	Headers                    map[string]string `json:"headers"`
+	TimeoutMs                  int32             `json:"timeoutMs"`
+	Filter                     hooks.Filter      `json:"filter"`
+	FailureMode                string            `json:"failureMode"`
+	HasSecret                  bool              `json:"hasSecret"`
+	PreviousSecretExpiresAt    *time.Time        `json:"previousSecretExpiresAt,omitempty"`
+	CreatedAt                  time.Time         `json:"createdAt"`
+	UpdatedAt                  time.Time         `json:"updatedAt"`
+}
+
+// CreateSubscriptionResponse carries the new subscription's signing secret, which is
+// only ever returned here and by a rotation.
+type CreateSubscriptionResponse struct {
+	SubscriptionResponse
+	Secret string `json:"secret"`
+}
+
+// SecretRotationResponse carries the new signing secret. The previous secret keeps
+// signing requests, next to the new one, until PreviousSecretExpiresAt.
+type SecretRotationResponse struct {
+	InternalHookSubscriptionID string     `json:"internalHookSubscriptionId"`
+	Secret                     string     `json:"secret"`
+	PreviousSecretExpiresAt    *time.Time `json:"previousSecretExpiresAt,omitempty"`
+}
+
+type DeliveryListOpts struct {
+	Status         *string
+	Event          *string
+	SubscriptionID *string
+	Limit          int
+}
+
+// DeliveryResponse is one queued post-event hook. Payload is only returned when a single
+// delivery is fetched. InternalHookSubscriptionID is null once the subscription is deleted.
+type DeliveryResponse struct {
+	InternalHookDeliveryID     string          `json:"internalHookDeliveryId"`
+	InternalHookSubscriptionID *string         `json:"internalHookSubscriptionId"`
+	Event                      string          `json:"event"`
+	Payload                    json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	Status                     string          `json:"status"`
+	Attempts                   int32           `json:"attempts"`
+	NextAttemptAt              time.Time       `json:"nextAttemptAt"`
+	LastError                  *string         `json:"lastError,omitempty"`
+	LastStatusCode             *int32          `json:"lastStatusCode,omitempty"`
+	DeliveredAt                *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt                  time.Time       `json:"createdAt"`
+	UpdatedAt                  time.Time       `json:"updatedAt"`
 }
@@ -0,0 +1,314 @@
+package hooksettings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/hooks"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
+
+// reservedHookHeaders are set on every hook request and cannot be overridden by a
+// subscription; X-Hook-* headers are reserved as well.
+var reservedHookHeaders = map[string]struct{}{
+	"Content-Type":   {},
+	"Content-Length": {},
+	"Host":           {},
+}
+
+func validateEvent(event hooks.Event) error {
+	if !hooks.IsValidEvent(event) {
+		return fmt.Errorf("invalid hook event %q", event)
+	}
+	return nil
+}
+
+// parseSecretGrace reads a Go duration such as "24h" or "90m"; empty means
+// defaultSecretGrace and "0" drops the previous secret at once.
+func parseSecretGrace(raw string) (time.Duration, error) {
+	if raw == "" {
+		return defaultSecretGrace, nil
+	}
+	grace, err := time.ParseDuration(raw)
+	if err != nil || grace < 0 || grace > maxSecretGrace {
+		return 0, errors.New("grace must be a duration between 0s and 168h")
+	}
+	return grace, nil
+}
+
+func validateSubscriptionID(id string) error {
+	return httpx.ValidateVar(id, "required,uuid4", "internalHookSubscriptionId is invalid")
+}
+
+func normalizeSubscriptionRequest(req *subscriptionRequest) {
+	req.Event = hooks.Event(strings.ToLower(strings.TrimSpace(string(req.Event))))
+	req.URL = strings.TrimSpace(req.URL)
+	if req.Enabled == nil {
+		enabled := true
+		req.Enabled = &enabled
+	}
+	req.FailureMode = strings.ToLower(strings.TrimSpace(req.FailureMode))
+	if req.FailureMode == "" {
+		req.FailureMode = hooks.FailureModeClosed
+	}
+	if req.TimeoutMs == nil {
+		timeoutMs := int32(defaultSubscriptionTimeoutMs)
+		req.TimeoutMs = &timeoutMs
+	}
+	if len(req.Headers) > 0 {
+		headers := make(map[string]string, len(req.Headers))
+		for name, value := range req.Headers {
+			headers[http.CanonicalHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
+		}
+		req.Headers = headers
+	}
+}
+
+func validateSubscriptionRequest(req subscriptionRequest) error {
+	if err := validateEvent(req.Event); err != nil {
+		return err
+	}
+	if err := httpx.ValidateVar(req.URL, "required,http_url", "url must be a valid http or https URL"); err != nil {
+		return err
+	}
+	if err := httpx.ValidateVar(req.FailureMode, "oneof=open closed", "failureMode must be one of open|closed"); err != nil {
+		return err
+	}
+	if *req.TimeoutMs < minSubscriptionTimeoutMs || *req.TimeoutMs > maxSubscriptionTimeoutMs {
+		return fmt.Errorf("timeoutMs must be between %d and %d", minSubscriptionTimeoutMs, maxSubscriptionTimeoutMs)
+	}
+	if err := validateSubscriptionHeaders(req.Headers); err != nil {
+		return err
+	}
+	return validateSubscriptionFilter(req.Filter)
+}
+
+func validateSubscriptionHeaders(headers map[string]string) error {
+	if len(headers) > maxSubscriptionHeaders {
+		return fmt.Errorf("headers cannot have more than %d entries", maxSubscriptionHeaders)
+	}
+	for name, value := range headers {
+		if !isHeaderToken(name) {
+			return fmt.Errorf("header name %q is invalid", name)
+		}
+		if _, ok := reservedHookHeaders[name]; ok || strings.HasPrefix(name, "X-Hook-") {
+			return fmt.Errorf("header %s is set by the hook dispatcher and cannot be overridden", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %s has an invalid value", name)
+		}
+	}
+	return nil
+}
+
+// isHeaderToken reports whether name is an RFC 9110 token.
+func isHeaderToken(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func validateSubscriptionFilter(filter hooks.Filter) error {
+	if len(filter) > maxFilterKeys {
+		return fmt.Errorf("filter cannot have more than %d fields", maxFilterKeys)
+	}
+	for key, values := range filter {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("filter field names cannot be empty")
+		}
+		if len(values) == 0 || len(values) > maxFilterValues {
+			return fmt.Errorf("filter field %s must list between 1 and %d values", key, maxFilterValues)
+		}
+	}
+	return nil
+}
+
+// encodeSubscriptionSettings returns the JSONB values stored for the request's headers and
+// filter; missing ones are stored as empty objects.
+func encodeSubscriptionSettings(req subscriptionRequest) ([]byte, []byte, error) {
+	headers := req.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	filter := req.Filter
+	if filter == nil {
+		filter = hooks.Filter{}
+	}
+
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return nil, nil, err
+	}
+	encodedFilter, err := json.Marshal(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodedHeaders, encodedFilter, nil
+}
+
+func toSubscriptionResponse(item sqlc.HookSubscription) (SubscriptionResponse, error) {
+	response := SubscriptionResponse{
+		InternalHookSubscriptionID: item.InternalHookSubscriptionID,
+		Event:                      item.EventName,
+		URL:                        item.TargetUrl,
+		Enabled:                    item.Enabled,
+		TimeoutMs:                  item.TimeoutMs,
+		FailureMode:                item.FailureMode,
+		HasSecret:                  item.SigningSecret != "",
+		CreatedAt:                  item.CreatedAt,
+		UpdatedAt:                  item.UpdatedAt,
+	}
+	if expiresAt := item.PreviousSigningSecretExpiresAt; expiresAt != nil && expiresAt.After(time.Now()) {
+		response.PreviousSecretExpiresAt = expiresAt
+	}
+	if err := json.Unmarshal(item.Headers, &response.Headers); err != nil {
+		return SubscriptionResponse{}, fmt.Errorf("decode headers of hook subscription %s: %w", item.InternalHookSubscriptionID, err)
+	}
+	if err := json.Unmarshal(item.Filter, &response.Filter); err != nil {
+		return SubscriptionResponse{}, fmt.Errorf("decode filter of hook subscription %s: %w", item.InternalHookSubscriptionID, err)
+	}
+	return response, nil
+}
+
+func toSubscriptionResponses(items []sqlc.HookSubscription) ([]SubscriptionResponse, error) {
+	out := make([]SubscriptionResponse, 0, len(items))
+	for _, item := range items {
+		response, err := toSubscriptionResponse(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, response)
+	}
+	return out, nil
+}
+
+func normalizeUpsertHooksRequest(req *upsertHooksRequest) {
+	for i := range req.Hooks {
+		req.Hooks[i].Event = hooks.Event(strings.ToLower(strings.TrimSpace(string(req.Hooks[i].Event))))
+		req.Hooks[i].URL = strings.TrimSpace(req.Hooks[i].URL)
+	}
+}
+
+func validateUpsertHooksRequest(req upsertHooksRequest) error {
+	if len(req.Hooks) == 0 {
+		return errors.New("hooks payload is required")
+	}
+	for i, hook := range req.Hooks {
+		if err := validateEvent(hook.Event); err != nil {
+			return err
+		}
+		if hook.URL == "" {
+			continue
+		}
+		if err := httpx.ValidateVar(hook.URL, "http_url", fmt.Sprintf("hooks[%d].url must be a valid http or https URL", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newHookConfigSubscription is the subscription PUT /settings/hooks creates for an event
+// without one: enabled, unfiltered and with the default timeout and failure mode.
+func newHookConfigSubscription(hook upsertHookItem) subscriptionRequest {
+	req := subscriptionRequest{Event: hook.Event, URL: hook.URL}
+	normalizeSubscriptionRequest(&req)
+	return req
+}
+
+// firstSubscriptionPerEvent expects subscriptions ordered by event and then creation.
+func firstSubscriptionPerEvent(items []sqlc.HookSubscription) []sqlc.HookSubscription {
+	out := make([]sqlc.HookSubscription, 0, len(items))
+	for _, item := range items {
+		if len(out) > 0 && out[len(out)-1].EventName == item.EventName {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func toHookConfigResponse(item sqlc.HookSubscription) HookConfigResponse {
+	response := HookConfigResponse{
+		Event:                      hooks.Event(item.EventName),
+		InternalHookSubscriptionID: item.InternalHookSubscriptionID,
+		HasSecret:                  item.SigningSecret != "",
+		UpdatedAt:                  item.UpdatedAt,
+	}
+	if item.Enabled {
+		response.URL = item.TargetUrl
+	}
+	if expiresAt := item.PreviousSigningSecretExpiresAt; expiresAt != nil && expiresAt.After(time.Now()) {
+		response.PreviousSecretExpiresAt = expiresAt
+	}
+	return response
+}
+
+func toHookConfigResponses(items []sqlc.HookSubscription) []HookConfigResponse {
+	out := make([]HookConfigResponse, 0, len(items))
+	for _, item := range items {
+		out = append(out, toHookConfigResponse(item))
+	}
+	return out
+}
+
+func validateDeliveryID(id string) error {
+	return httpx.ValidateVar(id, "required,uuid4", "internalHookDeliveryId is invalid")
+}
+
+func validateDeliveryListOpts(opts DeliveryListOpts) error {
+	if opts.Status != nil {
+		if err := httpx.ValidateVar(*opts.Status, "oneof=pending processing delivered dead", "status must be one of pending|processing|delivered|dead"); err != nil {
+			return err
+		}
+	}
+	if opts.SubscriptionID != nil {
+		if err := httpx.ValidateVar(*opts.SubscriptionID, "uuid4", "subscriptionId is invalid"); err != nil {
+			return err
+		}
+	}
+	if opts.Event != nil {
+		return validateEvent(hooks.Event(*opts.Event))
+	}
+	return nil
+}
+
+func toDeliveryResponse(item sqlc.HookDelivery) DeliveryResponse {
+	return DeliveryResponse{
+		InternalHookDeliveryID:     item.InternalHookDeliveryID,
+		InternalHookSubscriptionID: item.SubscriptionID,
+		Event:                      item.EventName,
+		Payload:                    item.Payload,
+		Status:                     item.Status,
+		Attempts:                   item.Attempts,
+		NextAttemptAt:              item.NextAttemptAt,
+		LastError:                  item.LastError,
+		LastStatusCode:             item.LastStatusCode,
+		DeliveredAt:                item.DeliveredAt,
+		CreatedAt:                  item.CreatedAt,
+		UpdatedAt:                  item.UpdatedAt,
+	}
+}
+
+func toDeliveryResponses(items []sqlc.HookDelivery) []DeliveryResponse {
+	out := make([]DeliveryResponse, 0, len(items))
+	for _, item := range items {
+		response := toDeliveryResponse(item)
+		response.Payload = nil
+		out = append(out, response)
+	}
+	return out
+}
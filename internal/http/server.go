@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
@@ -9,9 +10,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/keithics/devops-dashboard/api/docs/swagger"
+	"github.com/keithics/devops-dashboard/api/internal/anilistmapping"
 	"github.com/keithics/devops-dashboard/api/internal/apikey"
 	"github.com/keithics/devops-dashboard/api/internal/auth"
 	"github.com/keithics/devops-dashboard/api/internal/config"
+	"github.com/keithics/devops-dashboard/api/internal/cryptox"
 	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/episode"
 	"github.com/keithics/devops-dashboard/api/internal/hooks"
@@ -20,15 +23,21 @@ import (
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
 	"github.com/keithics/devops-dashboard/api/internal/metadata"
 	workermeta "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	metadatacache "github.com/keithics/devops-dashboard/api/internal/metadata/provider/cache"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/providers/anidb"
 	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/providers/anilist"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/providers/anilisttvdb"
 	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/providers/tvdb"
 	"github.com/keithics/devops-dashboard/api/internal/show"
+	"github.com/keithics/devops-dashboard/api/internal/showjob"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"golang.org/x/time/rate"
 )
 
-func NewServer(cfg config.Config, pool *pgxpool.Pool) *Server {
+// NewServer wires the handlers and workers. It fails when TOKEN_ENCRYPTION_KEY cannot
+// build the cipher that hook signing secrets are stored with.
+func NewServer(cfg config.Config, pool *pgxpool.Pool) (*Server, error) {
 	r := gin.New()
 	r.Use(httperr.Recovery())
 	r.Use(gin.Logger())
@@ -41,26 +50,51 @@ func NewServer(cfg config.Config, pool *pgxpool.Pool) *Server {
 	if err := authHandler.EnsureSeedOwner(context.Background()); err != nil {
 		log.Printf("failed to ensure seed owner user: %v", err)
 	}
-	var hookDispatcher hooks.Dispatcher = hooks.NoopDispatcher{}
-	httpHookDispatcher, err := hooks.NewHTTPDispatcher(pool)
+	hookCipher, err := cryptox.NewCipher(cfg.TokenEncryptionKey)
 	if err != nil {
-		log.Printf("failed to initialize hook dispatcher, using noop: %v", err)
-	} else {
-		hookDispatcher = httpHookDispatcher
+		return nil, fmt.Errorf("hook secret cipher: %w", err)
 	}
-	episodeHandler := episode.NewHandlerWithHooks(q, hookDispatcher)
-	anilistProvider := anilist.New()
-	metadataRegistry := workermeta.NewRegistry(map[workermeta.ProviderName]workermeta.Provider{
-		workermeta.ProviderAniDB:   anilistProvider,
-		workermeta.ProviderAniList: anilistProvider,
-		workermeta.ProviderTVDB:    tvdb.New(),
-	})
-	showHandler := show.NewHandlerWithHooks(q, hookDispatcher)
-	metadataHandler := metadata.NewHandler(metadata.NewService(workermeta.NewService(metadataRegistry), showHandler.Service()))
-	hookSettingsHandler, err := hooksettings.NewHandler(pool)
-	if err != nil {
-		log.Printf("failed to initialize hook settings handler: %v", err)
+	hookDispatcher := hooks.NewHTTPDispatcher(pool, hookCipher)
+	episodeHandler := episode.NewHandlerWithHooks(pool, hookDispatcher)
+	anilistMappingHandler := anilistmapping.NewHandler(q)
+	tvdbProvider := tvdb.New()
+	metadataProviders := map[workermeta.ProviderName]workermeta.Provider{
+		workermeta.ProviderAniDB:   anidb.New(),
+		workermeta.ProviderAniList: anilisttvdb.New(anilist.New(), tvdbProvider, anilistMappingHandler.Service()),
+		workermeta.ProviderTVDB:    tvdbProvider,
 	}
+	var metadataCache *metadatacache.Cache
+	if cfg.Cache.Enabled {
+		cacheOpts := metadatacache.Options{
+			SearchTTL:   cfg.Cache.SearchTTL,
+			DiscoverTTL: cfg.Cache.DiscoverTTL,
+			ShowTTL:     cfg.Cache.ShowTTL,
+			EpisodesTTL: cfg.Cache.EpisodesTTL,
+			MaxEntries:  cfg.Cache.MaxEntries,
+		}
+		if cfg.Cache.Postgres {
+			cacheOpts.Store = metadatacache.NewPostgresStore(q)
+		}
+		metadataCache = metadatacache.New(cacheOpts)
+		for name, provider := range metadataProviders {
+			metadataProviders[name] = metadataCache.Wrap(name, provider)
+		}
+	}
+	metadataRegistry := workermeta.NewRegistry(metadataProviders)
+	showHandler := show.NewHandlerWithHooks(pool, hookDispatcher)
+	showJobService := showjob.NewService(pool)
+	showJobHandler := showjob.NewHandler(showJobService)
+	metadataService := metadata.NewService(pool, workermeta.NewService(metadataRegistry), showHandler.Service(), episodeHandler.Service(), showJobService, metadataCache)
+	metadataHandler := metadata.NewHandler(metadataService, metadata.HandlerOptions{
+		SearchMinScore: cfg.SearchMinScore,
+		SearchTimeout:  cfg.SearchTimeout,
+	})
+	showJobWorker := showjob.NewWorker(showJobService, metadataService, showjob.WorkerOptions{
+		PollInterval: cfg.WorkerPollInterval,
+		Concurrency:  cfg.WorkerConcurrency,
+		MaxRetries:   cfg.WorkerMaxRetries,
+	})
+	hookSettingsHandler := hooksettings.NewHandler(pool, hookCipher)
 
 	r.GET("/health", httpx.RateLimitByIP(rate.Limit(10), 20, 5*time.Minute), healthHandler)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
@@ -69,22 +103,49 @@ func NewServer(cfg config.Config, pool *pgxpool.Pool) *Server {
 	episode.RegisterRoutes(r, episodeHandler)
 	apikey.RegisterRoutes(r, apiKeyHandler)
 	metadata.RegisterRoutes(r, metadataHandler)
+	anilistmapping.RegisterRoutes(r, anilistMappingHandler)
 	show.RegisterRoutes(r, showHandler)
-	if hookSettingsHandler != nil {
-		hooksettings.RegisterRoutes(r, hookSettingsHandler)
-	}
+	showjob.RegisterRoutes(r, showJobHandler)
+	hooksettings.RegisterRoutes(r, hookSettingsHandler)
 
 	return &Server{
-		cfg:    cfg,
-		pool:   pool,
-		router: r,
-	}
+		cfg:           cfg,
+		pool:          pool,
+		router:        r,
+		worker:        showJobWorker,
+		bulkAddWorker: metadata.NewBulkAddWorker(metadataService, cfg.WorkerPollInterval),
+		hookWorker: hooks.NewDeliveryWorker(pool, hookCipher, hooks.DeliveryWorkerOptions{
+			PollInterval: cfg.WorkerPollInterval,
+			MaxAttempts:  cfg.Hooks.DeliveryMaxAttempts,
+		}),
+		refresher: metadata.NewRefresher(metadataService, cfg.RefreshInterval),
+	}, nil
 }
 
 func (s *Server) Router() http.Handler {
 	return s.router
 }
 
+// RunWorker processes the job_shows queue until ctx is cancelled.
+func (s *Server) RunWorker(ctx context.Context) {
+	s.worker.Run(ctx)
+}
+
+// RunBulkAddWorker processes the job_bulk_adds queue until ctx is cancelled.
+func (s *Server) RunBulkAddWorker(ctx context.Context) {
+	s.bulkAddWorker.Run(ctx)
+}
+
+// RunHookDeliveries sends due hook_deliveries until ctx is cancelled.
+func (s *Server) RunHookDeliveries(ctx context.Context) {
+	s.hookWorker.Run(ctx)
+}
+
+// RunRefresher periodically refreshes ongoing shows until ctx is cancelled.
+func (s *Server) RunRefresher(ctx context.Context) {
+	s.refresher.Run(ctx)
+}
+
 // healthHandler godoc
 //
 //	@Summary		Health check
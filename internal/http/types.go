@@ -4,12 +4,19 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/keithics/devops-dashboard/api/internal/config"
+	"github.com/keithics/devops-dashboard/api/internal/hooks"
+	"github.com/keithics/devops-dashboard/api/internal/metadata"
+	"github.com/keithics/devops-dashboard/api/internal/showjob"
 )
 
 type Server struct {
-	cfg    config.Config
-	pool   *pgxpool.Pool
-	router *gin.Engine
+	cfg           config.Config
+	pool          *pgxpool.Pool
+	router        *gin.Engine
+	worker        *showjob.Worker
+	bulkAddWorker *metadata.BulkAddWorker
+	hookWorker    *hooks.DeliveryWorker
+	refresher     *metadata.Refresher
 }
 
 type healthResponse struct {
@@ -65,6 +65,42 @@ func PayloadTooLarge(message string) *HTTPError {
 	return New(http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", message)
 }
 
+func TooManyRequests(message string) *HTTPError {
+	return New(http.StatusTooManyRequests, "TOO_MANY_REQUESTS", message)
+}
+
+func ProviderNotFound(message string) *HTTPError {
+	return New(http.StatusNotFound, CodeProviderNotFound, message)
+}
+
+func ProviderUnsupported(message string) *HTTPError {
+	return New(http.StatusBadRequest, CodeProviderUnsupported, message)
+}
+
+func InvalidExternalID(message string) *HTTPError {
+	return New(http.StatusBadRequest, CodeInvalidExternalID, message)
+}
+
+func ProviderUnavailable(message string) *HTTPError {
+	return New(http.StatusBadGateway, CodeProviderUnavailable, message)
+}
+
+func ProviderRateLimited(message string) *HTTPError {
+	return New(http.StatusTooManyRequests, CodeProviderRateLimited, message)
+}
+
+func ImportTooLarge(message string) *HTTPError {
+	return New(http.StatusUnprocessableEntity, CodeImportTooLarge, message)
+}
+
+func HookRejected(message string) *HTTPError {
+	return New(http.StatusUnprocessableEntity, CodeHookRejected, message)
+}
+
+func HookFailed(message string) *HTTPError {
+	return New(http.StatusBadGateway, CodeHookFailed, message)
+}
+
 func Internal(message string) *HTTPError {
 	return New(http.StatusInternalServerError, "INTERNAL_ERROR", message)
 }
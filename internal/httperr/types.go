@@ -1,5 +1,22 @@
 package httperr
 
+// Stable codes for metadata provider failures, so clients can react without parsing
+// messages.
+const (
+	CodeProviderNotFound    = "PROVIDER_NOT_FOUND"
+	CodeProviderUnsupported = "PROVIDER_UNSUPPORTED"
+	CodeInvalidExternalID   = "INVALID_EXTERNAL_ID"
+	CodeProviderUnavailable = "PROVIDER_UNAVAILABLE"
+	CodeProviderRateLimited = "PROVIDER_RATE_LIMITED"
+	CodeImportTooLarge      = "IMPORT_TOO_LARGE"
+)
+
+// Stable codes for blocking pre-event hooks.
+const (
+	CodeHookRejected = "HOOK_REJECTED"
+	CodeHookFailed   = "HOOK_FAILED"
+)
+
 type HTTPError struct {
 	Status  int
 	Code    string
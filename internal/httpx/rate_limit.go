@@ -1,7 +1,6 @@
 package httpx
 
 import (
-	"net/http"
 	"sync"
 	"time"
 
@@ -67,6 +66,6 @@ func RateLimitByIP(limit rate.Limit, burst int, ttl time.Duration) gin.HandlerFu
 			return
 		}
 
-		httperr.Abort(c, httperr.New(http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "rate limit exceeded"))
+		httperr.Abort(c, httperr.TooManyRequests("rate limit exceeded"))
 	}
 }
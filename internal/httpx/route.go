@@ -0,0 +1,19 @@
+package httpx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httperr"
+)
+
+// RequireParam aborts with 404 unless the path param name equals value. Gin only matches
+// escaped colons in routes when the engine is started through Run, so custom-method
+// segments such as episodes:bulk are registered as a param and checked here.
+func RequireParam(name string, value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param(name) != value {
+			httperr.Abort(c, httperr.NotFound("route not found"))
+			return
+		}
+		c.Next()
+	}
+}
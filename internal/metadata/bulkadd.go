@@ -0,0 +1,233 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
+	"github.com/keithics/devops-dashboard/api/internal/utils/workerid"
+)
+
+// BulkAddShows adds every item as AddShowByExternalID would, bulkAddConcurrency at a
+// time, and reports each item's outcome in request order. A failing item does not stop
+// the rest.
+func (s *Service) BulkAddShows(ctx context.Context, items []bulkAddShowItem, opts BulkAddOptions) BulkAddShowsResult {
+	outcomes := make([]BulkAddShowResult, len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(bulkAddConcurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				outcomes[i] = s.bulkAddShow(ctx, items[i], opts)
+				outcomes[i].Index = i
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	result := BulkAddShowsResult{Items: outcomes}
+	for _, outcome := range outcomes {
+		switch outcome.Status {
+		case BulkAddStatusCreated:
+			result.Created++
+		case BulkAddStatusExisting:
+			result.Existing++
+		case BulkAddStatusSkipped:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+	}
+	return result
+}
+
+// bulkAddShow adds one item. In skip mode a show already linked to the requested id is
+// skipped before any provider call; one matched later by title or linked id is skipped
+// when the insert reports it as a duplicate.
+func (s *Service) bulkAddShow(ctx context.Context, item bulkAddShowItem, opts BulkAddOptions) BulkAddShowResult {
+	outcome := BulkAddShowResult{ExternalID: item.ExternalID, Status: BulkAddStatusError}
+	provider, err := resolveBulkAddProvider(item)
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	outcome.Provider = provider
+
+	onConflict := opts.OnConflict
+	if onConflict == bulkOnConflictSkip {
+		showID, err := s.linkedShowID(ctx, provider, item.ExternalID)
+		if err != nil {
+			outcome.Err = err
+			return outcome
+		}
+		if showID != "" {
+			outcome.Status = BulkAddStatusSkipped
+			outcome.InternalShowID = showID
+			return outcome
+		}
+		onConflict = showmodel.OnConflictReject
+	}
+
+	added, created, err := s.addShow(ctx, provider, item.ExternalID, onConflict, opts.ImportEpisodes)
+	var duplicate *showmodel.DuplicateShowError
+	if opts.OnConflict == bulkOnConflictSkip && errors.As(err, &duplicate) {
+		outcome.Status = BulkAddStatusSkipped
+		outcome.InternalShowID = duplicate.Existing.InternalShowID
+		return outcome
+	}
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	outcome.Show = &added
+	outcome.Status = BulkAddStatusExisting
+	if created {
+		outcome.Status = BulkAddStatusCreated
+	}
+	return outcome
+}
+
+// linkedShowID returns the local show linked to externalID, or "" when there is none.
+func (s *Service) linkedShowID(ctx context.Context, provider worker.ProviderName, externalID string) (string, error) {
+	if !strings.Contains(externalID, ":") {
+		externalID = string(provider) + ":" + externalID
+	}
+	ref, err := showmodel.ParseExternalRef(externalID)
+	if err != nil {
+		return "", nil
+	}
+	known, err := s.showSvc.KnownExternalIDs(ctx, []showmodel.ExternalRef{ref})
+	if err != nil {
+		return "", err
+	}
+	for _, row := range known {
+		if row.Provider == ref.Provider && row.ExternalID == ref.ID {
+			return row.ShowID, nil
+		}
+	}
+	return "", nil
+}
+
+// EnqueueBulkAdd stores the batch as a pending job for the BulkAddWorker.
+func (s *Service) EnqueueBulkAdd(ctx context.Context, items []bulkAddShowItem, opts BulkAddOptions) (sqlc.JobBulkAdd, error) {
+	payload, err := json.Marshal(items)
+	if err != nil {
+		return sqlc.JobBulkAdd{}, err
+	}
+	return s.q.CreateJobBulkAdd(ctx, sqlc.CreateJobBulkAddParams{
+		Items:          payload,
+		OnConflict:     opts.OnConflict,
+		ImportEpisodes: opts.ImportEpisodes,
+	})
+}
+
+func (s *Service) GetBulkAddJob(ctx context.Context, jobID string) (sqlc.JobBulkAdd, error) {
+	return s.q.GetJobBulkAddByID(ctx, jobID)
+}
+
+func NewBulkAddWorker(svc *Service, pollInterval time.Duration) *BulkAddWorker {
+	if pollInterval <= 0 {
+		pollInterval = defaultBulkAddPollInterval
+	}
+	return &BulkAddWorker{svc: svc, id: workerid.New(), pollInterval: pollInterval}
+}
+
+// Run processes queued bulk adds one job at a time until ctx is cancelled. A job left
+// processing by a stopped worker is taken over once bulkAddStaleAfter has passed; its
+// shows added before the stop are then reported as skipped or existing.
+func (w *BulkAddWorker) Run(ctx context.Context) {
+	log.Printf("bulk add worker %s started", w.id)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("bulk add worker %s stopped", w.id)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *BulkAddWorker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := w.processNext(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("bulk add worker %s: %v", w.id, err)
+			}
+			return
+		}
+		if !processed {
+			return
+		}
+	}
+}
+
+func (w *BulkAddWorker) processNext(ctx context.Context) (bool, error) {
+	job, err := w.svc.q.ClaimNextJobBulkAdd(ctx, sqlc.ClaimNextJobBulkAddParams{
+		LockedBy:    w.id,
+		StaleBefore: time.Now().Add(-bulkAddStaleAfter),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim bulk add job: %w", err)
+	}
+
+	var items []bulkAddShowItem
+	if err := json.Unmarshal(job.Items, &items); err != nil {
+		if _, failErr := w.svc.q.FailJobBulkAdd(ctx, sqlc.FailJobBulkAddParams{
+			ErrorMessage:         fmt.Sprintf("decode items: %v", err),
+			InternalJobBulkAddID: job.InternalJobBulkAddID,
+		}); failErr != nil {
+			return true, fmt.Errorf("record failure for bulk add job %s: %w", job.InternalJobBulkAddID, failErr)
+		}
+		return true, nil
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, bulkAddJobTimeout)
+	result := w.svc.BulkAddShows(jobCtx, items, BulkAddOptions{
+		OnConflict:     job.OnConflict,
+		ImportEpisodes: job.ImportEpisodes,
+	})
+	cancel()
+	// On shutdown the job stays processing, to be resumed once it goes stale.
+	if ctx.Err() != nil {
+		return true, ctx.Err()
+	}
+
+	payload, err := json.Marshal(toBulkAddShowsResponse(result))
+	if err != nil {
+		return true, err
+	}
+	if _, err := w.svc.q.CompleteJobBulkAdd(ctx, sqlc.CompleteJobBulkAddParams{
+		Result:               payload,
+		InternalJobBulkAddID: job.InternalJobBulkAddID,
+	}); err != nil {
+		return true, fmt.Errorf("record result for bulk add job %s: %w", job.InternalJobBulkAddID, err)
+	}
+	log.Printf("bulk add job %s completed: created=%d existing=%d skipped=%d failed=%d",
+		job.InternalJobBulkAddID, result.Created, result.Existing, result.Skipped, result.Failed)
+	return true, nil
+}
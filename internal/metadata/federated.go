@@ -0,0 +1,237 @@
+package metadata
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
+	"github.com/keithics/devops-dashboard/api/internal/utils/fuzzy"
+)
+
+// SearchAll searches every provider in providers concurrently, each bounded by timeout,
+// and merges hits that describe the same show. Failed providers are reported in
+// ProviderErrors next to the other providers' hits; only when every provider fails is
+// an error returned.
+func (s *Service) SearchAll(ctx context.Context, providers []worker.ProviderName, query string, opts worker.SearchOpts, minScore float64, timeout time.Duration) (FederatedSearchResult, error) {
+	outcomes := s.worker.SearchEach(ctx, providers, query, opts, timeout)
+
+	result := FederatedSearchResult{ProviderErrors: map[worker.ProviderName]error{}}
+	candidates := make([]searchCandidate, 0)
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			result.ProviderErrors[outcome.Provider] = outcome.Err
+			continue
+		}
+		for _, hit := range rankSearchHits(query, outcome.Hits, minScore) {
+			candidates = append(candidates, searchCandidate{provider: outcome.Provider, hit: hit})
+		}
+	}
+	if len(outcomes) > 0 && len(result.ProviderErrors) == len(outcomes) {
+		return FederatedSearchResult{}, outcomes[0].Err
+	}
+
+	known, err := s.showSvc.KnownExternalIDs(ctx, candidateRefs(candidates))
+	if err != nil {
+		return FederatedSearchResult{}, err
+	}
+
+	result.Items = mergeSearchCandidates(candidates, known)
+	return result, nil
+}
+
+// mergeSearchCandidates groups candidates that share an external id, belong to the
+// same local show, or come from different providers with the same start year and
+// near-identical titles. Groups are ordered by their best score, then by first hit.
+func mergeSearchCandidates(candidates []searchCandidate, known []sqlc.ShowExternalID) []FederatedSearchHit {
+	showByRef := make(map[string]string, len(known))
+	refsByShow := make(map[string][]string)
+	for _, row := range known {
+		ref := row.Provider + ":" + row.ExternalID
+		showByRef[ref] = row.ShowID
+		refsByShow[row.ShowID] = append(refsByShow[row.ShowID], ref)
+	}
+
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(a int, b int) {
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+		// The earlier hit stays the root so groups keep the order of their first hit.
+		if rb < ra {
+			ra, rb = rb, ra
+		}
+		parent[rb] = ra
+	}
+
+	firstByKey := make(map[string]int)
+	for i, candidate := range candidates {
+		for _, ref := range showmodel.ShowExternalRefs(candidate.hit.Hit) {
+			keys := []string{"ref:" + ref.String()}
+			if showID, ok := showByRef[ref.String()]; ok {
+				keys = append(keys, "show:"+showID)
+			}
+			for _, key := range keys {
+				if first, ok := firstByKey[key]; ok {
+					union(first, i)
+				} else {
+					firstByKey[key] = i
+				}
+			}
+		}
+	}
+
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			if candidates[i].provider == candidates[j].provider || find(i) == find(j) {
+				continue
+			}
+			if isSameShow(candidates[i].hit.Hit, candidates[j].hit.Hit) {
+				union(i, j)
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	roots := make([]int, 0)
+	for i := range candidates {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	items := make([]FederatedSearchHit, 0, len(roots))
+	for _, root := range roots {
+		items = append(items, mergeGroup(candidates, groups[root], showByRef, refsByShow))
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Score > items[j].Score
+	})
+	return items
+}
+
+func mergeGroup(candidates []searchCandidate, members []int, showByRef map[string]string, refsByShow map[string][]string) FederatedSearchHit {
+	best := members[0]
+	for _, i := range members[1:] {
+		if candidates[i].hit.Score > candidates[best].hit.Score {
+			best = i
+		}
+	}
+
+	merged := FederatedSearchHit{
+		Show:  candidates[best].hit.Hit,
+		Score: candidates[best].hit.Score,
+	}
+	merged.Show.AltTitles = slices.Clone(merged.Show.AltTitles)
+
+	externalIDs := make([]string, 0)
+	for _, i := range members {
+		candidate := candidates[i]
+		if !slices.Contains(merged.Providers, candidate.provider) {
+			merged.Providers = append(merged.Providers, candidate.provider)
+		}
+		if i != best {
+			merged.Show = fillMissingShowFields(merged.Show, candidate.hit.Hit)
+		}
+		for _, ref := range showmodel.ShowExternalRefs(candidate.hit.Hit) {
+			externalIDs = append(externalIDs, ref.String())
+			if showID, ok := showByRef[ref.String()]; ok && merged.InternalShowID == nil {
+				merged.InternalShowID = &showID
+			}
+		}
+	}
+	if merged.InternalShowID != nil {
+		externalIDs = append(externalIDs, refsByShow[*merged.InternalShowID]...)
+	}
+
+	sort.Strings(externalIDs)
+	merged.ExternalIDs = slices.Compact(externalIDs)
+	return merged
+}
+
+// fillMissingShowFields copies the fields primary lacks from other and adds other's
+// titles to primary's alternative titles.
+func fillMissingShowFields(primary worker.Show, other worker.Show) worker.Show {
+	titles := []string{other.TitlePreferred}
+	if other.TitleOriginal != nil {
+		titles = append(titles, *other.TitleOriginal)
+	}
+	titles = append(titles, other.AltTitles...)
+	for _, title := range titles {
+		if title == "" || title == primary.TitlePreferred || slices.Contains(primary.AltTitles, title) {
+			continue
+		}
+		if primary.TitleOriginal != nil && title == *primary.TitleOriginal {
+			continue
+		}
+		primary.AltTitles = append(primary.AltTitles, title)
+	}
+
+	if primary.TitleOriginal == nil {
+		primary.TitleOriginal = other.TitleOriginal
+	}
+	if primary.Synopsis == nil {
+		primary.Synopsis = other.Synopsis
+	}
+	if primary.StartDate == nil {
+		primary.StartDate = other.StartDate
+	}
+	if primary.EndDate == nil {
+		primary.EndDate = other.EndDate
+	}
+	if primary.PosterUrl == nil {
+		primary.PosterUrl = other.PosterUrl
+	}
+	if primary.BannerUrl == nil {
+		primary.BannerUrl = other.BannerUrl
+	}
+	if primary.SeasonCount == nil {
+		primary.SeasonCount = other.SeasonCount
+	}
+	if primary.EpisodeCount == nil {
+		primary.EpisodeCount = other.EpisodeCount
+	}
+	return primary
+}
+
+// isSameShow reports whether two hits from different providers share a start year and
+// have near-identical titles.
+func isSameShow(a worker.SearchHit, b worker.SearchHit) bool {
+	yearA, okA := startYear(a.StartDate)
+	yearB, okB := startYear(b.StartDate)
+	if !okA || !okB || yearA != yearB {
+		return false
+	}
+	return fuzzy.BestSimilarity(searchHitTitles(a), searchHitTitles(b)) >= mergeTitleThreshold
+}
+
+func startYear(date *string) (string, bool) {
+	if date == nil || len(*date) < 4 {
+		return "", false
+	}
+	return (*date)[:4], true
+}
+
+func candidateRefs(candidates []searchCandidate) []showmodel.ExternalRef {
+	refs := make([]showmodel.ExternalRef, 0, len(candidates))
+	for _, candidate := range candidates {
+		refs = append(refs, showmodel.ShowExternalRefs(candidate.hit.Hit)...)
+	}
+	return refs
+}
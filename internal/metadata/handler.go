@@ -4,35 +4,72 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httperr"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
 )
 
 // Search godoc
 //
 //	@Summary		Metadata search
-//	@Description	Search metadata by provider type (anidb default, tvdb optional)
+//	@Description	Search metadata by provider type (anidb default). type=all or a comma separated list searches several providers concurrently and returns a FederatedSearchResponse with merged hits and per-provider errors instead of a list.
 //	@Tags			metadata
 //	@Produce		json
 //	@Param			query	query		string	true	"Search query"
-//	@Param			type	query		string	false	"Provider type: anidb|tvdb (default anidb)"
+//	@Param			type	query		string	false	"Provider type: anidb|anilist|tvdb, all, or a comma separated list (default anidb)"
 //	@Param			page	query		int		false	"Page"
 //	@Param			limit	query		int		false	"Limit"
+//	@Param			minScore	query		number	false	"Minimum match score between 0 and 1 (default 0.5)"
 //	@Success		200		{array}		SearchHitResponse
 //	@Failure		400		{object}	httperr.APIErrorResponse
+//	@Failure		429		{object}	httperr.APIErrorResponse
 //	@Failure		500		{object}	httperr.APIErrorResponse
+//	@Failure		502		{object}	httperr.APIErrorResponse
+//	@Failure		503		{object}	httperr.APIErrorResponse
 //	@Router			/metadata/search [get]
 func (h *Handler) Search(c *gin.Context) {
-	provider, query, opts, ok := getSearchInput(c)
+	target, query, opts, minScore, ok := getSearchInput(c)
 	if !ok {
 		return
 	}
 
-	items, err := h.svc.Search(c.Request.Context(), provider, query, opts)
+	if target.Federated {
+		result, err := h.svc.SearchAll(c.Request.Context(), target.Providers, query, opts, minScore, h.opts.SearchTimeout)
+		if err != nil {
+			abortProviderErr(c, "failed to search metadata", err)
+			return
+		}
+		c.JSON(http.StatusOK, toFederatedSearchResponse(result))
+		return
+	}
+
+	items, err := h.svc.Search(c.Request.Context(), target.Providers[0], query, opts, minScore)
 	if err != nil {
 		abortProviderErr(c, "failed to search metadata", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	response := make([]SearchHitResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toSearchHitResponse(item))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListProviders godoc
+//
+//	@Summary		Metadata providers
+//	@Description	List registered metadata providers with their supported operations, id prefix, configuration state and last upstream latency and error
+//	@Tags			metadata
+//	@Produce		json
+//	@Success		200	{array}	ProviderResponse
+//	@Router			/metadata/providers [get]
+func (h *Handler) ListProviders(c *gin.Context) {
+	items := h.svc.DescribeProviders()
+	response := make([]ProviderResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toProviderResponse(item))
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // Discover godoc
@@ -46,7 +83,10 @@ func (h *Handler) Search(c *gin.Context) {
 //	@Param			limit	query		int		false	"Limit per section"
 //	@Success		200		{object}	DiscoverResponse
 //	@Failure		400		{object}	httperr.APIErrorResponse
+//	@Failure		429		{object}	httperr.APIErrorResponse
 //	@Failure		500		{object}	httperr.APIErrorResponse
+//	@Failure		502		{object}	httperr.APIErrorResponse
+//	@Failure		503		{object}	httperr.APIErrorResponse
 //	@Router			/metadata/discover [get]
 func (h *Handler) Discover(c *gin.Context) {
 	provider, opts, ok := getDiscoverInput(c)
@@ -73,7 +113,11 @@ func (h *Handler) Discover(c *gin.Context) {
 //	@Param			type		query		string	false	"Provider type: anidb|tvdb (default anidb)"
 //	@Success		200			{object}	ShowResponse
 //	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		404			{object}	httperr.APIErrorResponse
+//	@Failure		429			{object}	httperr.APIErrorResponse
 //	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Failure		502			{object}	httperr.APIErrorResponse
+//	@Failure		503			{object}	httperr.APIErrorResponse
 //	@Router			/metadata/show/{externalId} [get]
 func (h *Handler) GetShow(c *gin.Context) {
 	provider, externalID, ok := getProviderAndExternalID(c)
@@ -93,28 +137,182 @@ func (h *Handler) GetShow(c *gin.Context) {
 // AddShow godoc
 //
 //	@Summary		Metadata add show
-//	@Description	Fetch provider show by external id and create a local show record
+//	@Description	Fetch provider show by external id and create a local show record. An existing show is a conflict unless onConflict is return or update, in which case its missing episodes are imported.
 //	@Tags			metadata
 //	@Produce		json
 //	@Param			externalId	path		string	true	"Provider external id"
 //	@Param			type		query		string	false	"Provider type: anidb|anilist|tvdb (default anidb)"
-//	@Success		201			{object}	AddShowResponse
+//	@Param			onConflict	query		string	false	"Conflict policy: return|update (default: reject with 409)"
+//	@Success		200			{object}	AddShowResponse	"Existing show returned or updated"
+//	@Success		201			{object}	AddShowResponse	"New show created"
 //	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		404			{object}	httperr.APIErrorResponse
+//	@Failure		409			{object}	httperr.APIErrorResponse
+//	@Failure		422			{object}	httperr.APIErrorResponse
+//	@Failure		429			{object}	httperr.APIErrorResponse
 //	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Failure		502			{object}	httperr.APIErrorResponse
+//	@Failure		503			{object}	httperr.APIErrorResponse
 //	@Router			/metadata/show/{externalId} [post]
 func (h *Handler) AddShow(c *gin.Context) {
-	provider, externalID, ok := getProviderAndExternalID(c)
+	provider, externalID, onConflict, ok := getAddShowInput(c)
 	if !ok {
 		return
 	}
 
-	item, err := h.svc.AddShowByExternalID(c.Request.Context(), provider, externalID)
+	item, created, err := h.svc.AddShowByExternalID(c.Request.Context(), provider, externalID, onConflict)
 	if err != nil {
 		abortProviderErr(c, "failed to add metadata show", err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, item)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, item)
+}
+
+// AddShowAsync godoc
+//
+//	@Summary		Metadata add show asynchronously
+//	@Description	Fetch provider show by external id, create a local show record and queue a job that imports its episodes
+//	@Tags			metadata
+//	@Produce		json
+//	@Param			externalId	path		string	true	"Provider external id"
+//	@Param			type		query		string	false	"Provider type: anidb|anilist|tvdb (default anidb)"
+//	@Param			onConflict	query		string	false	"Conflict policy: return|update (default: reject with 409)"
+//	@Success		200			{object}	EnqueueShowResponse	"Existing active job"
+//	@Success		202			{object}	EnqueueShowResponse	"New job queued"
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		404			{object}	httperr.APIErrorResponse
+//	@Failure		409			{object}	httperr.APIErrorResponse
+//	@Failure		429			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Failure		502			{object}	httperr.APIErrorResponse
+//	@Failure		503			{object}	httperr.APIErrorResponse
+//	@Router			/metadata/show/{externalId}/async [post]
+func (h *Handler) AddShowAsync(c *gin.Context) {
+	provider, externalID, onConflict, ok := getAddShowInput(c)
+	if !ok {
+		return
+	}
+
+	item, created, err := h.svc.EnqueueShowByExternalID(c.Request.Context(), provider, externalID, onConflict)
+	if err != nil {
+		abortProviderErr(c, "failed to enqueue metadata show", err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusAccepted
+	}
+	c.JSON(status, item)
+}
+
+// RelatedShows godoc
+//
+//	@Summary		Metadata related shows
+//	@Description	Walk the show's franchise relations (sequels, prequels, side stories, movies, ...) and mark the shows already in the library
+//	@Tags			metadata
+//	@Produce		json
+//	@Param			externalId	path		string	true	"Provider external id"
+//	@Param			type		query		string	false	"Provider type: anidb|anilist|tvdb (default anidb)"
+//	@Param			depth		query		int		false	"Relation hops to follow, 1 to 3 (default 2)"
+//	@Success		200			{object}	RelatedShowsResponse
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		404			{object}	httperr.APIErrorResponse
+//	@Failure		429			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Failure		502			{object}	httperr.APIErrorResponse
+//	@Failure		503			{object}	httperr.APIErrorResponse
+//	@Router			/metadata/show/{externalId}/related [get]
+func (h *Handler) RelatedShows(c *gin.Context) {
+	provider, externalID, depth, ok := getRelatedInput(c)
+	if !ok {
+		return
+	}
+
+	graph, err := h.svc.RelatedShows(c.Request.Context(), provider, externalID, depth)
+	if err != nil {
+		abortProviderErr(c, "failed to list related metadata shows", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRelatedShowsResponse(graph))
+}
+
+// BulkAddShows godoc
+//
+//	@Summary		Metadata bulk add shows
+//	@Description	Add provider shows in one call, several at a time within the provider rate limits. Shows already in the library are skipped unless onConflict says otherwise. Items fail independently and the response lists every item's outcome in request order. With async=true the batch is queued as a job instead.
+//	@Tags			metadata
+//	@Accept			json
+//	@Produce		json
+//	@Param			onConflict	query		string				false	"Conflict policy for every item: skip|return|update (default skip)"
+//	@Param			episodes	query		bool				false	"Import each show's episodes (default true)"
+//	@Param			async		query		bool				false	"Queue the batch as a job (default false)"
+//	@Param			payload		body		[]bulkAddShowItem	true	"Shows (1 to 50, or 500 with async); type defaults to the external id's prefix"
+//	@Success		200			{object}	BulkAddShowsResponse
+//	@Success		202			{object}	BulkAddJobResponse	"Job queued"
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Router			/metadata/shows:bulk-add [post]
+func (h *Handler) BulkAddShows(c *gin.Context) {
+	items, opts, async, ok := getBulkAddInput(c)
+	if !ok {
+		return
+	}
+
+	if !async {
+		result := h.svc.BulkAddShows(c.Request.Context(), items, opts)
+		c.JSON(http.StatusOK, toBulkAddShowsResponse(result))
+		return
+	}
+
+	job, err := h.svc.EnqueueBulkAdd(c.Request.Context(), items, opts)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to enqueue bulk add").WithCause(err))
+		return
+	}
+	response, err := toBulkAddJobResponse(job)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to enqueue bulk add").WithCause(err))
+		return
+	}
+	c.JSON(http.StatusAccepted, response)
+}
+
+// GetBulkAddJob godoc
+//
+//	@Summary		Metadata get bulk add job
+//	@Description	Get a queued bulk add by job id. result holds the per-item outcomes once the job has completed.
+//	@Tags			metadata
+//	@Produce		json
+//	@Param			internalJobBulkAddId	path		string	true	"Internal bulk add job UUID"
+//	@Success		200						{object}	BulkAddJobResponse
+//	@Failure		400						{object}	httperr.APIErrorResponse
+//	@Failure		404						{object}	httperr.APIErrorResponse
+//	@Failure		500						{object}	httperr.APIErrorResponse
+//	@Router			/metadata/bulk-add-jobs/{internalJobBulkAddId} [get]
+func (h *Handler) GetBulkAddJob(c *gin.Context) {
+	jobID, ok := httpx.AbortIfMissingContext[string](c, ctxBulkAddJobIDKey)
+	if !ok {
+		return
+	}
+
+	job, err := h.svc.GetBulkAddJob(c.Request.Context(), jobID)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "bulk add job not found", "failed to get bulk add job") {
+		return
+	}
+
+	response, err := toBulkAddJobResponse(job)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to get bulk add job").WithCause(err))
+		return
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // ListEpisodes godoc
@@ -129,7 +327,11 @@ func (h *Handler) AddShow(c *gin.Context) {
 //	@Param			limit		query		int		false	"Limit"
 //	@Success		200			{array}		EpisodeResponse
 //	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		404			{object}	httperr.APIErrorResponse
+//	@Failure		429			{object}	httperr.APIErrorResponse
 //	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Failure		502			{object}	httperr.APIErrorResponse
+//	@Failure		503			{object}	httperr.APIErrorResponse
 //	@Router			/metadata/episodes/{externalId} [get]
 func (h *Handler) ListEpisodes(c *gin.Context) {
 	provider, externalID, opts, ok := getEpisodesInput(c)
@@ -145,3 +347,30 @@ func (h *Handler) ListEpisodes(c *gin.Context) {
 
 	c.JSON(http.StatusOK, items)
 }
+
+// PurgeCache godoc
+//
+//	@Summary		Metadata purge cache
+//	@Description	Drop cached provider responses for a provider, or only the show, episode and related show responses for one external id
+//	@Tags			metadata
+//	@Produce		json
+//	@Param			type		query		string	true	"Provider type: anidb|anilist|tvdb"
+//	@Param			externalId	query		string	false	"Provider external id"
+//	@Success		200			{object}	PurgeCacheResponse
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Router			/metadata/cache [delete]
+func (h *Handler) PurgeCache(c *gin.Context) {
+	provider, externalID, ok := getProviderAndExternalID(c)
+	if !ok {
+		return
+	}
+
+	purged, err := h.svc.PurgeCache(c.Request.Context(), provider, externalID)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to purge metadata cache").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, PurgeCacheResponse{Purged: purged})
+}
@@ -4,12 +4,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
 	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/cache"
+	"github.com/keithics/devops-dashboard/api/internal/show"
 	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
 )
 
 func (h *Handler) BindSearch() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		provider, err := parseProvider(c.Query("type"))
+		target, err := parseSearchTarget(c.Query("type"), h.svc.worker.Providers())
 		if httpx.AbortIfErr(c, err) {
 			return
 		}
@@ -24,9 +26,66 @@ func (h *Handler) BindSearch() gin.HandlerFunc {
 			Limit: httpx.ParsePositiveInt(c.Query("limit"), 10),
 		}
 
-		c.Set(ctxProviderTypeKey, provider)
+		minScore, err := parseMinScore(c.Query("minScore"), h.opts.SearchMinScore)
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+
+		c.Set(ctxSearchTargetKey, target)
 		c.Set(ctxQueryKey, query)
 		c.Set(ctxSearchOptsKey, opts)
+		c.Set(ctxMinScoreKey, minScore)
+		c.Next()
+	}
+}
+
+// CacheHeader reports through the X-Cache header whether the provider calls made by the
+// request were answered from the cache.
+func (h *Handler) CacheHeader() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, status := cache.WithStatus(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &cacheHeaderWriter{ResponseWriter: c.Writer, status: status}
+		c.Next()
+	}
+}
+
+func (w *cacheHeaderWriter) WriteHeaderNow() {
+	w.setCacheHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheHeaderWriter) Write(data []byte) (int, error) {
+	w.setCacheHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheHeaderWriter) WriteString(s string) (int, error) {
+	w.setCacheHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheHeaderWriter) setCacheHeader() {
+	if w.Written() {
+		return
+	}
+	if value := w.status.Header(); value != "" {
+		w.Header().Set(cache.HeaderName, value)
+	}
+}
+
+func (h *Handler) BindPurgeCache() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if httpx.AbortIfErr(c, validateCacheProviderType(c.Query("type"))) {
+			return
+		}
+		provider, err := parseProvider(c.Query("type"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+
+		c.Set(ctxProviderTypeKey, provider)
+		c.Set(ctxExternalIDKey, normalizeutil.String(c.Query("externalId")))
 		c.Next()
 	}
 }
@@ -77,3 +136,67 @@ func (h *Handler) BindEpisodesOpts() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (h *Handler) BindRelatedDepth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		depth, err := parseRelatedDepth(c.Query("depth"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		c.Set(ctxRelatedDepthKey, depth)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindBulkAddShows() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		async, err := parseBoolQuery(c.Query("async"), "async", false)
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		importEpisodes, err := parseBoolQuery(c.Query("episodes"), "episodes", true)
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		onConflict, err := parseBulkOnConflict(c.Query("onConflict"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+
+		var items []bulkAddShowItem
+		if httpx.AbortIfErr(c, c.ShouldBindJSON(&items)) {
+			return
+		}
+		normalizeBulkAddShowItems(items)
+		if httpx.AbortIfErr(c, validateBulkAddShowItems(items, async)) {
+			return
+		}
+
+		c.Set(ctxBulkAddShowsKey, items)
+		c.Set(ctxBulkAddOptsKey, BulkAddOptions{OnConflict: onConflict, ImportEpisodes: importEpisodes})
+		c.Set(ctxBulkAddAsyncKey, async)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindBulkAddJobID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID := c.Param("internalJobBulkAddId")
+		if httpx.AbortIfErr(c, validateBulkAddJobID(jobID)) {
+			return
+		}
+		c.Set(ctxBulkAddJobIDKey, jobID)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindOnConflict() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		onConflict, err := show.ParseOnConflict(c.Query("onConflict"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		c.Set(ctxOnConflictKey, onConflict)
+		c.Next()
+	}
+}
@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	metadata "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"golang.org/x/sync/singleflight"
+)
+
+func New(opts Options) *Cache {
+	opts = normalizeOptions(opts)
+	return &Cache{
+		opts:  opts,
+		lru:   newLRU(opts.MaxEntries),
+		store: opts.Store,
+	}
+}
+
+// Wrap returns next decorated with c. name scopes the cache keys and is what Purge
+// matches on.
+func (c *Cache) Wrap(name metadata.ProviderName, next metadata.Provider) *Provider {
+	return &Provider{cache: c, name: name, next: next}
+}
+
+// Purge drops a provider's cached responses, or only its show and episode responses for
+// externalID when that is set, and returns how many entries were removed from each tier.
+func (c *Cache) Purge(ctx context.Context, provider metadata.ProviderName, externalID string) (int64, error) {
+	externalID = normalizeExternalID(provider, externalID)
+	purged := c.lru.delete(string(provider), externalID)
+	if c.store == nil {
+		return purged, nil
+	}
+
+	stored, err := c.store.Delete(ctx, string(provider), externalID)
+	if err != nil {
+		return purged, err
+	}
+	return purged + stored, nil
+}
+
+func (p *Provider) Search(ctx context.Context, query string, opts metadata.SearchOpts) ([]metadata.SearchHit, error) {
+	key := p.key(methodSearch, normalizeQuery(query), fmt.Sprintf("page=%d", opts.Page), fmt.Sprintf("limit=%d", opts.Limit))
+	return load(ctx, p.cache, p.entry(key, methodSearch, ""), p.cache.opts.SearchTTL, func(ctx context.Context) ([]metadata.SearchHit, error) {
+		return p.next.Search(ctx, query, opts)
+	})
+}
+
+func (p *Provider) Discover(ctx context.Context, opts metadata.DiscoverOpts) (metadata.DiscoverResult, error) {
+	key := p.key(methodDiscover, fmt.Sprintf("page=%d", opts.Page), fmt.Sprintf("limit=%d", opts.Limit))
+	return load(ctx, p.cache, p.entry(key, methodDiscover, ""), p.cache.opts.DiscoverTTL, func(ctx context.Context) (metadata.DiscoverResult, error) {
+		return p.next.Discover(ctx, opts)
+	})
+}
+
+func (p *Provider) GetShow(ctx context.Context, externalID string) (metadata.Show, error) {
+	id := normalizeExternalID(p.name, externalID)
+	return load(ctx, p.cache, p.entry(p.key(methodShow, id), methodShow, id), p.cache.opts.ShowTTL, func(ctx context.Context) (metadata.Show, error) {
+		return p.next.GetShow(ctx, externalID)
+	})
+}
+
+func (p *Provider) ListEpisodes(ctx context.Context, externalID string, opts metadata.ListEpisodesOpts) ([]metadata.Episode, error) {
+	id := normalizeExternalID(p.name, externalID)
+	season := "season=all"
+	if opts.SeasonNumber != nil {
+		season = fmt.Sprintf("season=%d", *opts.SeasonNumber)
+	}
+	key := p.key(methodEpisodes, id, fmt.Sprintf("page=%d", opts.Page), fmt.Sprintf("limit=%d", opts.Limit), season)
+	return load(ctx, p.cache, p.entry(key, methodEpisodes, id), p.cache.opts.EpisodesTTL, func(ctx context.Context) ([]metadata.Episode, error) {
+		return p.next.ListEpisodes(ctx, externalID, opts)
+	})
+}
+
+// ListAllEpisodes caches the full episode list next to the paged ones, so a purge of the
+// show drops it too.
+func (p *Provider) ListAllEpisodes(ctx context.Context, externalID string) ([]metadata.Episode, error) {
+	lister, ok := p.next.(metadata.AllEpisodesLister)
+	if !ok {
+		return nil, metadata.Errorf(metadata.ErrUnsupported, "%s does not list all episodes at once", p.name)
+	}
+	id := normalizeExternalID(p.name, externalID)
+	return load(ctx, p.cache, p.entry(p.key(methodEpisodes, id, "all"), methodEpisodes, id), p.cache.opts.EpisodesTTL, func(ctx context.Context) ([]metadata.Episode, error) {
+		return lister.ListAllEpisodes(ctx, externalID)
+	})
+}
+
+// ListRelations caches relations for as long as shows, keyed by the show's external id so
+// a purge of the show drops them too.
+func (p *Provider) ListRelations(ctx context.Context, externalID string) (metadata.ShowRelations, error) {
+	lister, ok := p.next.(metadata.RelationLister)
+	if !ok {
+		return metadata.ShowRelations{}, metadata.Errorf(metadata.ErrUnsupported, "%s does not support related shows", p.name)
+	}
+	id := normalizeExternalID(p.name, externalID)
+	return load(ctx, p.cache, p.entry(p.key(methodRelated, id), methodRelated, id), p.cache.opts.ShowTTL, func(ctx context.Context) (metadata.ShowRelations, error) {
+		return lister.ListRelations(ctx, externalID)
+	})
+}
+
+func (p *Provider) Capabilities() metadata.Capabilities {
+	return metadata.CapabilitiesOf(p.next)
+}
+
+func (p *Provider) Health() metadata.Health {
+	return metadata.HealthOf(p.next)
+}
+
+func (p *Provider) key(method string, parts ...string) string {
+	return strings.Join(append([]string{string(p.name), method}, parts...), "|")
+}
+
+func (p *Provider) entry(key string, method string, externalID string) Entry {
+	return Entry{Key: key, Provider: string(p.name), Method: method, ExternalID: externalID}
+}
+
+// load answers from the memory tier, then the store, and otherwise calls fetch once
+// for all concurrent callers of the same key. The shared fetch outlives any one caller:
+// it runs without their cancellation, bounded by sharedFetchTimeout, while each caller
+// stops waiting when its own ctx is done. Errors are never cached, and a store failure
+// only costs the cache, not the request.
+func load[T any](ctx context.Context, c *Cache, entry Entry, ttl time.Duration, fetch func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if !bypassed(ctx) {
+		if payload, ok := c.lookup(ctx, entry.Key); ok {
+			var value T
+			if err := json.Unmarshal(payload, &value); err == nil {
+				recordHit(ctx)
+				return value, nil
+			}
+		}
+	}
+
+	recordMiss(ctx)
+	results := c.group.DoChan(entry.Key, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedFetchTimeout)
+		defer cancel()
+
+		value, err := fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		entry.Payload = payload
+		entry.ExpiresAt = time.Now().Add(ttl)
+		c.save(fetchCtx, entry)
+		return payload, nil
+	})
+
+	var result singleflight.Result
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result = <-results:
+	}
+	if result.Err != nil {
+		return zero, result.Err
+	}
+
+	var value T
+	if err := json.Unmarshal(result.Val.([]byte), &value); err != nil {
+		return zero, err
+	}
+	return value, nil
+}
+
+func (c *Cache) lookup(ctx context.Context, key string) ([]byte, bool) {
+	if entry, ok := c.lru.get(key, time.Now()); ok {
+		return entry.Payload, true
+	}
+	if c.store == nil {
+		return nil, false
+	}
+
+	entry, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		log.Printf("metadata cache: get %q: %v", key, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	c.lru.set(entry)
+	return entry.Payload, true
+}
+
+func (c *Cache) save(ctx context.Context, entry Entry) {
+	c.lru.set(entry)
+	if c.store == nil {
+		return
+	}
+	if err := c.store.Set(ctx, entry); err != nil {
+		log.Printf("metadata cache: set %q: %v", entry.Key, err)
+	}
+}
+
+func normalizeOptions(opts Options) Options {
+	if opts.SearchTTL <= 0 {
+		opts.SearchTTL = defaultSearchTTL
+	}
+	if opts.DiscoverTTL <= 0 {
+		opts.DiscoverTTL = defaultDiscoverTTL
+	}
+	if opts.ShowTTL <= 0 {
+		opts.ShowTTL = defaultShowTTL
+	}
+	if opts.EpisodesTTL <= 0 {
+		opts.EpisodesTTL = defaultEpisodesTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaultMaxEntries
+	}
+	return opts
+}
+
+// normalizeExternalID makes "anilist:123", "ANILIST:123" and "123" share one key.
+func normalizeExternalID(provider metadata.ProviderName, externalID string) string {
+	id := strings.ToLower(strings.TrimSpace(externalID))
+	return strings.TrimPrefix(id, string(provider)+":")
+}
+
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
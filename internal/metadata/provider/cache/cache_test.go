@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metadata "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+)
+
+// fakeProvider counts GetShow calls. When gate is set each call waits for it to close,
+// and err, when set, is returned instead of a show.
+type fakeProvider struct {
+	metadata.Provider
+
+	calls atomic.Int32
+	gate  chan struct{}
+	err   error
+}
+
+func (f *fakeProvider) GetShow(ctx context.Context, externalID string) (metadata.Show, error) {
+	f.calls.Add(1)
+	if f.gate != nil {
+		select {
+		case <-f.gate:
+		case <-ctx.Done():
+			return metadata.Show{}, ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return metadata.Show{}, f.err
+	}
+	return metadata.Show{ExternalID: externalID, TitlePreferred: "Frieren"}, nil
+}
+
+func TestProviderGetShow(t *testing.T) {
+	tests := []struct {
+		name       string
+		ids        []string
+		bypass     bool
+		ttl        time.Duration
+		pause      time.Duration
+		err        error
+		wantCalls  int32
+		wantHeader string
+	}{
+		{name: "second call is a hit", ids: []string{"anilist:1", "anilist:1"}, wantCalls: 1, wantHeader: HeaderHit},
+		{name: "ids share a key however written", ids: []string{"anilist:1", " ANILIST:1", "1"}, wantCalls: 1, wantHeader: HeaderHit},
+		{name: "other ids miss", ids: []string{"anilist:1", "anilist:2"}, wantCalls: 2, wantHeader: HeaderMiss},
+		{name: "bypass always calls the provider", ids: []string{"anilist:1", "anilist:1"}, bypass: true, wantCalls: 2, wantHeader: HeaderMiss},
+		{name: "expired entries miss", ids: []string{"anilist:1", "anilist:1"}, ttl: 10 * time.Millisecond, pause: 20 * time.Millisecond, wantCalls: 2, wantHeader: HeaderMiss},
+		{name: "errors are not cached", ids: []string{"anilist:1", "anilist:1"}, err: metadata.ErrNotFound, wantCalls: 2, wantHeader: HeaderMiss},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &fakeProvider{err: tt.err}
+			p := New(Options{ShowTTL: tt.ttl}).Wrap(metadata.ProviderAniList, next)
+
+			var status *Status
+			for i, id := range tt.ids {
+				ctx := context.Background()
+				if tt.bypass {
+					ctx = WithoutCache(ctx)
+				}
+				if i > 0 {
+					time.Sleep(tt.pause)
+				}
+				ctx, status = WithStatus(ctx)
+				if _, err := p.GetShow(ctx, id); !errors.Is(err, tt.err) {
+					t.Fatalf("GetShow(%q) err = %v, want %v", id, err, tt.err)
+				}
+			}
+			if got := next.calls.Load(); got != tt.wantCalls {
+				t.Errorf("provider calls = %d, want %d", got, tt.wantCalls)
+			}
+			if got := status.Header(); got != tt.wantHeader {
+				t.Errorf("last X-Cache = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestLoadSharesOneFetch(t *testing.T) {
+	next := &fakeProvider{gate: make(chan struct{})}
+	p := New(Options{}).Wrap(metadata.ProviderAniList, next)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.GetShow(context.Background(), "anilist:1")
+			errs <- err
+		}()
+	}
+	waitFor(t, func() bool { return next.calls.Load() == 1 })
+	// Give the other callers time to join the fetch before it completes.
+	time.Sleep(20 * time.Millisecond)
+	close(next.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetShow: %v", err)
+		}
+	}
+	if got := next.calls.Load(); got != 1 {
+		t.Errorf("provider calls = %d, want 1", got)
+	}
+}
+
+func TestLoadOutlivesCancelledCaller(t *testing.T) {
+	next := &fakeProvider{gate: make(chan struct{})}
+	p := New(Options{}).Wrap(metadata.ProviderAniList, next)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.GetShow(ctx, "anilist:1")
+		done <- err
+	}()
+	waitFor(t, func() bool { return next.calls.Load() == 1 })
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller err = %v, want context.Canceled", err)
+	}
+
+	// The shared fetch was not cancelled with its caller, so it still fills the cache.
+	close(next.gate)
+	waitFor(t, func() bool {
+		_, ok := p.cache.lru.get(p.key(methodShow, "1"), time.Now())
+		return ok
+	})
+	if _, err := p.GetShow(context.Background(), "anilist:1"); err != nil {
+		t.Fatalf("GetShow after the fetch: %v", err)
+	}
+	if got := next.calls.Load(); got != 1 {
+		t.Errorf("provider calls = %d, want 1", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within 2s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"container/list"
+	"time"
+)
+
+func newLRU(maxEntries int) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (l *lru) get(key string, now time.Time) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return Entry{}, false
+	}
+	entry := elem.Value.(Entry)
+	if !now.Before(entry.ExpiresAt) {
+		l.order.Remove(elem)
+		delete(l.items, key)
+		return Entry{}, false
+	}
+	l.order.MoveToFront(elem)
+	return entry, true
+}
+
+func (l *lru) set(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[entry.Key]; ok {
+		elem.Value = entry
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.items[entry.Key] = l.order.PushFront(entry)
+	for l.order.Len() > l.maxEntries {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(Entry).Key)
+	}
+}
+
+// delete removes a provider's entries, or only those for externalID when it is set.
+func (l *lru) delete(provider string, externalID string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var removed int64
+	for key, elem := range l.items {
+		entry := elem.Value.(Entry)
+		if entry.Provider != provider || (externalID != "" && entry.ExternalID != externalID) {
+			continue
+		}
+		l.order.Remove(elem)
+		delete(l.items, key)
+		removed++
+	}
+	return removed
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUExpiry(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		expires time.Duration
+		readAt  time.Duration
+		wantHit bool
+	}{
+		{name: "fresh", expires: time.Minute, readAt: 30 * time.Second, wantHit: true},
+		{name: "expires at the deadline", expires: time.Minute, readAt: time.Minute, wantHit: false},
+		{name: "expired", expires: time.Minute, readAt: 2 * time.Minute, wantHit: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLRU(10)
+			l.set(Entry{Key: "k", ExpiresAt: now.Add(tt.expires)})
+
+			if _, ok := l.get("k", now.Add(tt.readAt)); ok != tt.wantHit {
+				t.Fatalf("hit = %v, want %v", ok, tt.wantHit)
+			}
+			if !tt.wantHit && l.order.Len() != 0 {
+				t.Errorf("expired entry kept, len = %d", l.order.Len())
+			}
+		})
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		keys []string
+		// read or rewrite is touched before one more key is added.
+		read     string
+		rewrite  string
+		wantKeys []string
+		wantGone []string
+	}{
+		{name: "under capacity", keys: []string{"a", "b"}, wantKeys: []string{"a", "b"}},
+		{name: "oldest is evicted", keys: []string{"a", "b", "c", "d"}, wantKeys: []string{"b", "c", "d"}, wantGone: []string{"a"}},
+		{name: "a read keeps an entry", keys: []string{"a", "b", "c"}, read: "a", wantKeys: []string{"a", "c"}, wantGone: []string{"b"}},
+		{name: "an overwrite keeps an entry", keys: []string{"a", "b", "c"}, rewrite: "a", wantKeys: []string{"a", "c"}, wantGone: []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLRU(3)
+			for _, key := range tt.keys {
+				l.set(Entry{Key: key, ExpiresAt: expires})
+			}
+			if tt.read != "" {
+				l.get(tt.read, now)
+			}
+			if tt.rewrite != "" {
+				l.set(Entry{Key: tt.rewrite, ExpiresAt: expires})
+			}
+			if tt.read != "" || tt.rewrite != "" {
+				l.set(Entry{Key: "new", ExpiresAt: expires})
+			}
+
+			for _, key := range tt.wantKeys {
+				if _, ok := l.get(key, now); !ok {
+					t.Errorf("%q evicted, want kept", key)
+				}
+			}
+			for _, key := range tt.wantGone {
+				if _, ok := l.get(key, now); ok {
+					t.Errorf("%q kept, want evicted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	entries := []Entry{
+		{Key: "anilist|show|1", Provider: "anilist", ExternalID: "1", ExpiresAt: expires},
+		{Key: "anilist|episodes|1", Provider: "anilist", ExternalID: "1", ExpiresAt: expires},
+		{Key: "anilist|show|2", Provider: "anilist", ExternalID: "2", ExpiresAt: expires},
+		{Key: "anilist|search|x", Provider: "anilist", ExpiresAt: expires},
+		{Key: "tvdb|show|1", Provider: "tvdb", ExternalID: "1", ExpiresAt: expires},
+	}
+
+	tests := []struct {
+		name       string
+		provider   string
+		externalID string
+		want       int64
+	}{
+		{name: "one show", provider: "anilist", externalID: "1", want: 2},
+		{name: "whole provider", provider: "anilist", want: 4},
+		{name: "unknown show", provider: "tvdb", externalID: "9", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLRU(10)
+			for _, entry := range entries {
+				l.set(entry)
+			}
+			if got := l.delete(tt.provider, tt.externalID); got != tt.want {
+				t.Errorf("delete = %d, want %d", got, tt.want)
+			}
+			if got := int64(l.order.Len()); got != int64(len(entries))-tt.want {
+				t.Errorf("len = %d, want %d", got, int64(len(entries))-tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package cache
+
+import "context"
+
+// WithStatus returns a context whose provider calls are counted in the returned Status.
+func WithStatus(ctx context.Context) (context.Context, *Status) {
+	status := &Status{}
+	return context.WithValue(ctx, ctxStatusKey, status), status
+}
+
+// WithoutCache returns a context whose provider calls skip cached responses. Fresh
+// responses are still stored.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxBypassKey, true)
+}
+
+// Header is HIT when every counted call was served from the cache, MISS when any call
+// reached the provider, and empty when nothing was counted.
+func (s *Status) Header() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.misses > 0:
+		return HeaderMiss
+	case s.hits > 0:
+		return HeaderHit
+	default:
+		return ""
+	}
+}
+
+func recordHit(ctx context.Context) {
+	if status, ok := ctx.Value(ctxStatusKey).(*Status); ok {
+		status.mu.Lock()
+		status.hits++
+		status.mu.Unlock()
+	}
+}
+
+func recordMiss(ctx context.Context) {
+	if status, ok := ctx.Value(ctxStatusKey).(*Status); ok {
+		status.mu.Lock()
+		status.misses++
+		status.mu.Unlock()
+	}
+}
+
+func bypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(ctxBypassKey).(bool)
+	return bypass
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+)
+
+// NewPostgresStore keeps cache entries in the metadata_cache table so every API
+// instance shares them and they survive restarts.
+func NewPostgresStore(q *sqlc.Queries) *PostgresStore {
+	return &PostgresStore{q: q}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (Entry, bool, error) {
+	row, err := s.q.GetMetadataCacheEntry(ctx, key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Entry{}, false, nil
+		}
+		return Entry{}, false, err
+	}
+
+	entry := Entry{
+		Key:       row.CacheKey,
+		Provider:  row.Provider,
+		Method:    row.Method,
+		Payload:   row.Payload,
+		ExpiresAt: row.ExpiresAt,
+	}
+	if row.ExternalID != nil {
+		entry.ExternalID = *row.ExternalID
+	}
+	return entry, true, nil
+}
+
+// Set upserts entry and, at most once per storeCleanupInterval, deletes expired rows.
+func (s *PostgresStore) Set(ctx context.Context, entry Entry) error {
+	if err := s.q.UpsertMetadataCacheEntry(ctx, sqlc.UpsertMetadataCacheEntryParams{
+		CacheKey:   entry.Key,
+		Provider:   entry.Provider,
+		Method:     entry.Method,
+		ExternalID: optionalString(entry.ExternalID),
+		Payload:    entry.Payload,
+		ExpiresAt:  entry.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+
+	if s.cleanupDue(time.Now()) {
+		if _, err := s.q.DeleteExpiredMetadataCacheEntries(ctx); err != nil {
+			log.Printf("metadata cache: delete expired entries: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, provider string, externalID string) (int64, error) {
+	return s.q.DeleteMetadataCacheEntries(ctx, sqlc.DeleteMetadataCacheEntriesParams{
+		Provider:   provider,
+		ExternalID: optionalString(externalID),
+	})
+}
+
+func (s *PostgresStore) cleanupDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastCleanup) < storeCleanupInterval {
+		return false
+	}
+	s.lastCleanup = now
+	return true
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	metadata "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	HeaderName = "X-Cache"
+	HeaderHit  = "HIT"
+	HeaderMiss = "MISS"
+)
+
+const (
+	methodSearch   = "search"
+	methodDiscover = "discover"
+	methodShow     = "show"
+	methodEpisodes = "episodes"
+	methodRelated  = "related"
+)
+
+const (
+	defaultSearchTTL   = 10 * time.Minute
+	defaultDiscoverTTL = 10 * time.Minute
+	defaultShowTTL     = 6 * time.Hour
+	defaultEpisodesTTL = 6 * time.Hour
+	defaultMaxEntries  = 2000
+
+	// sharedFetchTimeout bounds a provider call made on behalf of every caller waiting on
+	// the same key, since no single caller's deadline applies to it.
+	sharedFetchTimeout = time.Minute
+
+	// storeCleanupInterval is how often the Postgres tier deletes its expired rows.
+	storeCleanupInterval = time.Hour
+)
+
+type ctxKey int
+
+const (
+	ctxStatusKey ctxKey = iota
+	ctxBypassKey
+)
+
+type Options struct {
+	SearchTTL   time.Duration
+	DiscoverTTL time.Duration
+	ShowTTL     time.Duration
+	EpisodesTTL time.Duration
+	// MaxEntries bounds the in-memory tier; the least recently used entry is evicted first.
+	MaxEntries int
+	// Store is the optional shared second tier. Nil keeps the cache in memory only.
+	Store Store
+}
+
+// Cache holds provider responses for every wrapped provider. Values are kept as JSON so
+// each caller gets its own copy.
+type Cache struct {
+	opts  Options
+	lru   *lru
+	store Store
+	group singleflight.Group
+}
+
+// Provider is a metadata.Provider that answers from the cache before calling next.
+type Provider struct {
+	cache *Cache
+	name  metadata.ProviderName
+	next  metadata.Provider
+}
+
+// Entry is one cached provider response.
+type Entry struct {
+	Key        string
+	Provider   string
+	Method     string
+	ExternalID string
+	Payload    []byte
+	ExpiresAt  time.Time
+}
+
+// Store is a cache tier shared between API instances.
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	Set(ctx context.Context, entry Entry) error
+	// Delete removes a provider's entries, or only those for externalID when it is set.
+	Delete(ctx context.Context, provider string, externalID string) (int64, error)
+}
+
+type PostgresStore struct {
+	q *sqlc.Queries
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+type lru struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+}
+
+// Status counts cache hits and misses for the provider calls made under one context.
+type Status struct {
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds every adapter reports through, so callers can tell a missing show from a
+// bad request or an upstream outage without reading messages. Test with errors.Is.
+var (
+	ErrNotFound            = errors.New("metadata not found")
+	ErrUnsupported         = errors.New("metadata operation not supported")
+	ErrInvalidExternalID   = errors.New("invalid external id")
+	ErrUpstreamUnavailable = errors.New("metadata provider unavailable")
+	ErrRateLimited         = errors.New("metadata provider rate limited")
+)
+
+// Errorf returns an error of the given kind with a formatted message. A %w verb in
+// format keeps the underlying error reachable as well.
+func Errorf(kind error, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
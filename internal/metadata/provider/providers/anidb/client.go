@@ -0,0 +1,198 @@
+package anidb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+)
+
+// fetchAPI returns the XML body of an HTTP API request, served from the disk cache
+// while it is younger than the cache TTL. AniDB answers each request with a document
+// named after it, e.g. <anime> for request=anime, and only that document is cached.
+func (p *Provider) fetchAPI(ctx context.Context, cacheName string, params url.Values) ([]byte, error) {
+	if p.client == "" {
+		return nil, metadata.Errorf(metadata.ErrUnsupported, "anidb provider is not configured: missing ANIDB_CLIENT")
+	}
+
+	params.Set("client", p.client)
+	params.Set("clientver", p.clientVersion)
+	params.Set("protover", "1")
+
+	root := params.Get("request")
+	return p.fetchCached(ctx, cacheName, p.cacheTTL, p.baseURL+"?"+params.Encode(), func(body []byte) error {
+		return checkAPIResponse(body, root)
+	})
+}
+
+// fetchCached reads cacheName from disk when fresh, otherwise downloads it through the
+// throttle. A failed download falls back to a stale copy so a ban or outage does not
+// take the provider down.
+func (p *Provider) fetchCached(ctx context.Context, cacheName string, ttl time.Duration, endpoint string, validate func([]byte) error) ([]byte, error) {
+	path := filepath.Join(p.cacheDir, cacheName)
+	cached, modTime, cacheErr := readCache(path)
+	if cacheErr == nil && time.Since(modTime) < ttl {
+		return cached, nil
+	}
+
+	body, err := p.download(ctx, endpoint)
+	if err == nil {
+		err = validate(body)
+	}
+	if err != nil {
+		if cacheErr == nil && ctx.Err() == nil {
+			log.Printf("anidb: serving stale %s: %v", cacheName, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if err := writeCache(path, body); err != nil {
+		log.Printf("anidb: failed to cache %s: %v", cacheName, err)
+	}
+	return body, nil
+}
+
+// download sends through the provider transport, whose limiter holds every request,
+// retries included, to AniDB's one request every two seconds; AniDB bans clients that
+// send more.
+func (p *Provider) download(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("User-Agent", p.client+"/"+p.clientVersion)
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, metadata.Errorf(metadata.ErrUpstreamUnavailable, "anidb request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, metadata.Errorf(metadata.ErrUpstreamUnavailable, "anidb request failed with status %d", res.StatusCode)
+	}
+	return gunzip(body)
+}
+
+// gunzip decompresses gzip bodies. AniDB always compresses, whether or not it sets
+// Content-Encoding, so the magic bytes are checked instead of the header.
+func gunzip(body []byte) ([]byte, error) {
+	if len(body) < 2 || body[0] != 0x1f || body[1] != 0x8b {
+		return body, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// checkAPIResponse accepts only a document whose root element is root, so an HTML page or
+// a truncated body never reaches the cache. It reports AniDB's in-band <error> responses,
+// which arrive with status 200. AniDB has no error code for every case, so the kind is
+// read from the message: a missing anime is not found and a flood ban is rate limiting.
+func checkAPIResponse(body []byte, root string) error {
+	name, err := rootElement(body)
+	if err != nil {
+		return metadata.Errorf(metadata.ErrUpstreamUnavailable, "anidb returned an unreadable response: %v", err)
+	}
+	if name == root {
+		return nil
+	}
+	if name != "error" {
+		return metadata.Errorf(metadata.ErrUpstreamUnavailable, "anidb returned <%s> instead of <%s>", name, root)
+	}
+
+	var apiErr errorXML
+	if err := xml.Unmarshal(body, &apiErr); err != nil {
+		return metadata.Errorf(metadata.ErrUpstreamUnavailable, "anidb returned an unreadable error: %v", err)
+	}
+
+	kind := metadata.ErrUpstreamUnavailable
+	switch message := strings.ToLower(apiErr.Message); {
+	case strings.Contains(message, "not found"), strings.Contains(message, "no such"):
+		kind = metadata.ErrNotFound
+	case strings.Contains(message, "banned"):
+		kind = metadata.ErrRateLimited
+	}
+	if apiErr.Code != "" {
+		return metadata.Errorf(kind, "anidb error %s: %s", apiErr.Code, apiErr.Message)
+	}
+	return metadata.Errorf(kind, "anidb error: %s", apiErr.Message)
+}
+
+// rootElement returns the name of the document's first element.
+func rootElement(body []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func checkNotEmpty(body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return metadata.Errorf(metadata.ErrUpstreamUnavailable, "anidb returned an empty response")
+	}
+	return nil
+}
+
+func readCache(path string) ([]byte, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return body, info.ModTime(), nil
+}
+
+// writeCache replaces path atomically so concurrent readers never see a partial file.
+func writeCache(path string, body []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func animeCacheName(id int64) string {
+	return "anime-" + strconv.FormatInt(id, 10) + ".xml"
+}
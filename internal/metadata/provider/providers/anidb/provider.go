@@ -0,0 +1,542 @@
+package anidb
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/transport"
+	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+)
+
+const (
+	defaultBaseURL       = "http://api.anidb.net:9001/httpapi"
+	defaultTitlesURL     = "https://anidb.net/api/anime-titles.xml.gz"
+	defaultImageBaseURL  = "https://cdn-eu.anidb.net/images/main/"
+	defaultClientVersion = "1"
+	defaultCacheTTL      = 24 * time.Hour
+	defaultTimeout       = 30 * time.Second
+	defaultPage          = 1
+	defaultPageSize      = 10
+	maxPageSize          = 100
+	idPrefix             = "anidb:"
+
+	// requestInterval is AniDB's flood limit: one request every two seconds per client.
+	requestInterval = 2 * time.Second
+	titlesTTL       = 24 * time.Hour
+	titlesCacheName = "anime-titles.xml"
+	hotCacheName    = "hotanime.xml"
+
+	epTypeRegular = 1
+	epTypeSpecial = 2
+	epTypeCredit  = 3
+	epTypeTrailer = 4
+	epTypeParody  = 5
+	epTypeOther   = 6
+
+	resourceMAL  = 2
+	resourceIMDB = 43
+	resourceTMDB = 44
+)
+
+var (
+	fullDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	// descriptionLinkPattern matches AniDB's inline links, e.g. "http://anidb.net/ch123 [Name]".
+	descriptionLinkPattern = regexp.MustCompile(`https?://anidb\.net/\S+ \[([^\]]+)\]`)
+)
+
+func New() *Provider {
+	upstream := transport.New("anidb", transport.Options{
+		RequestsPerMinute: int(time.Minute / requestInterval),
+	})
+	return &Provider{
+		baseURL:       getEnv("ANIDB_BASE_URL", defaultBaseURL),
+		titlesURL:     getEnv("ANIDB_TITLES_URL", defaultTitlesURL),
+		imageBaseURL:  strings.TrimRight(getEnv("ANIDB_IMAGE_BASE_URL", defaultImageBaseURL), "/") + "/",
+		client:        strings.TrimSpace(os.Getenv("ANIDB_CLIENT")),
+		clientVersion: getEnv("ANIDB_CLIENT_VERSION", defaultClientVersion),
+		cacheDir:      getEnv("ANIDB_CACHE_DIR", filepath.Join(os.TempDir(), "anidb-cache")),
+		cacheTTL:      getEnvDuration("ANIDB_CACHE_TTL", defaultCacheTTL),
+		httpClient:    upstream.Client(defaultTimeout),
+		upstream:      upstream,
+	}
+}
+
+// Capabilities: search reads the public title dump and works without ANIDB_CLIENT; the
+// other operations use the HTTP API and need it.
+func (p *Provider) Capabilities() metadata.Capabilities {
+	capabilities := metadata.Capabilities{
+		IDPrefix:      idPrefix,
+		Search:        true,
+		Discover:      true,
+		GetShow:       true,
+		ListEpisodes:  true,
+		SeasonFilter:  true,
+		EpisodeTitles: true,
+		Configured:    p.client != "",
+	}
+	if !capabilities.Configured {
+		capabilities.ConfigError = "missing ANIDB_CLIENT"
+	}
+	return capabilities
+}
+
+func (p *Provider) Health() metadata.Health {
+	return p.upstream.Health()
+}
+
+// Search matches against the offline title dump, so it costs no API requests.
+func (p *Provider) Search(ctx context.Context, query string, opts metadata.SearchOpts) ([]metadata.SearchHit, error) {
+	if strings.TrimSpace(query) == "" {
+		return []metadata.SearchHit{}, nil
+	}
+
+	entries, err := p.titleIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := normalizeutil.Page(opts.Page, defaultPage)
+	limit := normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)
+	matches := paginate(searchTitles(entries, query), page, limit)
+
+	hits := make([]metadata.SearchHit, 0, len(matches))
+	for _, entry := range matches {
+		titlePreferred, titleOriginal := pickTitles(entry.Titles)
+		hits = append(hits, metadata.SearchHit{
+			ExternalID:     formatExternalID(entry.ID),
+			TitlePreferred: titlePreferred,
+			TitleOriginal:  titleOriginal,
+			AltTitles:      buildAltTitles(titlePreferred, titleOriginal, entry.Titles),
+			Type:           "anime",
+			Status:         showmodel.StatusOngoing,
+		})
+	}
+	return hits, nil
+}
+
+// Discover derives every section from the hot anime list, AniDB's only discovery feed.
+func (p *Provider) Discover(ctx context.Context, opts metadata.DiscoverOpts) (metadata.DiscoverResult, error) {
+	params := url.Values{}
+	params.Set("request", "hotanime")
+	body, err := p.fetchAPI(ctx, hotCacheName, params)
+	if err != nil {
+		return metadata.DiscoverResult{}, err
+	}
+
+	var hot hotAnimeXML
+	if err := xml.Unmarshal(body, &hot); err != nil {
+		return metadata.DiscoverResult{}, err
+	}
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	upcoming := make([]hotAnimeItemXML, 0)
+	airing := make([]hotAnimeItemXML, 0)
+	for _, item := range hot.Anime {
+		switch {
+		case item.StartDate == "" || item.StartDate > today:
+			upcoming = append(upcoming, item)
+		case !hasEnded(item.EndDate, today):
+			airing = append(airing, item)
+		}
+	}
+
+	popular := append([]hotAnimeItemXML(nil), hot.Anime...)
+	sort.SliceStable(popular, func(i, j int) bool {
+		return popular[i].Ratings.Permanent.Count > popular[j].Ratings.Permanent.Count
+	})
+	topRated := append([]hotAnimeItemXML(nil), hot.Anime...)
+	sort.SliceStable(topRated, func(i, j int) bool {
+		return topRated[i].Ratings.Permanent.Value > topRated[j].Ratings.Permanent.Value
+	})
+
+	page := normalizeutil.Page(opts.Page, defaultPage)
+	limit := normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)
+	return metadata.DiscoverResult{
+		Trending:        p.mapHotList(paginate(hot.Anime, page, limit), today),
+		Popular:         p.mapHotList(paginate(popular, page, limit), today),
+		TopRated:        p.mapHotList(paginate(topRated, page, limit), today),
+		Upcoming:        p.mapHotList(paginate(upcoming, page, limit), today),
+		CurrentlyAiring: p.mapHotList(paginate(airing, page, limit), today),
+	}, nil
+}
+
+func (p *Provider) GetShow(ctx context.Context, externalID string) (metadata.Show, error) {
+	anime, err := p.anime(ctx, externalID)
+	if err != nil {
+		return metadata.Show{}, err
+	}
+
+	titlePreferred, titleOriginal := pickTitles(anime.Titles)
+	posterURL := p.pictureURL(anime.Picture)
+	var episodeCount *int64
+	if anime.EpisodeCount > 0 {
+		episodeCount = &anime.EpisodeCount
+	}
+
+	return metadata.Show{
+		ExternalID:     formatExternalID(anime.ID),
+		TitlePreferred: titlePreferred,
+		TitleOriginal:  titleOriginal,
+		AltTitles:      buildAltTitles(titlePreferred, titleOriginal, anime.Titles),
+		Type:           mapShowType(anime.Type),
+		Status:         animeStatus(anime.EndDate, time.Now().UTC().Format(time.DateOnly)),
+		Synopsis:       cleanDescription(anime.Description),
+		StartDate:      fullDate(anime.StartDate),
+		EndDate:        fullDate(anime.EndDate),
+		PosterUrl:      posterURL,
+		BannerUrl:      posterURL,
+		EpisodeCount:   episodeCount,
+		LinkedIDs:      linkedIDs(anime.Resources),
+	}, nil
+}
+
+// ListEpisodes returns regular episodes as season 1 and everything else as season 0.
+// Credits, trailers, parodies and other extras are numbered from 100, 200, 300 and 400
+// so they never collide with specials.
+func (p *Provider) ListEpisodes(ctx context.Context, externalID string, opts metadata.ListEpisodesOpts) ([]metadata.Episode, error) {
+	anime, err := p.anime(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	episodes := make([]metadata.Episode, 0, len(anime.Episodes))
+	for _, item := range anime.Episodes {
+		episode, ok := mapEpisode(item)
+		if !ok {
+			continue
+		}
+		if opts.SeasonNumber != nil && episode.SeasonNumber != *opts.SeasonNumber {
+			continue
+		}
+		episodes = append(episodes, episode)
+	}
+
+	sort.Slice(episodes, func(i, j int) bool {
+		if episodes[i].SeasonNumber != episodes[j].SeasonNumber {
+			return episodes[i].SeasonNumber < episodes[j].SeasonNumber
+		}
+		return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber
+	})
+
+	page := normalizeutil.Page(opts.Page, defaultPage)
+	limit := normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)
+	return paginate(episodes, page, limit), nil
+}
+
+func (p *Provider) anime(ctx context.Context, externalID string) (animeXML, error) {
+	id, err := parseExternalID(externalID)
+	if err != nil {
+		return animeXML{}, err
+	}
+
+	params := url.Values{}
+	params.Set("request", "anime")
+	params.Set("aid", strconv.FormatInt(id, 10))
+	body, err := p.fetchAPI(ctx, animeCacheName(id), params)
+	if err != nil {
+		return animeXML{}, err
+	}
+
+	var anime animeXML
+	if err := xml.Unmarshal(body, &anime); err != nil {
+		return animeXML{}, err
+	}
+	if anime.ID == 0 {
+		return animeXML{}, metadata.Errorf(metadata.ErrNotFound, "anidb show %s not found", externalID)
+	}
+	return anime, nil
+}
+
+func (p *Provider) mapHotList(items []hotAnimeItemXML, today string) []metadata.Show {
+	mapped := make([]metadata.Show, 0, len(items))
+	for _, item := range items {
+		titlePreferred, titleOriginal := pickTitles(item.Titles)
+		posterURL := p.pictureURL(item.Picture)
+		var episodeCount *int64
+		if item.EpisodeCount > 0 {
+			episodeCount = &item.EpisodeCount
+		}
+
+		mapped = append(mapped, metadata.Show{
+			ExternalID:     formatExternalID(item.ID),
+			TitlePreferred: titlePreferred,
+			TitleOriginal:  titleOriginal,
+			AltTitles:      buildAltTitles(titlePreferred, titleOriginal, item.Titles),
+			Type:           "anime",
+			Status:         animeStatus(item.EndDate, today),
+			StartDate:      fullDate(item.StartDate),
+			EndDate:        fullDate(item.EndDate),
+			PosterUrl:      posterURL,
+			BannerUrl:      posterURL,
+			EpisodeCount:   episodeCount,
+		})
+	}
+	return mapped
+}
+
+// linkedIDs keeps the external resources AniDB lists that a show can be linked under;
+// the first identifier of each resource is the id at that site.
+func linkedIDs(items []resourceXML) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		var provider string
+		switch item.Type {
+		case resourceMAL:
+			provider = showmodel.ExternalProviderMAL
+		case resourceIMDB:
+			provider = showmodel.ExternalProviderIMDB
+		case resourceTMDB:
+			provider = showmodel.ExternalProviderTMDB
+		default:
+			continue
+		}
+		if len(item.Identifiers) == 0 || strings.TrimSpace(item.Identifiers[0]) == "" {
+			continue
+		}
+		out = append(out, provider+":"+strings.TrimSpace(item.Identifiers[0]))
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func (p *Provider) pictureURL(picture string) *string {
+	picture = strings.TrimSpace(picture)
+	if picture == "" {
+		return nil
+	}
+	return normalizeutil.StringValuePtr(p.imageBaseURL + picture)
+}
+
+func mapEpisode(item episodeXML) (metadata.Episode, bool) {
+	number, err := strconv.ParseInt(strings.TrimLeft(strings.TrimSpace(item.EpNo.Value), "SCTPOsctpo"), 10, 64)
+	if err != nil || number <= 0 {
+		return metadata.Episode{}, false
+	}
+
+	seasonNumber := int64(0)
+	episodeType := ""
+	switch item.EpNo.Type {
+	case epTypeRegular:
+		seasonNumber = 1
+		episodeType = metadata.EpisodeTypeRegular
+	case epTypeSpecial:
+		episodeType = metadata.EpisodeTypeSpecial
+	case epTypeCredit:
+		number += 100
+		episodeType = creditType(item.Titles)
+	case epTypeTrailer:
+		number += 200
+		episodeType = metadata.EpisodeTypeTrailer
+	case epTypeParody:
+		number += 300
+		episodeType = metadata.EpisodeTypeParody
+	case epTypeOther:
+		number += 400
+		episodeType = metadata.EpisodeTypeOther
+	default:
+		return metadata.Episode{}, false
+	}
+
+	title := episodeTitle(item.Titles)
+	if title == "" {
+		title = fmt.Sprintf("Episode %s", strings.TrimSpace(item.EpNo.Value))
+	}
+
+	var runtime *int64
+	if item.Length > 0 {
+		runtime = &item.Length
+	}
+
+	return metadata.Episode{
+		Provider:       metadata.ProviderAniDB,
+		ExternalID:     formatExternalID(item.ID),
+		SeasonNumber:   seasonNumber,
+		EpisodeNumber:  number,
+		Title:          title,
+		Type:           episodeType,
+		AirDate:        fullDate(item.AirDate),
+		RuntimeMinutes: runtime,
+	}, true
+}
+
+// creditType tells openings from endings; AniDB files both as credits and only the
+// title says which is which.
+func creditType(titles []titleXML) string {
+	for _, title := range titles {
+		value := strings.ToLower(title.Value)
+		switch {
+		case strings.Contains(value, "opening"):
+			return metadata.EpisodeTypeOpening
+		case strings.Contains(value, "ending"):
+			return metadata.EpisodeTypeEnding
+		}
+	}
+	return metadata.EpisodeTypeCredit
+}
+
+func episodeTitle(titles []titleXML) string {
+	for _, lang := range []string{"en", "x-jat", "ja"} {
+		for _, title := range titles {
+			if title.Lang == lang && strings.TrimSpace(title.Value) != "" {
+				return strings.TrimSpace(title.Value)
+			}
+		}
+	}
+	for _, title := range titles {
+		if strings.TrimSpace(title.Value) != "" {
+			return strings.TrimSpace(title.Value)
+		}
+	}
+	return ""
+}
+
+func pickTitles(titles []titleXML) (string, *string) {
+	main := findTitle(titles, "main", "")
+	preferred := firstNonEmpty(findTitle(titles, "official", "en"), main)
+	if preferred == "" {
+		preferred = "Untitled"
+	}
+	return preferred, normalizeutil.StringValuePtr(firstNonEmpty(findTitle(titles, "official", "ja"), main))
+}
+
+func findTitle(titles []titleXML, titleType string, lang string) string {
+	for _, title := range titles {
+		if title.Type != titleType {
+			continue
+		}
+		if lang != "" && title.Lang != lang {
+			continue
+		}
+		if value := strings.TrimSpace(title.Value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// buildAltTitles keeps titles in English, romaji and Japanese; the full multilingual
+// list runs to dozens of entries for popular shows.
+func buildAltTitles(preferred string, original *string, titles []titleXML) []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0)
+	for _, title := range titles {
+		if title.Lang != "en" && title.Lang != "x-jat" && title.Lang != "ja" {
+			continue
+		}
+		normalized := strings.TrimSpace(title.Value)
+		if normalized == "" || strings.EqualFold(normalized, preferred) {
+			continue
+		}
+		if original != nil && strings.EqualFold(normalized, *original) {
+			continue
+		}
+		key := strings.ToLower(normalized)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, normalized)
+	}
+	return out
+}
+
+func animeStatus(endDate string, today string) string {
+	if hasEnded(endDate, today) {
+		return showmodel.StatusFinished
+	}
+	return showmodel.StatusOngoing
+}
+
+// hasEnded compares possibly partial AniDB dates ("2010", "2010-04") against today.
+func hasEnded(endDate string, today string) bool {
+	endDate = strings.TrimSpace(endDate)
+	if endDate == "" || len(endDate) > len(today) {
+		return false
+	}
+	return endDate <= today[:len(endDate)]
+}
+
+func fullDate(value string) *string {
+	value = strings.TrimSpace(value)
+	if !fullDatePattern.MatchString(value) {
+		return nil
+	}
+	return &value
+}
+
+func cleanDescription(value string) *string {
+	return normalizeutil.StringValuePtr(descriptionLinkPattern.ReplaceAllString(value, "$1"))
+}
+
+func mapShowType(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "movie":
+		return "movie"
+	case "ova":
+		return "ova"
+	case "tv special":
+		return "special"
+	default:
+		return "anime"
+	}
+}
+
+func paginate[T any](items []T, page int, limit int) []T {
+	start := (page - 1) * limit
+	if start >= len(items) {
+		return []T{}
+	}
+	end := min(start+limit, len(items))
+	return items[start:end]
+}
+
+func parseExternalID(value string) (int64, error) {
+	normalized := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), idPrefix)
+	id, err := strconv.ParseInt(strings.TrimSpace(normalized), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, metadata.Errorf(metadata.ErrInvalidExternalID, "anidb external id must be a positive integer")
+	}
+	return id, nil
+}
+
+func formatExternalID(id int64) string {
+	return idPrefix + strconv.FormatInt(id, 10)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+func getEnv(key, fallback string) string {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	parsed, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
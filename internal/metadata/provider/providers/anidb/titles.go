@@ -0,0 +1,90 @@
+package anidb
+
+import (
+	"context"
+	"encoding/xml"
+	"sort"
+	"strings"
+	"time"
+
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+)
+
+// titleIndex returns the parsed offline title dump with its titles normalized for search.
+// AniDB allows downloading the dump at most once a day, so it shares the disk cache and
+// is re-parsed only when stale.
+func (p *Provider) titleIndex(ctx context.Context) ([]titleEntry, error) {
+	p.titlesMu.Lock()
+	defer p.titlesMu.Unlock()
+
+	if p.titles != nil && time.Since(p.titlesLoadedAt) < titlesTTL {
+		return p.titles, nil
+	}
+
+	body, err := p.fetchCached(ctx, titlesCacheName, titlesTTL, p.titlesURL, checkNotEmpty)
+	if err != nil {
+		return nil, err
+	}
+
+	var dump titleDumpXML
+	if err := xml.Unmarshal(body, &dump); err != nil {
+		return nil, err
+	}
+
+	for i := range dump.Anime {
+		entry := &dump.Anime[i]
+		entry.normalized = make([]string, 0, len(entry.Titles))
+		for _, title := range entry.Titles {
+			entry.normalized = append(entry.normalized, normalizeutil.LowerString(title.Value))
+		}
+	}
+	p.titles = dump.Anime
+	p.titlesLoadedAt = time.Now()
+	return p.titles, nil
+}
+
+// searchTitles ranks anime whose titles contain query: exact title matches first, then
+// prefix matches, then substring matches, ties broken by anime id.
+func searchTitles(entries []titleEntry, query string) []titleEntry {
+	normalizedQuery := normalizeutil.LowerString(query)
+	if normalizedQuery == "" {
+		return []titleEntry{}
+	}
+
+	matches := make([]searchMatch, 0)
+	for _, entry := range entries {
+		best := 0
+		for _, title := range entry.normalized {
+			best = max(best, titleScore(title, normalizedQuery))
+		}
+		if best > 0 {
+			matches = append(matches, searchMatch{entry: entry, score: best})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry.ID < matches[j].entry.ID
+	})
+
+	out := make([]titleEntry, 0, len(matches))
+	for _, match := range matches {
+		out = append(out, match.entry)
+	}
+	return out
+}
+
+func titleScore(title string, query string) int {
+	switch {
+	case title == query:
+		return 3
+	case strings.HasPrefix(title, query):
+		return 2
+	case strings.Contains(title, query):
+		return 1
+	default:
+		return 0
+	}
+}
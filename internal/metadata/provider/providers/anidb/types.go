@@ -0,0 +1,111 @@
+package anidb
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/transport"
+)
+
+type Provider struct {
+	baseURL       string
+	titlesURL     string
+	imageBaseURL  string
+	client        string
+	clientVersion string
+	cacheDir      string
+	cacheTTL      time.Duration
+	httpClient    *http.Client
+	upstream      *transport.Transport
+
+	titlesMu       sync.Mutex
+	titles         []titleEntry
+	titlesLoadedAt time.Time
+}
+
+type titleXML struct {
+	Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type animeXML struct {
+	XMLName      xml.Name      `xml:"anime"`
+	ID           int64         `xml:"id,attr"`
+	Type         string        `xml:"type"`
+	EpisodeCount int64         `xml:"episodecount"`
+	StartDate    string        `xml:"startdate"`
+	EndDate      string        `xml:"enddate"`
+	Titles       []titleXML    `xml:"titles>title"`
+	Description  string        `xml:"description"`
+	Picture      string        `xml:"picture"`
+	Episodes     []episodeXML  `xml:"episodes>episode"`
+	Resources    []resourceXML `xml:"resources>resource"`
+}
+
+type resourceXML struct {
+	Type        int      `xml:"type,attr"`
+	Identifiers []string `xml:"externalentity>identifier"`
+}
+
+type episodeXML struct {
+	ID      int64      `xml:"id,attr"`
+	EpNo    epNoXML    `xml:"epno"`
+	Length  int64      `xml:"length"`
+	AirDate string     `xml:"airdate"`
+	Titles  []titleXML `xml:"title"`
+}
+
+type epNoXML struct {
+	Type  int    `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type hotAnimeXML struct {
+	XMLName xml.Name          `xml:"hotanime"`
+	Anime   []hotAnimeItemXML `xml:"anime"`
+}
+
+type hotAnimeItemXML struct {
+	ID           int64      `xml:"id,attr"`
+	EpisodeCount int64      `xml:"episodecount"`
+	StartDate    string     `xml:"startdate"`
+	EndDate      string     `xml:"enddate"`
+	Titles       []titleXML `xml:"title"`
+	Picture      string     `xml:"picture"`
+	Ratings      ratingsXML `xml:"ratings"`
+}
+
+type ratingsXML struct {
+	Permanent ratingXML `xml:"permanent"`
+}
+
+type ratingXML struct {
+	Count int64   `xml:"count,attr"`
+	Value float64 `xml:",chardata"`
+}
+
+type errorXML struct {
+	XMLName xml.Name `xml:"error"`
+	Code    string   `xml:"code,attr"`
+	Message string   `xml:",chardata"`
+}
+
+type titleDumpXML struct {
+	Anime []titleEntry `xml:"anime"`
+}
+
+// titleEntry is one anime of the offline title dump. normalized holds its titles lower
+// cased for search, filled once when the dump is loaded.
+type titleEntry struct {
+	ID         int64      `xml:"aid,attr"`
+	Titles     []titleXML `xml:"title"`
+	normalized []string
+}
+
+type searchMatch struct {
+	entry titleEntry
+	score int
+}
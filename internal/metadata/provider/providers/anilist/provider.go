@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/transport"
 	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
 	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
 )
@@ -22,10 +23,15 @@ const (
 	defaultPageSize = 10
 	maxPageSize     = 50
 	idPrefix        = "anilist:"
-	searchQuery     = `query ($query: String!, $page: Int!, $perPage: Int!) {
+	defaultTimeout  = 15 * time.Second
+	// requestsPerMinute is AniList's documented rate limit.
+	requestsPerMinute = 90
+	requestBurst      = 5
+	searchQuery       = `query ($query: String!, $page: Int!, $perPage: Int!) {
   Page(page: $page, perPage: $perPage) {
     media(search: $query, type: ANIME, sort: SEARCH_MATCH) {
       id
+      idMal
       type
       status
       averageScore
@@ -44,6 +50,7 @@ const (
   trending: Page(page: $page, perPage: $perPage) {
     media(type: ANIME, sort: TRENDING_DESC) {
       id
+      idMal
       type
       status
       description(asHtml: false)
@@ -73,6 +80,7 @@ const (
   popular: Page(page: $page, perPage: $perPage) {
     media(type: ANIME, sort: POPULARITY_DESC) {
       id
+      idMal
       type
       status
       description(asHtml: false)
@@ -102,6 +110,7 @@ const (
   topRated: Page(page: $page, perPage: $perPage) {
     media(type: ANIME, sort: SCORE_DESC) {
       id
+      idMal
       type
       status
       description(asHtml: false)
@@ -131,6 +140,7 @@ const (
   upcoming: Page(page: $page, perPage: $perPage) {
     media(type: ANIME, status: NOT_YET_RELEASED, sort: POPULARITY_DESC) {
       id
+      idMal
       type
       status
       description(asHtml: false)
@@ -160,6 +170,7 @@ const (
   currentlyAiring: Page(page: $page, perPage: $currentlyPerPage) {
     media(type: ANIME, status: RELEASING, sort: POPULARITY_DESC) {
       id
+      idMal
       type
       status
       description(asHtml: false)
@@ -190,6 +201,7 @@ const (
 	showQuery = `query ($id: Int!) {
   Media(id: $id, type: ANIME) {
     id
+    idMal
     status
     description(asHtml: false)
     bannerImage
@@ -213,6 +225,49 @@ const (
       native
     }
   }
+}`
+	relationsQuery = `query ($id: Int!) {
+  Media(id: $id, type: ANIME) {
+    ...media
+    relations {
+      edges {
+        relationType(version: 2)
+        node {
+          ...media
+        }
+      }
+    }
+  }
+}
+
+fragment media on Media {
+  id
+  idMal
+  type
+  format
+  status
+  description(asHtml: false)
+  bannerImage
+  synonyms
+  episodes
+  coverImage {
+    large
+  }
+  startDate {
+    year
+    month
+    day
+  }
+  endDate {
+    year
+    month
+    day
+  }
+  title {
+    romaji
+    english
+    native
+  }
 }`
 	episodesQuery = `query ($mediaId: Int!, $page: Int!, $perPage: Int!) {
   Page(page: $page, perPage: $perPage) {
@@ -225,14 +280,35 @@ const (
 )
 
 func New() *Provider {
+	upstream := transport.New("anilist", transport.Options{
+		RequestsPerMinute: requestsPerMinute,
+		Burst:             requestBurst,
+	})
 	return &Provider{
 		endpoint: defaultEndpoint,
-		client: &http.Client{
-			Timeout: 15 * time.Second,
-		},
+		client:   upstream.Client(defaultTimeout),
+		upstream: upstream,
+	}
+}
+
+// Capabilities: AniList has airing schedules but no episode titles, and every entry is a
+// single season, so SeasonNumber only relabels the episodes.
+func (p *Provider) Capabilities() metadata.Capabilities {
+	return metadata.Capabilities{
+		IDPrefix:     idPrefix,
+		Search:       true,
+		Discover:     true,
+		GetShow:      true,
+		ListEpisodes: true,
+		Related:      true,
+		Configured:   true,
 	}
 }
 
+func (p *Provider) Health() metadata.Health {
+	return p.upstream.Health()
+}
+
 func (p *Provider) Search(ctx context.Context, query string, opts metadata.SearchOpts) ([]metadata.SearchHit, error) {
 	if strings.TrimSpace(query) == "" {
 		return []metadata.SearchHit{}, nil
@@ -306,7 +382,7 @@ func (p *Provider) GetShow(ctx context.Context, externalID string) (metadata.Sho
 	}
 
 	if response.Data.Media.ID == 0 {
-		return metadata.Show{}, fmt.Errorf("anilist show %s not found", externalID)
+		return metadata.Show{}, metadata.Errorf(metadata.ErrNotFound, "anilist show %s not found", externalID)
 	}
 
 	titlePreferred, titleOriginal := pickTitles(response.Data.Media.Title)
@@ -328,6 +404,7 @@ func (p *Provider) GetShow(ctx context.Context, externalID string) (metadata.Sho
 		BannerUrl:      bannerURL,
 		Type:           "anime",
 		Status:         showmodel.NormalizeStatusOrDefault(response.Data.Media.Status, showmodel.StatusOngoing),
+		LinkedIDs:      linkedIDs(response.Data.Media.IDMal),
 	}, nil
 }
 
@@ -366,6 +443,7 @@ func (p *Provider) ListEpisodes(ctx context.Context, externalID string, opts met
 			SeasonNumber:  seasonNumber,
 			EpisodeNumber: item.Episode,
 			Title:         title,
+			Type:          metadata.EpisodeTypeRegular,
 			AirDate:       airDate,
 		})
 	}
@@ -373,6 +451,55 @@ func (p *Provider) ListEpisodes(ctx context.Context, externalID string, opts met
 	return episodes, nil
 }
 
+// ListRelations returns the show's anime relations that make up its franchise: sequels,
+// prequels, side stories, parents, spin-offs, alternatives, summaries and any movie.
+// Manga and novel sources and character crossovers are left out.
+func (p *Provider) ListRelations(ctx context.Context, externalID string) (metadata.ShowRelations, error) {
+	mediaID, err := parseExternalID(externalID)
+	if err != nil {
+		return metadata.ShowRelations{}, err
+	}
+
+	request := graphQLRequest{
+		Query: relationsQuery,
+		Variables: map[string]any{
+			"id": mediaID,
+		},
+	}
+
+	var response graphQLRelationsResponse
+	if err := p.execute(ctx, request, &response); err != nil {
+		return metadata.ShowRelations{}, err
+	}
+
+	media := response.Data.Media
+	if media.ID == 0 {
+		return metadata.ShowRelations{}, metadata.Errorf(metadata.ErrNotFound, "anilist show %s not found", externalID)
+	}
+
+	relations := make([]metadata.Relation, 0, len(media.Relations.Edges))
+	for _, edge := range media.Relations.Edges {
+		if !strings.EqualFold(edge.Node.Type, "ANIME") || edge.Node.ID == 0 {
+			continue
+		}
+		relationType, ok := mapAniListRelationType(edge.RelationType, edge.Node.Format)
+		if !ok {
+			continue
+		}
+		relations = append(relations, metadata.Relation{
+			Type:   relationType,
+			Show:   mapAniListMediaToShow(edge.Node),
+			Format: mapAniListFormat(edge.Node.Format),
+		})
+	}
+
+	return metadata.ShowRelations{
+		Show:      mapAniListMediaToShow(media.anilistMediaSummary),
+		Format:    mapAniListFormat(media.Format),
+		Relations: relations,
+	}, nil
+}
+
 func (p *Provider) execute(ctx context.Context, request graphQLRequest, target any) error {
 	payload, err := json.Marshal(request)
 	if err != nil {
@@ -388,7 +515,7 @@ func (p *Provider) execute(ctx context.Context, request graphQLRequest, target a
 
 	httpRes, err := p.client.Do(httpReq)
 	if err != nil {
-		return err
+		return metadata.Errorf(metadata.ErrUpstreamUnavailable, "anilist request failed: %w", err)
 	}
 	defer httpRes.Body.Close()
 
@@ -397,8 +524,12 @@ func (p *Provider) execute(ctx context.Context, request graphQLRequest, target a
 		return err
 	}
 
+	// AniList answers a missing Media with 404 and a "Not Found." GraphQL error.
+	if httpRes.StatusCode == http.StatusNotFound {
+		return metadata.Errorf(metadata.ErrNotFound, "anilist media not found")
+	}
 	if httpRes.StatusCode < 200 || httpRes.StatusCode >= 300 {
-		return fmt.Errorf("anilist request failed with status %d", httpRes.StatusCode)
+		return metadata.Errorf(metadata.ErrUpstreamUnavailable, "anilist request failed with status %d", httpRes.StatusCode)
 	}
 
 	if err := json.Unmarshal(body, target); err != nil {
@@ -414,6 +545,8 @@ func (p *Provider) execute(ctx context.Context, request graphQLRequest, target a
 		return firstGraphQLError(value.Errors)
 	case *graphQLEpisodesResponse:
 		return firstGraphQLError(value.Errors)
+	case *graphQLRelationsResponse:
+		return firstGraphQLError(value.Errors)
 	default:
 		return nil
 	}
@@ -423,7 +556,7 @@ func firstGraphQLError(items []graphQLError) error {
 	if len(items) == 0 {
 		return nil
 	}
-	return fmt.Errorf("anilist graphql error: %s", items[0].Message)
+	return metadata.Errorf(metadata.ErrUpstreamUnavailable, "anilist graphql error: %s", items[0].Message)
 }
 
 func parseExternalID(value string) (int64, error) {
@@ -431,7 +564,7 @@ func parseExternalID(value string) (int64, error) {
 	normalized = strings.TrimPrefix(strings.ToLower(normalized), idPrefix)
 	id, err := strconv.ParseInt(strings.TrimSpace(normalized), 10, 64)
 	if err != nil || id <= 0 {
-		return 0, fmt.Errorf("anilist external id must be a positive integer")
+		return 0, metadata.Errorf(metadata.ErrInvalidExternalID, "anilist external id must be a positive integer")
 	}
 	return id, nil
 }
@@ -494,6 +627,37 @@ func mapAniListType(value string) string {
 	return strings.ToLower(strings.TrimSpace(value))
 }
 
+// mapAniListRelationType maps a franchise relation to its Relation* constant. Other
+// relations are only kept when they point at a movie.
+func mapAniListRelationType(relationType string, format string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(relationType)) {
+	case "SEQUEL":
+		return metadata.RelationSequel, true
+	case "PREQUEL":
+		return metadata.RelationPrequel, true
+	case "SIDE_STORY":
+		return metadata.RelationSideStory, true
+	case "PARENT":
+		return metadata.RelationParent, true
+	case "SPIN_OFF":
+		return metadata.RelationSpinOff, true
+	case "ALTERNATIVE":
+		return metadata.RelationAlternative, true
+	case "SUMMARY":
+		return metadata.RelationSummary, true
+	case "CHARACTER":
+		return "", false
+	}
+	if strings.EqualFold(format, "MOVIE") {
+		return metadata.RelationOther, true
+	}
+	return "", false
+}
+
+func mapAniListFormat(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
 func mapAniListMediaList(items []anilistMediaSummary) []metadata.Show {
 	if len(items) == 0 {
 		return []metadata.Show{}
@@ -528,7 +692,17 @@ func mapAniListMediaToShow(media anilistMediaSummary) metadata.Show {
 		PosterUrl:      posterURL,
 		BannerUrl:      bannerURL,
 		EpisodeCount:   media.Episodes,
+		LinkedIDs:      linkedIDs(media.IDMal),
+	}
+}
+
+// linkedIDs reports the MyAnimeList id AniList cross-references, so imports from either
+// side resolve to the same stored show.
+func linkedIDs(idMal *int64) []string {
+	if idMal == nil || *idMal <= 0 {
+		return nil
 	}
+	return []string{showmodel.ExternalProviderMAL + ":" + strconv.FormatInt(*idMal, 10)}
 }
 
 func normalizeAverageScore(value *float64) *float64 {
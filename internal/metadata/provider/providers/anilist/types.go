@@ -1,10 +1,15 @@
 package anilist
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/transport"
+)
 
 type Provider struct {
 	endpoint string
 	client   *http.Client
+	upstream *transport.Transport
 }
 
 type graphQLRequest struct {
@@ -62,9 +67,18 @@ type graphQLEpisodesResponse struct {
 	Errors []graphQLError `json:"errors"`
 }
 
+type graphQLRelationsResponse struct {
+	Data struct {
+		Media anilistMediaRelations `json:"Media"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
 type anilistMediaSummary struct {
 	ID          int64             `json:"id"`
+	IDMal       *int64            `json:"idMal"`
 	Type        string            `json:"type"`
+	Format      string            `json:"format"`
 	Status      string            `json:"status"`
 	Description *string           `json:"description"`
 	BannerImage *string           `json:"bannerImage"`
@@ -76,12 +90,26 @@ type anilistMediaSummary struct {
 	Title       anilistMediaTitle `json:"title"`
 }
 
+// anilistMediaRelations is a show with its version 2 relation edges.
+type anilistMediaRelations struct {
+	anilistMediaSummary
+	Relations struct {
+		Edges []anilistRelationEdge `json:"edges"`
+	} `json:"relations"`
+}
+
+type anilistRelationEdge struct {
+	RelationType string              `json:"relationType"`
+	Node         anilistMediaSummary `json:"node"`
+}
+
 type anilistCoverImage struct {
 	Large *string `json:"large"`
 }
 
 type anilistMediaDetails struct {
 	ID          int64             `json:"id"`
+	IDMal       *int64            `json:"idMal"`
 	Title       anilistMediaTitle `json:"title"`
 	Status      string            `json:"status"`
 	Synonyms    []string          `json:"synonyms"`
@@ -0,0 +1,213 @@
+package anilisttvdb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+)
+
+const (
+	idPrefix     = "anilist:"
+	tvdbIDPrefix = "tvdb:"
+	tvdbPageSize = 100
+	tvdbMaxPages = 20
+)
+
+// New wraps an AniList provider so its episode listings carry TVDB titles, runtimes
+// and numbering for AniList entries that have a stored TVDB mapping.
+func New(anilist metadata.Provider, tvdb metadata.Provider, mappings MappingStore) *Provider {
+	return &Provider{
+		anilist:  anilist,
+		tvdb:     tvdb,
+		mappings: mappings,
+	}
+}
+
+// Search adds the mapped TVDB series to each AniList hit's linked ids, so federated
+// search can merge it with the TVDB hit for the same show.
+func (p *Provider) Search(ctx context.Context, query string, opts metadata.SearchOpts) ([]metadata.SearchHit, error) {
+	hits, err := p.anilist.Search(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i] = p.linkTVDB(ctx, hits[i])
+	}
+	return hits, nil
+}
+
+// Capabilities are AniList's, plus the season filter and episode titles that TVDB adds
+// for shows with a mapping.
+func (p *Provider) Capabilities() metadata.Capabilities {
+	capabilities := metadata.CapabilitiesOf(p.anilist)
+	capabilities.SeasonFilter = true
+	capabilities.EpisodeTitles = true
+	return capabilities
+}
+
+// Health is AniList's; TVDB failures only cost mapped shows their titles.
+func (p *Provider) Health() metadata.Health {
+	return metadata.HealthOf(p.anilist)
+}
+
+func (p *Provider) Discover(ctx context.Context, opts metadata.DiscoverOpts) (metadata.DiscoverResult, error) {
+	return p.anilist.Discover(ctx, opts)
+}
+
+func (p *Provider) GetShow(ctx context.Context, externalID string) (metadata.Show, error) {
+	item, err := p.anilist.GetShow(ctx, externalID)
+	if err != nil {
+		return metadata.Show{}, err
+	}
+	return p.linkTVDB(ctx, item), nil
+}
+
+// ListRelations forwards to AniList and links every mapped show to its TVDB series.
+func (p *Provider) ListRelations(ctx context.Context, externalID string) (metadata.ShowRelations, error) {
+	lister, ok := p.anilist.(metadata.RelationLister)
+	if !ok {
+		return metadata.ShowRelations{}, metadata.Errorf(metadata.ErrUnsupported, "anilist does not support related shows")
+	}
+	item, err := lister.ListRelations(ctx, externalID)
+	if err != nil {
+		return metadata.ShowRelations{}, err
+	}
+	item.Show = p.linkTVDB(ctx, item.Show)
+	for i := range item.Relations {
+		item.Relations[i].Show = p.linkTVDB(ctx, item.Relations[i].Show)
+	}
+	return item, nil
+}
+
+// ListEpisodes renumbers AniList's airing schedule into the mapped TVDB season and
+// fills in TVDB titles and runtimes. Unmapped entries are returned as AniList has
+// them. If TVDB is unavailable the renumbered schedule is still returned.
+func (p *Provider) ListEpisodes(ctx context.Context, externalID string, opts metadata.ListEpisodesOpts) ([]metadata.Episode, error) {
+	anilistID, err := parseExternalID(externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, ok, err := p.mappings.LookupTVDB(ctx, anilistID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return p.anilist.ListEpisodes(ctx, externalID, opts)
+	}
+	if opts.SeasonNumber != nil && *opts.SeasonNumber != mapping.SeasonNumber {
+		return []metadata.Episode{}, nil
+	}
+
+	episodes, err := p.anilist.ListEpisodes(ctx, externalID, metadata.ListEpisodesOpts{
+		Page:  opts.Page,
+		Limit: opts.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tvdbEpisodes, err := p.tvdbSeason(ctx, mapping)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("anilist-tvdb: tvdb %d season %d unavailable, returning untitled schedule: %v", mapping.TVDBID, mapping.SeasonNumber, err)
+		tvdbEpisodes = map[int64]metadata.Episode{}
+	}
+
+	for i := range episodes {
+		episodes[i] = mergeEpisode(episodes[i], mapping, tvdbEpisodes)
+	}
+	return episodes, nil
+}
+
+// linkTVDB appends "tvdb:<id>" to item's linked ids when its AniList entry is mapped.
+// A failed lookup only loses the link, so it is logged rather than returned.
+func (p *Provider) linkTVDB(ctx context.Context, item metadata.Show) metadata.Show {
+	anilistID, err := parseExternalID(item.ExternalID)
+	if err != nil {
+		return item
+	}
+	mapping, ok, err := p.mappings.LookupTVDB(ctx, anilistID)
+	if err != nil {
+		log.Printf("anilist-tvdb: mapping lookup for anilist %d failed: %v", anilistID, err)
+		return item
+	}
+	if !ok {
+		return item
+	}
+
+	tvdbID := tvdbIDPrefix + strconv.FormatInt(mapping.TVDBID, 10)
+	if !slices.Contains(item.LinkedIDs, tvdbID) {
+		item.LinkedIDs = append(item.LinkedIDs, tvdbID)
+	}
+	return item
+}
+
+func (p *Provider) tvdbSeason(ctx context.Context, mapping Mapping) (map[int64]metadata.Episode, error) {
+	externalID := tvdbIDPrefix + strconv.FormatInt(mapping.TVDBID, 10)
+	season := mapping.SeasonNumber
+
+	out := map[int64]metadata.Episode{}
+	for page := 1; page <= tvdbMaxPages; page++ {
+		items, err := p.tvdb.ListEpisodes(ctx, externalID, metadata.ListEpisodesOpts{
+			Page:         page,
+			Limit:        tvdbPageSize,
+			SeasonNumber: &season,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			out[item.EpisodeNumber] = item
+		}
+		if len(items) < tvdbPageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+func mergeEpisode(item metadata.Episode, mapping Mapping, tvdbEpisodes map[int64]metadata.Episode) metadata.Episode {
+	item.SeasonNumber = mapping.SeasonNumber
+	item.EpisodeNumber += mapping.EpisodeOffset
+	// AniList has no titles, only "Episode N" placeholders, so renumbering renames too.
+	item.Title = fmt.Sprintf("Episode %d", item.EpisodeNumber)
+
+	match, ok := tvdbEpisodes[item.EpisodeNumber]
+	if !ok {
+		return item
+	}
+
+	if title := strings.TrimSpace(match.Title); title != "" {
+		item.Title = title
+	}
+	if match.RuntimeMinutes != nil {
+		item.RuntimeMinutes = match.RuntimeMinutes
+	}
+	if match.AbsoluteNumber != nil {
+		item.AbsoluteNumber = match.AbsoluteNumber
+	}
+	if item.AirDate == nil {
+		item.AirDate = match.AirDate
+	}
+	if match.Type != "" {
+		item.Type = match.Type
+	}
+	return item
+}
+
+func parseExternalID(value string) (int64, error) {
+	normalized := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), idPrefix)
+	id, err := strconv.ParseInt(strings.TrimSpace(normalized), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, metadata.Errorf(metadata.ErrInvalidExternalID, "anilist external id must be a positive integer")
+	}
+	return id, nil
+}
@@ -0,0 +1,25 @@
+package anilisttvdb
+
+import (
+	"context"
+
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+)
+
+// Mapping places an AniList entry inside a TVDB series: AniList episode N is episode
+// N+EpisodeOffset of season SeasonNumber.
+type Mapping struct {
+	TVDBID        int64
+	SeasonNumber  int64
+	EpisodeOffset int64
+}
+
+type MappingStore interface {
+	LookupTVDB(ctx context.Context, anilistID int64) (Mapping, bool, error)
+}
+
+type Provider struct {
+	anilist  metadata.Provider
+	tvdb     metadata.Provider
+	mappings MappingStore
+}
@@ -7,7 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -16,263 +16,307 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/transport"
 	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
 	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
-	defaultBaseURL  = "https://api4.thetvdb.com/v4"
-	defaultTimeout  = 20 * time.Second
-	defaultPage     = 1
-	defaultPageSize = 10
-	maxPageSize     = 100
-	idPrefix        = "tvdb:"
+	defaultBaseURL         = "https://api4.thetvdb.com/v4"
+	defaultLanguage        = "eng"
+	defaultDiscoverCountry = "usa"
+	defaultSeasonType      = "default"
+	defaultTimeout         = 20 * time.Second
+	// TVDB publishes no rate limit; this keeps bursts such as season fan-out polite.
+	requestsPerMinute = 600
+	requestBurst      = 10
+	defaultPage       = 1
+	defaultPageSize   = 10
+	maxPageSize       = 100
+	idPrefix          = "tvdb:"
+
+	// episodesPageSize is the fixed page size of /series/{id}/episodes.
+	episodesPageSize = 500
+	// tokenTTL is used when the login token carries no readable expiry.
+	tokenTTL = 24 * time.Hour
+	// tokenExpirySkew renews the token slightly before TVDB rejects it.
+	tokenExpirySkew = time.Minute
+
+	statusContinuing = 1
+	statusEnded      = 2
+	statusUpcoming   = 3
+
+	artworkBanner = 1
+	artworkPoster = 2
 )
 
+// errUnauthorized triggers one re-login; if it persists the provider is unusable.
+var errUnauthorized = metadata.Errorf(metadata.ErrUpstreamUnavailable, "tvdb request unauthorized")
+
+// remoteSources maps TVDB remote id source names to show link providers.
+var remoteSources = map[string]string{
+	"imdb":           showmodel.ExternalProviderIMDB,
+	"themoviedb.com": showmodel.ExternalProviderTMDB,
+	"myanimelist":    showmodel.ExternalProviderMAL,
+}
+
 func New() *Provider {
+	upstream := transport.New("tvdb", transport.Options{
+		RequestsPerMinute: requestsPerMinute,
+		Burst:             requestBurst,
+	})
 	return &Provider{
-		baseURL: strings.TrimRight(getEnv("TVDB_BASE_URL", defaultBaseURL), "/"),
-		apiKey:  strings.TrimSpace(os.Getenv("TVDB_API_KEY")),
-		pin:     strings.TrimSpace(os.Getenv("TVDB_PIN")),
-		client: &http.Client{
-			Timeout: defaultTimeout,
-		},
-		debug: strings.EqualFold(strings.TrimSpace(os.Getenv("TVDB_DEBUG")), "1") ||
-			strings.EqualFold(strings.TrimSpace(os.Getenv("TVDB_DEBUG")), "true"),
+		baseURL:         strings.TrimRight(getEnv("TVDB_BASE_URL", defaultBaseURL), "/"),
+		apiKey:          strings.TrimSpace(os.Getenv("TVDB_API_KEY")),
+		pin:             strings.TrimSpace(os.Getenv("TVDB_PIN")),
+		language:        getEnv("TVDB_LANGUAGE", defaultLanguage),
+		discoverCountry: getEnv("TVDB_DISCOVER_COUNTRY", defaultDiscoverCountry),
+		seasonType:      getEnv("TVDB_SEASON_TYPE", defaultSeasonType),
+		client:          upstream.Client(defaultTimeout),
+		upstream:        upstream,
 	}
 }
 
-func (p *Provider) Search(ctx context.Context, query string, opts metadata.SearchOpts) ([]metadata.SearchHit, error) {
-	return nil, errors.New("tvdb provider is not implemented yet")
+func (p *Provider) Capabilities() metadata.Capabilities {
+	capabilities := metadata.Capabilities{
+		IDPrefix:      idPrefix,
+		Search:        true,
+		Discover:      true,
+		GetShow:       true,
+		ListEpisodes:  true,
+		SeasonFilter:  true,
+		EpisodeTitles: true,
+		Configured:    p.apiKey != "",
+	}
+	if !capabilities.Configured {
+		capabilities.ConfigError = "missing TVDB_API_KEY"
+	}
+	return capabilities
+}
+
+func (p *Provider) Health() metadata.Health {
+	return p.upstream.Health()
+}
 
+func (p *Provider) Search(ctx context.Context, query string, opts metadata.SearchOpts) ([]metadata.SearchHit, error) {
 	if strings.TrimSpace(query) == "" {
 		return []metadata.SearchHit{}, nil
 	}
 
-	items, err := p.searchItems(ctx, query, opts, true)
-	if err != nil {
-		return nil, err
-	}
-	if len(items) == 0 {
-		// TVDB's search behavior can vary by account/index; retry without type filter.
-		items, err = p.searchItems(ctx, query, opts, false)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	hits := make([]metadata.SearchHit, 0, len(items))
-	for _, item := range items {
-		titlePreferred := firstString(item, "name_translated", "title", "seriesName", "name", "slug")
-		if titlePreferred == "" {
-			titlePreferred = "Untitled"
-		}
-
-		titleOriginal := normalizeutil.StringValuePtr(firstString(item, "name"))
-		bannerURL := normalizeutil.StringValuePtr(firstString(item, "image_url", "thumbnail", "image", "banner"))
-		synopsis := normalizeutil.StringValuePtr(firstString(item, "overview_translated", "overview", "overviews", "summary", "plot"))
-		_ = floatPtr(firstFloat64(item, "score"))
-
-		hits = append(hits, metadata.SearchHit{
-			ExternalID:     formatExternalID(normalizeSeriesID(firstString(item, "id", "tvdb_id"))),
-			TitlePreferred: titlePreferred,
-			TitleOriginal:  titleOriginal,
-			AltTitles:      extractTVDBAltTitles(item, titlePreferred, titleOriginal),
-			Type:           mapShowType(firstString(item, "primary_type", "type")),
-			Status:         showmodel.NormalizeStatusOrDefault(firstString(item, "status", "statusName"), showmodel.StatusOngoing),
-			Synopsis:       synopsis,
-			BannerUrl:      bannerURL,
-		})
-	}
-
-	return hits, nil
-}
-
-func (p *Provider) searchItems(ctx context.Context, query string, opts metadata.SearchOpts, includeType bool) ([]map[string]any, error) {
-	// TODO cleanup: remove temporary debug logging and search fallback once TVDB response handling is fully stabilized.
 	page := normalizeutil.Page(opts.Page, defaultPage)
-	tvdbPage := toTVDBPage(page)
+	limit := normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)
 
 	params := url.Values{}
 	params.Set("query", query)
-	if includeType {
-		params.Set("type", "series")
-	}
-	params.Set("page", strconv.Itoa(tvdbPage))
-	limit := normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)
+	params.Set("type", "series")
+	params.Set("offset", strconv.Itoa((page-1)*limit))
 	params.Set("limit", strconv.Itoa(limit))
 
-	body, err := p.doRequest(ctx, http.MethodGet, "/search?"+params.Encode(), nil)
-	if err != nil {
+	var response envelope[[]searchResult]
+	if err := p.get(ctx, "/search", params, &response); err != nil {
 		return nil, err
 	}
 
-	if p.debug {
-		log.Printf("[tvdb] search includeType=%v query=%q page=%d tvdbPage=%d limit=%d raw=%s", includeType, query, page, tvdbPage, limit, truncateForLog(body, 1200))
-	}
-	items, err := decodeArrayData(body)
-	if err != nil {
-		return nil, err
+	hits := make([]metadata.SearchHit, 0, len(response.Data))
+	for _, item := range response.Data {
+		hits = append(hits, p.mapSearchResult(item))
 	}
-	if p.debug {
-		log.Printf("[tvdb] search parsed includeType=%v count=%d", includeType, len(items))
-	}
-	return items, nil
+	return hits, nil
 }
 
+// Discover builds each section from /series/filter, since TVDB has no trending or
+// popularity feeds. Score is TVDB's popularity measure.
 func (p *Provider) Discover(ctx context.Context, opts metadata.DiscoverOpts) (metadata.DiscoverResult, error) {
-	_ = ctx
-	_ = opts
-	return metadata.DiscoverResult{}, errors.New("tvdb provider is not implemented yet")
+	page := normalizeutil.Page(opts.Page, defaultPage)
+	limit := normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)
+	year := time.Now().UTC().Year()
+
+	var result metadata.DiscoverResult
+	sections := []struct {
+		filter discoverFilter
+		target *[]metadata.Show
+	}{
+		{discoverFilter{sort: "score", sortType: "desc", status: statusContinuing, year: year}, &result.Trending},
+		{discoverFilter{sort: "score", sortType: "desc"}, &result.Popular},
+		{discoverFilter{sort: "score", sortType: "desc", status: statusEnded}, &result.TopRated},
+		{discoverFilter{sort: "firstAired", sortType: "asc", status: statusUpcoming}, &result.Upcoming},
+		{discoverFilter{sort: "lastAired", sortType: "desc", status: statusContinuing}, &result.CurrentlyAiring},
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, section := range sections {
+		group.Go(func() error {
+			items, err := p.filterSeries(groupCtx, section.filter)
+			if err != nil {
+				return err
+			}
+			*section.target = p.mapSeriesList(paginate(items, page, limit))
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return metadata.DiscoverResult{}, err
+	}
+	return result, nil
 }
 
 func (p *Provider) GetShow(ctx context.Context, externalID string) (metadata.Show, error) {
-	return metadata.Show{}, errors.New("tvdb provider is not implemented yet")
-
 	id, err := parseExternalID(externalID)
 	if err != nil {
 		return metadata.Show{}, err
 	}
 
-	body, err := p.doRequest(ctx, http.MethodGet, "/series/"+id+"/extended", nil)
-	if err != nil {
-		return metadata.Show{}, err
-	}
+	params := url.Values{}
+	params.Set("meta", "translations")
 
-	item, err := decodeObjectData(body)
-	if err != nil {
+	var response envelope[seriesRecord]
+	if err := p.get(ctx, "/series/"+id+"/extended", params, &response); err != nil {
 		return metadata.Show{}, err
 	}
-
-	titlePreferred := firstString(item, "name_translated", "name", "slug")
-	if titlePreferred == "" {
-		titlePreferred = "Untitled"
-	}
-
-	posterURL := normalizeutil.StringValuePtr(firstString(item, "image", "image_url"))
-	bannerURL := posterURL
-	if artworks := mapSlice(item, "artworks"); len(artworks) > 0 {
-		for _, artwork := range artworks {
-			typ := strings.ToLower(firstString(artwork, "type", "typeName"))
-			if strings.Contains(typ, "banner") {
-				bannerURL = normalizeutil.StringValuePtr(firstString(artwork, "image", "image_url"))
-				break
-			}
-		}
+	if response.Data.ID == 0 {
+		return metadata.Show{}, metadata.Errorf(metadata.ErrNotFound, "tvdb show %s not found", externalID)
 	}
 
-	return metadata.Show{
-		ExternalID:     formatExternalID(id),
-		TitlePreferred: titlePreferred,
-		TitleOriginal:  normalizeutil.StringValuePtr(firstString(item, "name")),
-		AltTitles:      extractTVDBAltTitles(item, titlePreferred, normalizeutil.StringValuePtr(firstString(item, "name"))),
-		Type:           mapShowType(firstString(item, "type", "primary_type")),
-		Status:         showmodel.NormalizeStatusOrDefault(firstString(item, "status", "statusName"), showmodel.StatusOngoing),
-		Synopsis:       normalizeutil.StringValuePtr(firstString(item, "overview_translated", "overview", "overviews")),
-		StartDate:      normalizeutil.StringValuePtr(firstString(item, "firstAired", "first_air_time")),
-		EndDate:        normalizeutil.StringValuePtr(firstString(item, "lastAired")),
-		PosterUrl:      posterURL,
-		BannerUrl:      bannerURL,
-	}, nil
+	return p.mapSeries(response.Data), nil
 }
 
+// ListEpisodes pages over the configured season order. TVDB serves 500 episodes per
+// page, before unnumbered and off-season episodes are dropped, so TVDB pages are walked
+// from the first one and the page/limit window is cut out of the episodes that remain.
+// Imports use ListAllEpisodes instead, which walks the pages once.
 func (p *Provider) ListEpisodes(ctx context.Context, externalID string, opts metadata.ListEpisodesOpts) ([]metadata.Episode, error) {
-	return nil, errors.New("tvdb provider is not implemented yet")
-
 	id, err := parseExternalID(externalID)
 	if err != nil {
 		return nil, err
 	}
 
 	page := normalizeutil.Page(opts.Page, defaultPage)
-	tvdbPage := toTVDBPage(page)
+	limit := normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)
+	start := (page - 1) * limit
 
-	params := url.Values{}
-	params.Set("page", strconv.Itoa(tvdbPage))
-	params.Set("limit", strconv.Itoa(normalizeutil.Limit(opts.Limit, defaultPageSize, maxPageSize)))
-	body, err := p.doRequest(ctx, http.MethodGet, "/series/"+id+"/episodes/default?"+params.Encode(), nil)
+	items, err := p.episodeRecords(ctx, id, opts.SeasonNumber, start+limit)
 	if err != nil {
 		return nil, err
 	}
+	return mapEpisodes(paginateFrom(items, start, limit)), nil
+}
 
-	items, err := decodeArrayData(body)
+// ListAllEpisodes walks every TVDB episode page of the series once.
+func (p *Provider) ListAllEpisodes(ctx context.Context, externalID string) ([]metadata.Episode, error) {
+	id, err := parseExternalID(externalID)
 	if err != nil {
 		return nil, err
 	}
 
-	episodes := make([]metadata.Episode, 0, len(items))
-	for _, item := range items {
-		seasonNumber := int64(firstInt(item, "seasonNumber", "season"))
-		episodeNumber := int64(firstInt(item, "number", "episodeNumber"))
-		if seasonNumber < 0 || episodeNumber < 0 {
-			continue
+	items, err := p.episodeRecords(ctx, id, nil, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	return mapEpisodes(items), nil
+}
+
+// episodeRecords reads TVDB pages from the first one until at least want episodes are
+// kept or the series runs out.
+func (p *Provider) episodeRecords(ctx context.Context, seriesID string, seasonNumber *int64, want int) ([]episodeRecord, error) {
+	items := make([]episodeRecord, 0)
+	for tvdbPage := 0; ; tvdbPage++ {
+		records, hasNext, err := p.episodePage(ctx, seriesID, tvdbPage, seasonNumber)
+		if err != nil {
+			return nil, err
 		}
-		if opts.SeasonNumber != nil && seasonNumber != *opts.SeasonNumber {
-			continue
+		items = append(items, records...)
+		if len(items) >= want || !hasNext {
+			return items, nil
 		}
+	}
+}
 
-		title := firstString(item, "name")
-		if title == "" {
-			title = fmt.Sprintf("Episode %d", episodeNumber)
-		}
+func (p *Provider) episodePage(ctx context.Context, seriesID string, page int, seasonNumber *int64) ([]episodeRecord, bool, error) {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	if seasonNumber != nil {
+		params.Set("season", strconv.FormatInt(*seasonNumber, 10))
+	}
+
+	var response envelope[episodesData]
+	if err := p.get(ctx, "/series/"+seriesID+"/episodes/"+url.PathEscape(p.seasonType), params, &response); err != nil {
+		return nil, false, err
+	}
 
-		epID := firstString(item, "id")
-		if epID == "" {
-			epID = fmt.Sprintf("%s:%d:%d", id, seasonNumber, episodeNumber)
+	records := make([]episodeRecord, 0, len(response.Data.Episodes))
+	for _, item := range response.Data.Episodes {
+		if item.SeasonNumber < 0 || item.Number <= 0 {
+			continue
+		}
+		if seasonNumber != nil && item.SeasonNumber != *seasonNumber {
+			continue
 		}
+		records = append(records, item)
+	}
 
-		runtime := int64Ptr(firstInt(item, "runtime", "runtimeMinutes"))
+	hasNext := response.Links.Next != nil && *response.Links.Next != "" && len(response.Data.Episodes) > 0
+	return records, hasNext, nil
+}
 
-		episodes = append(episodes, metadata.Episode{
-			Provider:       metadata.ProviderTVDB,
-			ExternalID:     formatExternalID(epID),
-			SeasonNumber:   seasonNumber,
-			EpisodeNumber:  episodeNumber,
-			Title:          title,
-			AirDate:        normalizeutil.StringValuePtr(firstString(item, "aired", "firstAired")),
-			RuntimeMinutes: runtime,
-		})
+func (p *Provider) filterSeries(ctx context.Context, filter discoverFilter) ([]seriesRecord, error) {
+	params := url.Values{}
+	params.Set("country", p.discoverCountry)
+	params.Set("lang", p.language)
+	params.Set("sort", filter.sort)
+	params.Set("sortType", filter.sortType)
+	if filter.status > 0 {
+		params.Set("status", strconv.Itoa(filter.status))
+	}
+	if filter.year > 0 {
+		params.Set("year", strconv.Itoa(filter.year))
 	}
 
-	sort.Slice(episodes, func(i, j int) bool {
-		if episodes[i].SeasonNumber != episodes[j].SeasonNumber {
-			return episodes[i].SeasonNumber < episodes[j].SeasonNumber
-		}
-		return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber
-	})
+	var response envelope[[]seriesRecord]
+	if err := p.get(ctx, "/series/filter", params, &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
 
-	return episodes, nil
+// get performs an authenticated GET and decodes the JSON envelope into target. A 401
+// drops the cached token and retries once with a fresh login.
+func (p *Provider) get(ctx context.Context, path string, params url.Values, target any) error {
+	endpoint := p.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	body, err := p.doRequest(ctx, endpoint)
+	if errors.Is(err, errUnauthorized) {
+		p.resetToken()
+		body, err = p.doRequest(ctx, endpoint)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, target)
 }
 
-func (p *Provider) doRequest(ctx context.Context, method, path string, payload any) ([]byte, error) {
+func (p *Provider) doRequest(ctx context.Context, endpoint string) ([]byte, error) {
 	token, err := p.ensureToken(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var bodyReader io.Reader
-	if payload != nil {
-		raw, marshalErr := json.Marshal(payload)
-		if marshalErr != nil {
-			return nil, marshalErr
-		}
-		bodyReader = bytes.NewReader(raw)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
-	if payload != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
+	req.Header.Set("Accept-Language", p.language)
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	res, err := p.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, metadata.Errorf(metadata.ErrUpstreamUnavailable, "tvdb request failed: %w", err)
 	}
 	defer res.Body.Close()
 
@@ -280,12 +324,18 @@ func (p *Provider) doRequest(ctx context.Context, method, path string, payload a
 	if err != nil {
 		return nil, err
 	}
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, fmt.Errorf("tvdb request failed with status %d", res.StatusCode)
+	switch {
+	case res.StatusCode == http.StatusUnauthorized:
+		return nil, errUnauthorized
+	case res.StatusCode == http.StatusNotFound:
+		return nil, metadata.Errorf(metadata.ErrNotFound, "tvdb resource not found")
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		return nil, metadata.Errorf(metadata.ErrUpstreamUnavailable, "tvdb request failed with status %d", res.StatusCode)
 	}
 	return body, nil
 }
 
+// ensureToken returns the cached bearer token, logging in again once it has expired.
 func (p *Provider) ensureToken(ctx context.Context) (string, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -294,15 +344,10 @@ func (p *Provider) ensureToken(ctx context.Context) (string, error) {
 		return p.token, nil
 	}
 	if p.apiKey == "" {
-		return "", errors.New("tvdb provider is not configured: missing TVDB_API_KEY")
-	}
-
-	loginPayload := map[string]string{"apikey": p.apiKey}
-	if p.pin != "" {
-		loginPayload["pin"] = p.pin
+		return "", metadata.Errorf(metadata.ErrUnsupported, "tvdb provider is not configured: missing TVDB_API_KEY")
 	}
 
-	raw, err := json.Marshal(loginPayload)
+	raw, err := json.Marshal(loginRequest{APIKey: p.apiKey, PIN: p.pin})
 	if err != nil {
 		return "", err
 	}
@@ -316,7 +361,7 @@ func (p *Provider) ensureToken(ctx context.Context) (string, error) {
 
 	res, err := p.client.Do(req)
 	if err != nil {
-		return "", err
+		return "", metadata.Errorf(metadata.ErrUpstreamUnavailable, "tvdb login failed: %w", err)
 	}
 	defer res.Body.Close()
 
@@ -325,227 +370,253 @@ func (p *Provider) ensureToken(ctx context.Context) (string, error) {
 		return "", err
 	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return "", fmt.Errorf("tvdb login failed with status %d", res.StatusCode)
+		return "", metadata.Errorf(metadata.ErrUpstreamUnavailable, "tvdb login failed with status %d", res.StatusCode)
 	}
 
-	token, err := extractToken(body)
-	if err != nil {
+	var response envelope[loginData]
+	if err := json.Unmarshal(body, &response); err != nil {
 		return "", err
 	}
+	token := strings.TrimSpace(response.Data.Token)
+	if token == "" {
+		return "", metadata.Errorf(metadata.ErrUpstreamUnavailable, "tvdb login response missing token")
+	}
 
 	p.token = token
-	p.tokenUntil = time.Now().Add(24 * time.Hour)
+	p.tokenUntil = tokenExpiry(token, time.Now())
 	return p.token, nil
 }
 
-func extractToken(body []byte) (string, error) {
-	var payload map[string]any
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return "", err
+func (p *Provider) resetToken() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = ""
+	p.tokenUntil = time.Time{}
+}
+
+// tokenExpiry reads the exp claim of the login JWT. TVDB signs the token, so it is
+// parsed without verification purely to learn when to log in again.
+func tokenExpiry(token string, now time.Time) time.Time {
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil || claims.ExpiresAt == nil {
+		return now.Add(tokenTTL)
 	}
+	return claims.ExpiresAt.Add(-tokenExpirySkew)
+}
 
-	if token := firstString(payload, "token"); token != "" {
-		return token, nil
+func (p *Provider) mapSearchResult(item searchResult) metadata.SearchHit {
+	titleOriginal := normalizeutil.StringValuePtr(item.Name)
+	titlePreferred := firstNonEmpty(item.Translations[p.language], item.Name, item.Slug)
+	if titlePreferred == "" {
+		titlePreferred = "Untitled"
 	}
-	if data, ok := payload["data"].(map[string]any); ok {
-		if token := firstString(data, "token"); token != "" {
-			return token, nil
-		}
+
+	return metadata.SearchHit{
+		ExternalID:     formatExternalID(normalizeSeriesID(firstNonEmpty(item.TVDBID, item.ID))),
+		TitlePreferred: titlePreferred,
+		TitleOriginal:  titleOriginal,
+		AltTitles:      buildAltTitles(titlePreferred, titleOriginal, item.Aliases),
+		Type:           mapShowType(item.PrimaryType),
+		Status:         showmodel.NormalizeStatusOrDefault(item.Status, showmodel.StatusOngoing),
+		Synopsis:       normalizeutil.StringValuePtr(firstNonEmpty(item.Overviews[p.language], item.Overview)),
+		StartDate:      normalizeutil.StringValuePtr(item.FirstAirTime),
+		PosterUrl:      normalizeutil.StringValuePtr(item.ImageURL),
+		BannerUrl:      normalizeutil.StringValuePtr(firstNonEmpty(item.ImageURL, item.Thumbnail)),
+		LinkedIDs:      linkedIDs(item.RemoteIDs),
 	}
+}
 
-	return "", errors.New("tvdb login response missing token")
+func (p *Provider) mapSeriesList(items []seriesRecord) []metadata.Show {
+	mapped := make([]metadata.Show, 0, len(items))
+	for _, item := range items {
+		mapped = append(mapped, p.mapSeries(item))
+	}
+	return mapped
 }
 
-func decodeArrayData(body []byte) ([]map[string]any, error) {
-	var payload map[string]any
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, err
+func (p *Provider) mapSeries(item seriesRecord) metadata.Show {
+	titleOriginal := normalizeutil.StringValuePtr(item.Name)
+	titlePreferred := firstNonEmpty(translatedName(item.Translations.NameTranslations, p.language), item.Name, item.Slug)
+	if titlePreferred == "" {
+		titlePreferred = "Untitled"
 	}
 
-	data := payload["data"]
-	switch v := data.(type) {
-	case []any:
-		return anySliceToMapSlice(v), nil
-	case map[string]any:
-		for _, key := range []string{"series", "episodes", "results", "items", "hits"} {
-			if arr, ok := v[key].([]any); ok {
-				return anySliceToMapSlice(arr), nil
-			}
-		}
+	aliases := make([]string, 0, len(item.Aliases))
+	for _, alias := range item.Aliases {
+		aliases = append(aliases, alias.Name)
+	}
+
+	posterURL := normalizeutil.StringValuePtr(firstNonEmpty(artworkImage(item.Artworks, artworkPoster), item.Image))
+	bannerURL := normalizeutil.StringValuePtr(artworkImage(item.Artworks, artworkBanner))
+	if bannerURL == nil {
+		bannerURL = posterURL
+	}
+
+	return metadata.Show{
+		ExternalID:     formatExternalID(strconv.FormatInt(item.ID, 10)),
+		TitlePreferred: titlePreferred,
+		TitleOriginal:  titleOriginal,
+		AltTitles:      buildAltTitles(titlePreferred, titleOriginal, aliases),
+		Type:           seriesType(item.Genres),
+		Status:         showmodel.NormalizeStatusOrDefault(item.Status.Name, showmodel.StatusOngoing),
+		Synopsis:       normalizeutil.StringValuePtr(firstNonEmpty(translatedOverview(item.Translations.OverviewTranslations, p.language), item.Overview)),
+		StartDate:      normalizeutil.StringValuePtr(item.FirstAired),
+		EndDate:        endDate(item),
+		PosterUrl:      posterURL,
+		BannerUrl:      bannerURL,
+		SeasonCount:    seasonCount(item.Seasons, p.seasonType),
+		LinkedIDs:      linkedIDs(item.RemoteIDs),
+	}
+}
 
-		// TVDB responses vary by endpoint/version; recursively find first object array.
-		if arr := findFirstMapArray(v); len(arr) > 0 {
-			return arr, nil
+// linkedIDs keeps the remote ids TVDB cross-references that a show can be linked under.
+func linkedIDs(items []remoteID) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		id := strings.TrimSpace(item.ID)
+		provider, ok := remoteSources[strings.ToLower(strings.TrimSpace(item.SourceName))]
+		if !ok || id == "" {
+			continue
 		}
+		out = append(out, provider+":"+id)
+	}
+	if len(out) == 0 {
+		return nil
 	}
+	return out
+}
 
-	// Fallback: search entire payload for a nested object array.
-	if arr := findFirstMapArray(payload); len(arr) > 0 {
-		return arr, nil
+func mapEpisodes(items []episodeRecord) []metadata.Episode {
+	episodes := make([]metadata.Episode, 0, len(items))
+	for _, item := range items {
+		episodes = append(episodes, mapEpisode(item))
 	}
 
-	return []map[string]any{}, nil
+	sort.Slice(episodes, func(i, j int) bool {
+		if episodes[i].SeasonNumber != episodes[j].SeasonNumber {
+			return episodes[i].SeasonNumber < episodes[j].SeasonNumber
+		}
+		return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber
+	})
+	return episodes
 }
 
-func decodeObjectData(body []byte) (map[string]any, error) {
-	var payload map[string]any
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, err
+func mapEpisode(item episodeRecord) metadata.Episode {
+	title := ""
+	if item.Name != nil {
+		title = strings.TrimSpace(*item.Name)
+	}
+	if title == "" {
+		title = fmt.Sprintf("Episode %d", item.Number)
+	}
+
+	episodeType := metadata.EpisodeTypeRegular
+	if item.SeasonNumber == 0 {
+		episodeType = metadata.EpisodeTypeSpecial
 	}
 
-	if data, ok := payload["data"].(map[string]any); ok {
-		return data, nil
+	var runtime *int64
+	if item.Runtime != nil && *item.Runtime > 0 {
+		runtime = item.Runtime
+	}
+	var absoluteNumber *int64
+	if item.AbsoluteNumber != nil && *item.AbsoluteNumber > 0 {
+		absoluteNumber = item.AbsoluteNumber
 	}
 
-	return map[string]any{}, nil
+	return metadata.Episode{
+		Provider:       metadata.ProviderTVDB,
+		ExternalID:     formatExternalID(strconv.FormatInt(item.ID, 10)),
+		SeasonNumber:   item.SeasonNumber,
+		EpisodeNumber:  item.Number,
+		Title:          title,
+		Type:           episodeType,
+		AbsoluteNumber: absoluteNumber,
+		AirDate:        normalizeutil.StringPtr(item.Aired),
+		RuntimeMinutes: runtime,
+	}
 }
 
-func anySliceToMapSlice(items []any) []map[string]any {
-	out := make([]map[string]any, 0, len(items))
+func translatedName(items []translation, language string) string {
 	for _, item := range items {
-		if m, ok := item.(map[string]any); ok {
-			out = append(out, m)
+		if item.Language == language {
+			return strings.TrimSpace(item.Name)
 		}
 	}
-	return out
+	return ""
 }
 
-func mapSlice(m map[string]any, key string) []map[string]any {
-	raw, ok := m[key].([]any)
-	if !ok {
-		return nil
+func translatedOverview(items []translation, language string) string {
+	for _, item := range items {
+		if item.Language == language {
+			return strings.TrimSpace(item.Overview)
+		}
 	}
-	return anySliceToMapSlice(raw)
+	return ""
 }
 
-func findFirstMapArray(value any) []map[string]any {
-	switch v := value.(type) {
-	case []any:
-		items := anySliceToMapSlice(v)
-		if len(items) > 0 {
-			return items
-		}
-		for _, item := range v {
-			if nested := findFirstMapArray(item); len(nested) > 0 {
-				return nested
-			}
-		}
-	case map[string]any:
-		for _, item := range v {
-			if nested := findFirstMapArray(item); len(nested) > 0 {
-				return nested
-			}
+func artworkImage(items []seriesArtwork, artworkType int64) string {
+	for _, item := range items {
+		if item.Type == artworkType && strings.TrimSpace(item.Image) != "" {
+			return strings.TrimSpace(item.Image)
 		}
 	}
-	return nil
+	return ""
 }
 
-func firstString(m map[string]any, keys ...string) string {
-	for _, key := range keys {
-		value, ok := m[key]
-		if !ok || value == nil {
-			continue
-		}
+// endDate reports lastAired only for ended series; for running ones it is just the
+// latest episode so far.
+func endDate(item seriesRecord) *string {
+	if showmodel.NormalizeStatus(item.Status.Name) != showmodel.StatusFinished {
+		return nil
+	}
+	return normalizeutil.StringValuePtr(item.LastAired)
+}
 
-		switch v := value.(type) {
-		case string:
-			v = strings.TrimSpace(v)
-			if v != "" {
-				return v
-			}
-		case float64:
-			if v > 0 {
-				return strconv.FormatInt(int64(v), 10)
-			}
-		case int64:
-			if v > 0 {
-				return strconv.FormatInt(v, 10)
-			}
-		case map[string]any:
-			for _, langKey := range []string{"eng", "en", "english"} {
-				if nested, ok := v[langKey]; ok {
-					if nestedString, ok := nested.(string); ok {
-						nestedString = strings.TrimSpace(nestedString)
-						if nestedString != "" {
-							return nestedString
-						}
-					}
-				}
-			}
-			for _, nestedKey := range []string{"value", "id", "name", "title"} {
-				if nested, ok := v[nestedKey]; ok {
-					if nestedString, ok := nested.(string); ok {
-						nestedString = strings.TrimSpace(nestedString)
-						if nestedString != "" {
-							return nestedString
-						}
-					}
-				}
-			}
-		case []any:
-			for _, item := range v {
-				if m, ok := item.(map[string]any); ok {
-					lang := strings.ToLower(strings.TrimSpace(firstString(m, "language", "lang", "locale")))
-					if lang == "eng" || lang == "en" || lang == "english" {
-						if value := firstString(m, "name", "title", "value", "text"); value != "" {
-							return value
-						}
-					}
-				}
-			}
+func seasonCount(items []seriesSeason, seasonType string) *int64 {
+	var count int64
+	for _, item := range items {
+		if item.Number > 0 && strings.EqualFold(item.Type.Type, seasonType) {
+			count++
 		}
 	}
-	return ""
+	if count == 0 {
+		return nil
+	}
+	return &count
 }
 
-func firstFloat64(m map[string]any, keys ...string) float64 {
-	for _, key := range keys {
-		value, ok := m[key]
-		if !ok || value == nil {
-			continue
-		}
-		switch v := value.(type) {
-		case float64:
-			return v
-		case int64:
-			return float64(v)
-		case string:
-			if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
-				return parsed
-			}
+func seriesType(genres []seriesGenre) string {
+	for _, genre := range genres {
+		if strings.EqualFold(genre.Slug, "anime") || strings.EqualFold(genre.Name, "anime") {
+			return "anime"
 		}
 	}
-	return 0
+	return "tv"
 }
 
-func firstInt(m map[string]any, keys ...string) int {
-	for _, key := range keys {
-		value, ok := m[key]
-		if !ok || value == nil {
-			continue
-		}
-		switch v := value.(type) {
-		case float64:
-			return int(v)
-		case int64:
-			return int(v)
-		case string:
-			parsed, err := strconv.Atoi(strings.TrimSpace(v))
-			if err == nil {
-				return parsed
-			}
-		}
+func paginate[T any](items []T, page int, limit int) []T {
+	return paginateFrom(items, (page-1)*limit, limit)
+}
+
+func paginateFrom[T any](items []T, start int, limit int) []T {
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
 	}
-	return 0
+	return items[start:end]
 }
 
 func parseExternalID(externalID string) (string, error) {
 	id := normalizeSeriesID(strings.TrimSpace(externalID))
 	if id == "" {
-		return "", errors.New("tvdb external id is required")
+		return "", metadata.Errorf(metadata.ErrInvalidExternalID, "tvdb external id is required")
 	}
-	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
-		return "", errors.New("tvdb external id must be a positive integer")
+	if parsed, err := strconv.ParseInt(id, 10, 64); err != nil || parsed <= 0 {
+		return "", metadata.Errorf(metadata.ErrInvalidExternalID, "tvdb external id must be a positive integer")
 	}
 	return id, nil
 }
@@ -593,27 +664,13 @@ func mapShowType(value string) string {
 	}
 }
 
-func floatPtr(value float64) *float64 {
-	if value <= 0 {
-		return nil
-	}
-	return &value
-}
-
-func int64Ptr(value int) *int64 {
-	if value <= 0 {
-		return nil
-	}
-	converted := int64(value)
-	return &converted
-}
-
-func toTVDBPage(value int) int {
-	page := normalizeutil.Page(value, defaultPage)
-	if page <= 1 {
-		return 0
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			return trimmed
+		}
 	}
-	return page - 1
+	return ""
 }
 
 func getEnv(key, fallback string) string {
@@ -624,54 +681,26 @@ func getEnv(key, fallback string) string {
 	return value
 }
 
-func truncateForLog(body []byte, max int) string {
-	if len(body) <= max {
-		return string(body)
-	}
-	return string(body[:max]) + "...(truncated)"
-}
-
-func extractTVDBAltTitles(item map[string]any, preferred string, original *string) []string {
+func buildAltTitles(preferred string, original *string, values []string) []string {
 	seen := map[string]struct{}{}
-	add := func(value string, out *[]string) {
+	out := make([]string, 0)
+	for _, value := range values {
 		normalized := strings.TrimSpace(value)
 		if normalized == "" {
-			return
+			continue
 		}
 		if strings.EqualFold(normalized, preferred) {
-			return
+			continue
 		}
 		if original != nil && strings.EqualFold(normalized, *original) {
-			return
+			continue
 		}
 		key := strings.ToLower(normalized)
 		if _, ok := seen[key]; ok {
-			return
+			continue
 		}
 		seen[key] = struct{}{}
-		*out = append(*out, normalized)
-	}
-
-	out := make([]string, 0)
-
-	if aliases, ok := item["aliases"].([]any); ok {
-		for _, alias := range aliases {
-			switch v := alias.(type) {
-			case string:
-				add(v, &out)
-			case map[string]any:
-				add(firstString(v, "name", "title"), &out)
-			}
-		}
+		out = append(out, normalized)
 	}
-
-	if arr, ok := item["translations"].([]any); ok {
-		for _, entry := range arr {
-			if m, ok := entry.(map[string]any); ok {
-				add(firstString(m, "name", "title"), &out)
-			}
-		}
-	}
-
 	return out
 }
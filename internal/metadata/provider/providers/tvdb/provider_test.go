@@ -0,0 +1,392 @@
+package tvdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+)
+
+type fakeTVDB struct {
+	token    string
+	logins   atomic.Int32
+	requests atomic.Int32
+	// rejectNext makes the next authenticated request answer 401 once.
+	rejectNext atomic.Bool
+	episodes   []episodeRecord
+}
+
+func newFakeTVDB(t *testing.T) (*fakeTVDB, *Provider) {
+	t.Helper()
+
+	fake := &fakeTVDB{token: signedToken(t, time.Now().Add(time.Hour))}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	t.Setenv("TVDB_BASE_URL", server.URL)
+	t.Setenv("TVDB_API_KEY", "test-key")
+	t.Setenv("TVDB_PIN", "1234")
+	return fake, New()
+}
+
+func (f *fakeTVDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/login" {
+		var body loginRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.APIKey != "test-key" || body.PIN != "1234" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.logins.Add(1)
+		writeJSON(w, envelope[loginData]{Status: "success", Data: loginData{Token: f.token}})
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+f.token || f.rejectNext.CompareAndSwap(true, false) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.requests.Add(1)
+
+	switch r.URL.Path {
+	case "/search":
+		writeJSON(w, envelope[[]searchResult]{Data: []searchResult{{
+			ID:           "series-81189",
+			TVDBID:       "81189",
+			Name:         "Breaking Bad",
+			Aliases:      []string{"Breaking Bad", "BB"},
+			Translations: map[string]string{"eng": "Breaking Bad"},
+			Overviews:    map[string]string{"eng": "A chemistry teacher turns to crime."},
+			PrimaryType:  "series",
+			Status:       "Ended",
+			FirstAirTime: "2008-01-20",
+			ImageURL:     "https://img/poster.jpg",
+		}}})
+	case "/series/81189/extended":
+		writeJSON(w, envelope[seriesRecord]{Data: seriesRecord{
+			ID:         81189,
+			Name:       "Breaking Bad",
+			Aliases:    []seriesAlias{{Language: "spa", Name: "Reino del Metanfetamina"}},
+			FirstAired: "2008-01-20",
+			LastAired:  "2013-09-29",
+			Status:     seriesStatus{ID: statusEnded, Name: "Ended"},
+			Artworks: []seriesArtwork{
+				{Image: "https://img/banner.jpg", Type: artworkBanner},
+				{Image: "https://img/poster.jpg", Type: artworkPoster},
+			},
+			Seasons: []seriesSeason{
+				{Number: 0, Type: seriesSeasonType{Type: "default"}},
+				{Number: 1, Type: seriesSeasonType{Type: "default"}},
+				{Number: 2, Type: seriesSeasonType{Type: "default"}},
+				{Number: 1, Type: seriesSeasonType{Type: "dvd"}},
+			},
+			Translations: seriesTranslations{
+				OverviewTranslations: []translation{{Language: "eng", Overview: "A chemistry teacher turns to crime."}},
+			},
+			RemoteIDs: []remoteID{
+				{ID: "tt0903747", Type: 2, SourceName: "IMDB"},
+				{ID: "1396", Type: 12, SourceName: "TheMovieDB.com"},
+				{ID: "BreakingBad", Type: 4, SourceName: "Facebook"},
+			},
+		}})
+	case "/series/81189/episodes/default":
+		f.serveEpisodes(w, r)
+	case "/series/filter":
+		if r.URL.Query().Get("country") == "" || r.URL.Query().Get("lang") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		status := r.URL.Query().Get("status")
+		writeJSON(w, envelope[[]seriesRecord]{Data: []seriesRecord{
+			{ID: 1, Name: "Filter " + status + " A", Status: seriesStatus{Name: "Continuing"}},
+			{ID: 2, Name: "Filter " + status + " B", Status: seriesStatus{Name: "Continuing"}},
+			{ID: 3, Name: "Filter " + status + " C", Status: seriesStatus{Name: "Continuing"}},
+		}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeTVDB) serveEpisodes(w http.ResponseWriter, r *http.Request) {
+	items := f.episodes
+	if raw := r.URL.Query().Get("season"); raw != "" {
+		season, _ := strconv.ParseInt(raw, 10, 64)
+		filtered := make([]episodeRecord, 0)
+		for _, item := range items {
+			if item.SeasonNumber == season {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	start := page * episodesPageSize
+	end := min(start+episodesPageSize, len(items))
+	var next *string
+	if end < len(items) {
+		link := fmt.Sprintf("/series/81189/episodes/default?page=%d", page+1)
+		next = &link
+	}
+	writeJSON(w, envelope[episodesData]{
+		Data:  episodesData{Episodes: paginateFrom(items, start, end-start)},
+		Links: links{Next: next, TotalItems: len(items), PageSize: episodesPageSize},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func signedToken(t *testing.T, expiresAt time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestLoginTokenIsCachedAndRenewedOn401(t *testing.T) {
+	fake, p := newFakeTVDB(t)
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := p.Search(ctx, "breaking", metadata.SearchOpts{}); err != nil {
+			t.Fatalf("search: %v", err)
+		}
+	}
+	if got := fake.logins.Load(); got != 1 {
+		t.Fatalf("logins = %d, want 1", got)
+	}
+
+	fake.rejectNext.Store(true)
+	if _, err := p.Search(ctx, "breaking", metadata.SearchOpts{}); err != nil {
+		t.Fatalf("search after 401: %v", err)
+	}
+	if got := fake.logins.Load(); got != 2 {
+		t.Fatalf("logins after 401 = %d, want 2", got)
+	}
+}
+
+func TestTokenExpiryFromClaims(t *testing.T) {
+	now := time.Now()
+	exp := now.Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+	if got := tokenExpiry(signedToken(t, exp), now); !got.Equal(exp.Add(-tokenExpirySkew)) {
+		t.Fatalf("tokenExpiry = %v, want %v", got, exp.Add(-tokenExpirySkew))
+	}
+	if got := tokenExpiry("not-a-jwt", now); !got.Equal(now.Add(tokenTTL)) {
+		t.Fatalf("tokenExpiry fallback = %v, want %v", got, now.Add(tokenTTL))
+	}
+}
+
+func TestMissingAPIKey(t *testing.T) {
+	_, _ = newFakeTVDB(t)
+	t.Setenv("TVDB_API_KEY", "")
+
+	if _, err := New().Search(context.Background(), "breaking", metadata.SearchOpts{}); err == nil {
+		t.Fatal("expected error without TVDB_API_KEY")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	_, p := newFakeTVDB(t)
+
+	hits, err := p.Search(context.Background(), "breaking", metadata.SearchOpts{})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Fatalf("hits = %d, want 1", len(hits))
+	}
+
+	hit := hits[0]
+	if hit.ExternalID != "tvdb:81189" {
+		t.Errorf("ExternalID = %q", hit.ExternalID)
+	}
+	if hit.TitlePreferred != "Breaking Bad" || hit.Status != "finished" || hit.Type != "tv" {
+		t.Errorf("unexpected hit %+v", hit)
+	}
+	if len(hit.AltTitles) != 1 || hit.AltTitles[0] != "BB" {
+		t.Errorf("AltTitles = %v", hit.AltTitles)
+	}
+	if hit.Synopsis == nil || *hit.Synopsis != "A chemistry teacher turns to crime." {
+		t.Errorf("Synopsis = %v", hit.Synopsis)
+	}
+}
+
+func TestGetShow(t *testing.T) {
+	_, p := newFakeTVDB(t)
+
+	show, err := p.GetShow(context.Background(), "tvdb:81189")
+	if err != nil {
+		t.Fatalf("get show: %v", err)
+	}
+	if show.ExternalID != "tvdb:81189" || show.Status != "finished" {
+		t.Errorf("unexpected show %+v", show)
+	}
+	if show.BannerUrl == nil || *show.BannerUrl != "https://img/banner.jpg" {
+		t.Errorf("BannerUrl = %v", show.BannerUrl)
+	}
+	if show.PosterUrl == nil || *show.PosterUrl != "https://img/poster.jpg" {
+		t.Errorf("PosterUrl = %v", show.PosterUrl)
+	}
+	if show.EndDate == nil || *show.EndDate != "2013-09-29" {
+		t.Errorf("EndDate = %v", show.EndDate)
+	}
+	if show.SeasonCount == nil || *show.SeasonCount != 2 {
+		t.Errorf("SeasonCount = %v, want 2", show.SeasonCount)
+	}
+	if show.Synopsis == nil || *show.Synopsis != "A chemistry teacher turns to crime." {
+		t.Errorf("Synopsis = %v", show.Synopsis)
+	}
+	if got := strings.Join(show.LinkedIDs, ","); got != "imdb:tt0903747,tmdb:1396" {
+		t.Errorf("LinkedIDs = %q", got)
+	}
+
+	if _, err := p.GetShow(context.Background(), "tvdb:abc"); !errors.Is(err, metadata.ErrInvalidExternalID) {
+		t.Errorf("non-numeric id error = %v, want ErrInvalidExternalID", err)
+	}
+	if _, err := p.GetShow(context.Background(), "tvdb:404"); !errors.Is(err, metadata.ErrNotFound) {
+		t.Errorf("unknown series error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListEpisodes(t *testing.T) {
+	fake, p := newFakeTVDB(t)
+
+	// Unnumbered episodes fill the start of TVDB's first page and are dropped, so
+	// season 1 spans the page boundary after filtering; season 2 is short.
+	for i := int64(1); i <= 20; i++ {
+		fake.episodes = append(fake.episodes, episodeRecord{ID: 900 + i, SeasonNumber: 1})
+	}
+	for i := int64(1); i <= 510; i++ {
+		fake.episodes = append(fake.episodes, episodeRecord{ID: 1000 + i, SeasonNumber: 1, Number: i})
+	}
+	title := "Pilot"
+	fake.episodes[20].Name = &title
+	for i := int64(1); i <= 3; i++ {
+		fake.episodes = append(fake.episodes, episodeRecord{ID: 5000 + i, SeasonNumber: 2, Number: i})
+	}
+
+	tests := []struct {
+		name      string
+		opts      metadata.ListEpisodesOpts
+		wantLen   int
+		wantFirst [2]int64
+	}{
+		{name: "first page", opts: metadata.ListEpisodesOpts{Page: 1, Limit: 10}, wantLen: 10, wantFirst: [2]int64{1, 1}},
+		{name: "crosses tvdb page", opts: metadata.ListEpisodesOpts{Page: 5, Limit: 100}, wantLen: 100, wantFirst: [2]int64{1, 401}},
+		{name: "second tvdb page only", opts: metadata.ListEpisodesOpts{Page: 6, Limit: 100}, wantLen: 13, wantFirst: [2]int64{1, 501}},
+		{name: "season filter", opts: metadata.ListEpisodesOpts{Page: 1, Limit: 50, SeasonNumber: ptr(int64(2))}, wantLen: 3, wantFirst: [2]int64{2, 1}},
+		{name: "past the end", opts: metadata.ListEpisodesOpts{Page: 20, Limit: 50}, wantLen: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			episodes, err := p.ListEpisodes(context.Background(), "tvdb:81189", tt.opts)
+			if err != nil {
+				t.Fatalf("list episodes: %v", err)
+			}
+			if len(episodes) != tt.wantLen {
+				t.Fatalf("len = %d, want %d", len(episodes), tt.wantLen)
+			}
+			if tt.wantLen == 0 {
+				return
+			}
+			first := episodes[0]
+			if first.SeasonNumber != tt.wantFirst[0] || first.EpisodeNumber != tt.wantFirst[1] {
+				t.Errorf("first = S%dE%d, want S%dE%d", first.SeasonNumber, first.EpisodeNumber, tt.wantFirst[0], tt.wantFirst[1])
+			}
+			if first.Provider != metadata.ProviderTVDB {
+				t.Errorf("Provider = %q", first.Provider)
+			}
+		})
+	}
+
+	episodes, err := p.ListEpisodes(context.Background(), "tvdb:81189", metadata.ListEpisodesOpts{Page: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("list episodes: %v", err)
+	}
+	if episodes[0].Title != "Pilot" || episodes[1].Title != "Episode 2" {
+		t.Errorf("titles = %q, %q", episodes[0].Title, episodes[1].Title)
+	}
+	if episodes[0].ExternalID != "tvdb:1001" {
+		t.Errorf("ExternalID = %q", episodes[0].ExternalID)
+	}
+}
+
+func TestListAllEpisodesReadsEachPageOnce(t *testing.T) {
+	fake, p := newFakeTVDB(t)
+
+	for i := int64(1); i <= 3*episodesPageSize; i++ {
+		fake.episodes = append(fake.episodes, episodeRecord{ID: i, SeasonNumber: 1, Number: i})
+	}
+	fake.episodes = append(fake.episodes, episodeRecord{ID: 9000, SeasonNumber: 0})
+
+	episodes, err := p.ListAllEpisodes(context.Background(), "tvdb:81189")
+	if err != nil {
+		t.Fatalf("list all episodes: %v", err)
+	}
+	if len(episodes) != 3*episodesPageSize {
+		t.Fatalf("len = %d, want %d", len(episodes), 3*episodesPageSize)
+	}
+	if last := episodes[len(episodes)-1]; last.EpisodeNumber != 3*episodesPageSize {
+		t.Errorf("last = E%d, want E%d", last.EpisodeNumber, 3*episodesPageSize)
+	}
+	if got := fake.requests.Load(); got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	fake, p := newFakeTVDB(t)
+
+	result, err := p.Discover(context.Background(), metadata.DiscoverOpts{Page: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if got := fake.requests.Load(); got != 5 {
+		t.Errorf("filter requests = %d, want 5", got)
+	}
+
+	sections := map[string][]metadata.Show{
+		"trending":        result.Trending,
+		"popular":         result.Popular,
+		"topRated":        result.TopRated,
+		"upcoming":        result.Upcoming,
+		"currentlyAiring": result.CurrentlyAiring,
+	}
+	for name, items := range sections {
+		if len(items) != 2 {
+			t.Errorf("%s len = %d, want 2", name, len(items))
+		}
+	}
+	if result.Upcoming[0].TitlePreferred != fmt.Sprintf("Filter %d A", statusUpcoming) {
+		t.Errorf("upcoming title = %q", result.Upcoming[0].TitlePreferred)
+	}
+
+	page2, err := p.Discover(context.Background(), metadata.DiscoverOpts{Page: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("discover page 2: %v", err)
+	}
+	if len(page2.Popular) != 1 || page2.Popular[0].ExternalID != "tvdb:3" {
+		t.Errorf("popular page 2 = %+v", page2.Popular)
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -4,16 +4,146 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/transport"
 )
 
 type Provider struct {
-	baseURL string
-	apiKey  string
-	pin     string
-	client  *http.Client
-	debug   bool
+	baseURL         string
+	apiKey          string
+	pin             string
+	language        string
+	discoverCountry string
+	seasonType      string
+	client          *http.Client
+	upstream        *transport.Transport
 
 	mu         sync.Mutex
 	token      string
 	tokenUntil time.Time
 }
+
+type envelope[T any] struct {
+	Status string `json:"status"`
+	Data   T      `json:"data"`
+	Links  links  `json:"links"`
+}
+
+type links struct {
+	Next       *string `json:"next"`
+	TotalItems int     `json:"total_items"`
+	PageSize   int     `json:"page_size"`
+}
+
+type loginRequest struct {
+	APIKey string `json:"apikey"`
+	PIN    string `json:"pin,omitempty"`
+}
+
+type loginData struct {
+	Token string `json:"token"`
+}
+
+type searchResult struct {
+	ID           string            `json:"id"`
+	TVDBID       string            `json:"tvdb_id"`
+	Name         string            `json:"name"`
+	Slug         string            `json:"slug"`
+	Aliases      []string          `json:"aliases"`
+	Translations map[string]string `json:"translations"`
+	Overview     string            `json:"overview"`
+	Overviews    map[string]string `json:"overviews"`
+	PrimaryType  string            `json:"primary_type"`
+	Status       string            `json:"status"`
+	FirstAirTime string            `json:"first_air_time"`
+	ImageURL     string            `json:"image_url"`
+	Thumbnail    string            `json:"thumbnail"`
+	RemoteIDs    []remoteID        `json:"remote_ids"`
+}
+
+type seriesRecord struct {
+	ID           int64              `json:"id"`
+	Name         string             `json:"name"`
+	Slug         string             `json:"slug"`
+	Image        string             `json:"image"`
+	Overview     string             `json:"overview"`
+	Aliases      []seriesAlias      `json:"aliases"`
+	FirstAired   string             `json:"firstAired"`
+	LastAired    string             `json:"lastAired"`
+	Status       seriesStatus       `json:"status"`
+	Genres       []seriesGenre      `json:"genres"`
+	Artworks     []seriesArtwork    `json:"artworks"`
+	Seasons      []seriesSeason     `json:"seasons"`
+	Translations seriesTranslations `json:"translations"`
+	RemoteIDs    []remoteID         `json:"remoteIds"`
+}
+
+type remoteID struct {
+	ID         string `json:"id"`
+	Type       int64  `json:"type"`
+	SourceName string `json:"sourceName"`
+}
+
+type seriesAlias struct {
+	Language string `json:"language"`
+	Name     string `json:"name"`
+}
+
+type seriesStatus struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type seriesGenre struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type seriesArtwork struct {
+	Image     string `json:"image"`
+	Thumbnail string `json:"thumbnail"`
+	Type      int64  `json:"type"`
+}
+
+type seriesSeason struct {
+	Number int64            `json:"number"`
+	Type   seriesSeasonType `json:"type"`
+}
+
+type seriesSeasonType struct {
+	Type string `json:"type"`
+}
+
+type seriesTranslations struct {
+	NameTranslations     []translation `json:"nameTranslations"`
+	OverviewTranslations []translation `json:"overviewTranslations"`
+}
+
+type translation struct {
+	Language  string `json:"language"`
+	Name      string `json:"name"`
+	Overview  string `json:"overview"`
+	IsPrimary bool   `json:"isPrimary"`
+}
+
+type episodesData struct {
+	Episodes []episodeRecord `json:"episodes"`
+}
+
+type episodeRecord struct {
+	ID             int64   `json:"id"`
+	Name           *string `json:"name"`
+	Aired          *string `json:"aired"`
+	Runtime        *int64  `json:"runtime"`
+	SeasonNumber   int64   `json:"seasonNumber"`
+	Number         int64   `json:"number"`
+	AbsoluteNumber *int64  `json:"absoluteNumber"`
+}
+
+// discoverFilter is one /series/filter query backing a Discover section.
+type discoverFilter struct {
+	sort     string
+	sortType string
+	status   int
+	year     int
+}
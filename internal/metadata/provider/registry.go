@@ -1,6 +1,9 @@
 package metadata
 
-import "fmt"
+import (
+	"sort"
+	"strings"
+)
 
 func NewRegistry(providers map[ProviderName]Provider) *Registry {
 	copied := make(map[ProviderName]Provider, len(providers))
@@ -13,7 +16,50 @@ func NewRegistry(providers map[ProviderName]Provider) *Registry {
 func (r *Registry) Provider(name ProviderName) (Provider, error) {
 	provider, ok := r.providers[name]
 	if !ok {
-		return nil, fmt.Errorf("metadata provider %q is not supported", name)
+		return nil, Errorf(ErrUnsupported, "metadata provider %q is not supported", name)
 	}
 	return provider, nil
 }
+
+// Names returns the registered provider names in alphabetical order.
+func (r *Registry) Names() []ProviderName {
+	names := make([]ProviderName, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// CapabilitiesOf returns the capabilities p reports. A provider that is not a Describer
+// is assumed to support every Provider operation.
+func CapabilitiesOf(p Provider) Capabilities {
+	if describer, ok := p.(Describer); ok {
+		return describer.Capabilities()
+	}
+	_, related := p.(RelationLister)
+	return Capabilities{Search: true, Discover: true, GetShow: true, ListEpisodes: true, Related: related, Configured: true}
+}
+
+// HealthOf returns the health p reports, or the zero Health when it is not a Describer.
+func HealthOf(p Provider) Health {
+	if describer, ok := p.(Describer); ok {
+		return describer.Health()
+	}
+	return Health{}
+}
+
+// ProviderFromExternalID derives the provider from a prefixed external id such as "anilist:123".
+func ProviderFromExternalID(externalID string) (ProviderName, error) {
+	prefix, _, ok := strings.Cut(strings.TrimSpace(externalID), ":")
+	if !ok {
+		return "", Errorf(ErrInvalidExternalID, "external id %q is missing a provider prefix", externalID)
+	}
+
+	switch name := ProviderName(strings.ToLower(strings.TrimSpace(prefix))); name {
+	case ProviderAniDB, ProviderAniList, ProviderTVDB:
+		return name, nil
+	default:
+		return "", Errorf(ErrUnsupported, "metadata provider %q is not supported", name)
+	}
+}
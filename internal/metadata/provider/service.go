@@ -1,6 +1,11 @@
 package metadata
 
-import "context"
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
 
 func NewService(registry *Registry) *Service {
 	return &Service{registry: registry}
@@ -14,6 +19,57 @@ func (s *Service) Search(ctx context.Context, providerName ProviderName, query s
 	return provider.Search(ctx, query, opts)
 }
 
+// Providers returns the names of every registered provider.
+func (s *Service) Providers() []ProviderName {
+	return s.registry.Names()
+}
+
+// Describe reports the capabilities and upstream health of every registered provider.
+func (s *Service) Describe() []ProviderDescription {
+	names := s.registry.Names()
+	items := make([]ProviderDescription, 0, len(names))
+	for _, name := range names {
+		provider, err := s.registry.Provider(name)
+		if err != nil {
+			continue
+		}
+		capabilities := CapabilitiesOf(provider)
+		if capabilities.IDPrefix == "" {
+			capabilities.IDPrefix = string(name) + ":"
+		}
+		items = append(items, ProviderDescription{
+			Provider:     name,
+			Capabilities: capabilities,
+			Health:       HealthOf(provider),
+		})
+	}
+	return items
+}
+
+// SearchEach queries every named provider concurrently, each bounded by timeout, and
+// returns one outcome per provider in the order given. A failing provider does not
+// affect the others.
+func (s *Service) SearchEach(ctx context.Context, providerNames []ProviderName, query string, opts SearchOpts, timeout time.Duration) []ProviderSearch {
+	results := make([]ProviderSearch, len(providerNames))
+	var wg sync.WaitGroup
+	for i, name := range providerNames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			providerCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			hits, err := s.Search(providerCtx, name, query, opts)
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				err = Errorf(ErrUpstreamUnavailable, "%s search timed out after %s", name, timeout)
+			}
+			results[i] = ProviderSearch{Provider: name, Hits: hits, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
 func (s *Service) Discover(ctx context.Context, providerName ProviderName, opts DiscoverOpts) (DiscoverResult, error) {
 	provider, err := s.registry.Provider(providerName)
 	if err != nil {
@@ -37,3 +93,31 @@ func (s *Service) ListEpisodes(ctx context.Context, providerName ProviderName, e
 	}
 	return provider.ListEpisodes(ctx, externalID, opts)
 }
+
+// ListAllEpisodes returns every episode of the show from providers that implement
+// AllEpisodesLister.
+func (s *Service) ListAllEpisodes(ctx context.Context, providerName ProviderName, externalID string) ([]Episode, error) {
+	provider, err := s.registry.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := provider.(AllEpisodesLister)
+	if !ok {
+		return nil, Errorf(ErrUnsupported, "%s does not list all episodes at once", providerName)
+	}
+	return lister.ListAllEpisodes(ctx, externalID)
+}
+
+// ListRelations returns the show's franchise relations from providers that implement
+// RelationLister.
+func (s *Service) ListRelations(ctx context.Context, providerName ProviderName, externalID string) (ShowRelations, error) {
+	provider, err := s.registry.Provider(providerName)
+	if err != nil {
+		return ShowRelations{}, err
+	}
+	lister, ok := provider.(RelationLister)
+	if !ok {
+		return ShowRelations{}, Errorf(ErrUnsupported, "%s does not support related shows", providerName)
+	}
+	return lister.ListRelations(ctx, externalID)
+}
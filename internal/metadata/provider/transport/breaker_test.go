@@ -0,0 +1,149 @@
+package transport
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	const openTimeout = 30 * time.Second
+
+	type step struct {
+		// action is allow, success, failure or release.
+		action string
+		at     time.Duration
+		// wantAllow is checked for allow steps.
+		wantAllow bool
+	}
+	tests := []struct {
+		name      string
+		steps     []step
+		wantState breakerState
+	}{
+		{
+			name: "failures below the threshold stay closed",
+			steps: []step{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow", wantAllow: true},
+			},
+			wantState: breakerClosed,
+		},
+		{
+			name: "a success resets the failure count",
+			steps: []step{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "success"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow", wantAllow: true},
+			},
+			wantState: breakerClosed,
+		},
+		{
+			name: "threshold failures open the circuit",
+			steps: []step{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow", at: openTimeout - time.Second, wantAllow: false},
+			},
+			wantState: breakerOpen,
+		},
+		{
+			name: "after the open timeout one trial goes through",
+			steps: []step{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow", at: openTimeout, wantAllow: true},
+				{action: "allow", at: openTimeout, wantAllow: false},
+			},
+			wantState: breakerHalfOpen,
+		},
+		{
+			name: "a successful trial closes the circuit",
+			steps: []step{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow", at: openTimeout, wantAllow: true},
+				{action: "success", at: openTimeout},
+				{action: "allow", at: openTimeout, wantAllow: true},
+				{action: "allow", at: openTimeout, wantAllow: true},
+			},
+			wantState: breakerClosed,
+		},
+		{
+			name: "a failed trial reopens the circuit",
+			steps: []step{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow", at: openTimeout, wantAllow: true},
+				{action: "failure", at: openTimeout},
+				{action: "allow", at: openTimeout + time.Second, wantAllow: false},
+			},
+			wantState: breakerOpen,
+		},
+		{
+			name: "a released trial lets the next request probe",
+			steps: []step{
+				{action: "failure"},
+				{action: "failure"},
+				{action: "failure"},
+				{action: "allow", at: openTimeout, wantAllow: true},
+				{action: "release", at: openTimeout},
+				{action: "allow", at: openTimeout, wantAllow: true},
+			},
+			wantState: breakerHalfOpen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			b := newBreaker(3, openTimeout)
+			for i, s := range tt.steps {
+				now := start.Add(s.at)
+				switch s.action {
+				case "allow":
+					if got, _ := b.allow(now); got != s.wantAllow {
+						t.Fatalf("step %d: allow = %v, want %v", i, got, s.wantAllow)
+					}
+				case "success":
+					b.success()
+				case "failure":
+					b.failure(now)
+				case "release":
+					b.release()
+				default:
+					t.Fatalf("step %d: unknown action %q", i, s.action)
+				}
+			}
+			if b.state != tt.wantState {
+				t.Errorf("state = %v, want %v", b.state, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestBreakerReportsRemainingOpenTime(t *testing.T) {
+	start := time.Now()
+	b := newBreaker(1, 30*time.Second)
+	b.failure(start)
+
+	ok, openFor := b.allow(start.Add(10 * time.Second))
+	if ok {
+		t.Fatalf("allow = true, want false")
+	}
+	if openFor != 20*time.Second {
+		t.Errorf("openFor = %s, want 20s", openFor)
+	}
+	if !b.isOpen(start.Add(10 * time.Second)) {
+		t.Errorf("isOpen = false, want true")
+	}
+	if b.isOpen(start.Add(30 * time.Second)) {
+		t.Errorf("isOpen after the timeout = true, want false")
+	}
+}
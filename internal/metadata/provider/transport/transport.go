@@ -0,0 +1,275 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	metadata "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"golang.org/x/time/rate"
+)
+
+func New(provider string, opts Options) *Transport {
+	opts = normalizeOptions(opts)
+	t := &Transport{
+		provider: provider,
+		opts:     opts,
+		base:     opts.Base,
+		breaker:  newBreaker(opts.FailureThreshold, opts.OpenTimeout),
+	}
+	if opts.RequestsPerMinute > 0 {
+		t.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(opts.RequestsPerMinute)), opts.Burst)
+	}
+	return t
+}
+
+// Client returns an http.Client that sends through t with the given overall timeout,
+// which includes the time spent waiting for the limiter and between retries.
+func (t *Transport) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: t, Timeout: timeout}
+}
+
+// RoundTrip sends req, retrying 429 and 5xx responses with jittered exponential backoff
+// or the provider's Retry-After. When the retries run out, or the wait would outlast the
+// request's deadline, it returns an *UpstreamError instead of the response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ok, openFor := t.breaker.allow(time.Now()); !ok {
+		return nil, &UpstreamError{Provider: t.provider, RetryAfter: openFor, CircuitOpen: true}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx); err != nil {
+			t.breaker.release()
+			return nil, err
+		}
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			t.breaker.release()
+			return nil, err
+		}
+		sentAt := time.Now()
+		res, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			if ctx.Err() != nil {
+				t.breaker.release()
+			} else {
+				t.record(sentAt, err.Error())
+				t.breaker.failure(time.Now())
+			}
+			return nil, err
+		}
+		if retryable(res.StatusCode) {
+			t.record(sentAt, fmt.Sprintf("status %d", res.StatusCode))
+		} else {
+			t.record(sentAt, "")
+		}
+		if !retryable(res.StatusCode) {
+			t.breaker.success()
+			return res, nil
+		}
+
+		hint := parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		delay := hint
+		if delay <= 0 {
+			delay = t.backoff(attempt)
+		}
+		if attempt >= t.opts.MaxRetries || delay > t.opts.MaxRetryWait || !fitsDeadline(ctx, delay) || !replayable(req) {
+			t.breaker.failure(time.Now())
+			return nil, &UpstreamError{Provider: t.provider, StatusCode: res.StatusCode, RetryAfter: hint}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			t.breaker.release()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Health reports the latency and outcome of the last request sent upstream, the last
+// failure, and whether the circuit is open.
+func (t *Transport) Health() metadata.Health {
+	t.mu.Lock()
+	health := t.health
+	t.mu.Unlock()
+
+	health.CircuitOpen = t.breaker.isOpen(time.Now())
+	return health
+}
+
+// record notes one upstream attempt; failure is empty when the provider answered
+// without a retryable error.
+func (t *Transport) record(sentAt time.Time, failure string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.health.LastRequestAt = sentAt
+	t.health.LastLatency = time.Since(sentAt)
+	if failure != "" {
+		t.health.LastErrorAt = sentAt
+		t.health.LastError = failure
+	}
+}
+
+// wait takes a token from the provider's bucket. A wait that would outlast the request's
+// deadline fails at once as a 429, so the caller gets a retry hint instead of a timeout.
+func (t *Transport) wait(ctx context.Context) error {
+	if t.limiter == nil {
+		return nil
+	}
+
+	reservation := t.limiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if !fitsDeadline(ctx, delay) {
+		reservation.Cancel()
+		return &UpstreamError{Provider: t.provider, StatusCode: http.StatusTooManyRequests, RetryAfter: delay}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff is BaseBackoff doubled per attempt and capped at MaxBackoff, with jitter over
+// its upper half so concurrent retries spread out.
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.opts.MaxBackoff
+	if attempt < 32 {
+		delay = min(t.opts.BaseBackoff<<attempt, t.opts.MaxBackoff)
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+func (e *UpstreamError) Error() string {
+	switch {
+	case e.CircuitOpen:
+		return fmt.Sprintf("%s is unavailable after repeated failures, retry after %s", e.Provider, roundUp(e.RetryAfter))
+	case e.Throttled() && e.RetryAfter > 0:
+		return fmt.Sprintf("%s is rate limiting requests, retry after %s", e.Provider, roundUp(e.RetryAfter))
+	case e.Throttled():
+		return fmt.Sprintf("%s is rate limiting requests", e.Provider)
+	default:
+		return fmt.Sprintf("%s request failed with status %d", e.Provider, e.StatusCode)
+	}
+}
+
+// Is lets errors.Is classify the error as metadata.ErrRateLimited when the provider is
+// throttling and as metadata.ErrUpstreamUnavailable otherwise.
+func (e *UpstreamError) Is(target error) bool {
+	if e.Throttled() {
+		return target == metadata.ErrRateLimited
+	}
+	return target == metadata.ErrUpstreamUnavailable
+}
+
+// Throttled reports whether the provider answered 429 Too Many Requests.
+func (e *UpstreamError) Throttled() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// roundUp rounds d up to whole seconds, so a hint never reads as "retry after 0s".
+func roundUp(d time.Duration) time.Duration {
+	return time.Duration(math.Ceil(d.Seconds())) * time.Second
+}
+
+func normalizeOptions(opts Options) Options {
+	if opts.Burst <= 0 {
+		opts.Burst = 1
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.MaxRetryWait <= 0 {
+		opts.MaxRetryWait = defaultMaxRetryWait
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = defaultFailureThreshold
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = defaultOpenTimeout
+	}
+	if opts.Base == nil {
+		opts.Base = http.DefaultTransport
+	}
+	return opts
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// rewind returns the request for an attempt; retries get a fresh copy of the body.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func fitsDeadline(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > wait
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or HTTP-date form.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
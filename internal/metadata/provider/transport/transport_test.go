@@ -0,0 +1,200 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metadata "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+)
+
+// fakeUpstream answers with statuses in order, repeating the last one, and counts calls.
+type fakeUpstream struct {
+	statuses   []int
+	retryAfter string
+	calls      atomic.Int32
+}
+
+func (f *fakeUpstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := int(f.calls.Add(1)) - 1
+	status := f.statuses[min(call, len(f.statuses)-1)]
+	header := http.Header{}
+	if f.retryAfter != "" {
+		header.Set("Retry-After", f.retryAfter)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func newRequest(t *testing.T, ctx context.Context) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://provider.test/item", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	return req
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "seconds with spaces", value: " 7 ", want: 7 * time.Second},
+		{name: "negative seconds", value: "-5", want: 0},
+		{name: "http date", value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{name: "http date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{name: "garbage", value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTripRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		maxRetries int
+		wantStatus int
+		wantErr    error
+		wantHint   time.Duration
+		wantCalls  int32
+	}{
+		{name: "success", statuses: []int{200}, wantStatus: 200, wantCalls: 1},
+		{name: "client errors are not retried", statuses: []int{404}, wantStatus: 404, wantCalls: 1},
+		{name: "5xx is retried", statuses: []int{503, 502, 200}, wantStatus: 200, wantCalls: 3},
+		{name: "short retry-after is waited out", statuses: []int{429, 200}, retryAfter: "0", wantStatus: 200, wantCalls: 2},
+		{name: "retries run out", statuses: []int{500}, maxRetries: 2, wantErr: metadata.ErrUpstreamUnavailable, wantCalls: 3},
+		{name: "retries disabled", statuses: []int{500}, maxRetries: -1, wantErr: metadata.ErrUpstreamUnavailable, wantCalls: 1},
+		{
+			name:       "long retry-after fails at once with the hint",
+			statuses:   []int{429, 200},
+			retryAfter: "120",
+			wantErr:    metadata.ErrRateLimited,
+			wantHint:   2 * time.Minute,
+			wantCalls:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &fakeUpstream{statuses: tt.statuses, retryAfter: tt.retryAfter}
+			tr := New("test", Options{
+				MaxRetries:   tt.maxRetries,
+				BaseBackoff:  time.Millisecond,
+				MaxBackoff:   time.Millisecond,
+				MaxRetryWait: time.Second,
+				Base:         upstream,
+			})
+
+			res, err := tr.RoundTrip(newRequest(t, context.Background()))
+			if got := upstream.calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				var upstreamErr *UpstreamError
+				if !errors.As(err, &upstreamErr) {
+					t.Fatalf("err = %T, want *UpstreamError", err)
+				}
+				if upstreamErr.RetryAfter != tt.wantHint {
+					t.Errorf("RetryAfter = %s, want %s", upstreamErr.RetryAfter, tt.wantHint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("round trip: %v", err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRoundTripOpensCircuit(t *testing.T) {
+	upstream := &fakeUpstream{statuses: []int{500}}
+	tr := New("test", Options{MaxRetries: -1, FailureThreshold: 2, OpenTimeout: time.Minute, Base: upstream})
+
+	for range 2 {
+		if _, err := tr.RoundTrip(newRequest(t, context.Background())); !errors.Is(err, metadata.ErrUpstreamUnavailable) {
+			t.Fatalf("err = %v, want ErrUpstreamUnavailable", err)
+		}
+	}
+
+	_, err := tr.RoundTrip(newRequest(t, context.Background()))
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) || !upstreamErr.CircuitOpen {
+		t.Fatalf("err = %v, want an open circuit", err)
+	}
+	if upstream.calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", upstream.calls.Load())
+	}
+	if !tr.Health().CircuitOpen {
+		t.Errorf("Health().CircuitOpen = false, want true")
+	}
+}
+
+func TestRoundTripTokenBucket(t *testing.T) {
+	upstream := &fakeUpstream{statuses: []int{200}}
+	tr := New("test", Options{RequestsPerMinute: 60, Burst: 2, Base: upstream})
+
+	for i := range 2 {
+		res, err := tr.RoundTrip(newRequest(t, context.Background()))
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		res.Body.Close()
+	}
+
+	// The bucket is empty and refills once a second, which a 100ms deadline cannot wait for.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := tr.RoundTrip(newRequest(t, ctx))
+	if !errors.Is(err, metadata.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if waited := time.Since(started); waited > 50*time.Millisecond {
+		t.Errorf("waited %s, want an immediate failure", waited)
+	}
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) && (upstreamErr.RetryAfter <= 0 || upstreamErr.RetryAfter > time.Second) {
+		t.Errorf("RetryAfter = %s, want up to 1s", upstreamErr.RetryAfter)
+	}
+	if upstream.calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", upstream.calls.Load())
+	}
+
+	// A refused request gives its token back, so the next one waits a single interval.
+	res, err := tr.RoundTrip(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatalf("request after refill: %v", err)
+	}
+	res.Body.Close()
+	if waited := time.Since(started); waited > 1500*time.Millisecond {
+		t.Errorf("waited %s for one token, want about 1s", waited)
+	}
+}
@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	metadata "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultMaxRetries       = 3
+	defaultBaseBackoff      = 500 * time.Millisecond
+	defaultMaxBackoff       = 10 * time.Second
+	defaultMaxRetryWait     = 30 * time.Second
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type Options struct {
+	// RequestsPerMinute is the provider's token bucket rate. Zero disables the limiter.
+	RequestsPerMinute int
+	Burst             int
+	// MaxRetries is how many times a 429 or 5xx response is retried; negative disables retries.
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxRetryWait is the longest Retry-After that is waited out; a longer one fails
+	// the request at once so the caller can pass the hint on.
+	MaxRetryWait time.Duration
+	// FailureThreshold is the number of consecutive failed requests that opens the
+	// circuit; while open, requests fail without reaching the provider for OpenTimeout.
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	// Base sends the requests. Nil uses http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// Transport is an http.RoundTripper that rate limits, retries and circuit-breaks the
+// requests to one metadata provider.
+type Transport struct {
+	provider string
+	opts     Options
+	base     http.RoundTripper
+	limiter  *rate.Limiter
+	breaker  *breaker
+
+	mu     sync.Mutex
+	health metadata.Health
+}
+
+// UpstreamError reports a provider that is throttling us or is unavailable.
+// StatusCode is the provider's last status, or zero when the circuit is open.
+type UpstreamError struct {
+	Provider    string
+	StatusCode  int
+	RetryAfter  time.Duration
+	CircuitOpen bool
+}
+
+type breaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	// probing is set while the single half-open trial request is in flight.
+	probing bool
+}
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"context"
+	"time"
 
 	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
 )
@@ -14,6 +15,30 @@ const (
 	ProviderTVDB    ProviderName = "tvdb"
 )
 
+// Episode types. Providers without the distinction report regular or special by season.
+const (
+	EpisodeTypeRegular = "regular"
+	EpisodeTypeSpecial = "special"
+	EpisodeTypeOpening = "op"
+	EpisodeTypeEnding  = "ed"
+	EpisodeTypeCredit  = "credit"
+	EpisodeTypeTrailer = "trailer"
+	EpisodeTypeParody  = "parody"
+	EpisodeTypeOther   = "other"
+)
+
+// Relation types reported by RelationLister, read as "the related show is the root's ...".
+const (
+	RelationSequel      = "sequel"
+	RelationPrequel     = "prequel"
+	RelationSideStory   = "side_story"
+	RelationParent      = "parent"
+	RelationSpinOff     = "spin_off"
+	RelationAlternative = "alternative"
+	RelationSummary     = "summary"
+	RelationOther       = "other"
+)
+
 type SearchOpts struct {
 	Page  int
 	Limit int
@@ -48,6 +73,8 @@ type Episode struct {
 	SeasonNumber   int64        `json:"seasonNumber"`
 	EpisodeNumber  int64        `json:"episodeNumber"`
 	Title          string       `json:"title"`
+	Type           string       `json:"type,omitempty"`
+	AbsoluteNumber *int64       `json:"absoluteNumber,omitempty"`
 	AirDate        *string      `json:"airDate,omitempty"`
 	RuntimeMinutes *int64       `json:"runtimeMinutes,omitempty"`
 }
@@ -59,6 +86,91 @@ type Provider interface {
 	ListEpisodes(ctx context.Context, externalID string, opts ListEpisodesOpts) ([]Episode, error)
 }
 
+// RelationLister is an optional companion to Provider for adapters that know which
+// shows belong to the same franchise.
+type RelationLister interface {
+	ListRelations(ctx context.Context, externalID string) (ShowRelations, error)
+}
+
+// AllEpisodesLister is an optional companion to Provider for adapters that can return
+// every episode of a show in one walk of their upstream, which is cheaper than asking
+// ListEpisodes for one page after another.
+type AllEpisodesLister interface {
+	ListAllEpisodes(ctx context.Context, externalID string) ([]Episode, error)
+}
+
+// ShowRelations is a show with its direct franchise relations. Format is the provider's
+// lower case media format, e.g. tv, movie or ova, when it reports one.
+type ShowRelations struct {
+	Show      Show
+	Format    string
+	Relations []Relation
+}
+
+// Relation is one edge of the franchise graph; Type is one of the Relation* constants.
+type Relation struct {
+	Type   string
+	Show   Show
+	Format string
+}
+
+// Describer is an optional companion to Provider for adapters that can say what they
+// support and how their upstream is doing. Use CapabilitiesOf and HealthOf rather than
+// asserting it directly.
+type Describer interface {
+	Capabilities() Capabilities
+	Health() Health
+}
+
+type Capabilities struct {
+	// IDPrefix is the prefix of the provider's external ids, e.g. "anilist:".
+	IDPrefix     string
+	Search       bool
+	Discover     bool
+	GetShow      bool
+	ListEpisodes bool
+	// Related is set when the provider implements RelationLister.
+	Related bool
+	// SeasonFilter is set when ListEpisodes honours ListEpisodesOpts.SeasonNumber.
+	SeasonFilter bool
+	// EpisodeTitles is set when episodes carry real titles rather than "Episode N".
+	EpisodeTitles bool
+	Configured    bool
+	// ConfigError says what is missing when Configured is false.
+	ConfigError string
+}
+
+// Health is the outcome of the provider's most recent upstream requests. Zero times
+// mean nothing has been sent or nothing has failed yet.
+type Health struct {
+	LastRequestAt time.Time
+	LastLatency   time.Duration
+	LastErrorAt   time.Time
+	LastError     string
+	CircuitOpen   bool
+}
+
+// ProviderDescription is one registered provider's capabilities and health.
+type ProviderDescription struct {
+	Provider     ProviderName
+	Capabilities Capabilities
+	Health       Health
+}
+
+// Error is a provider failure of a known Kind, one of the Err* sentinels, carrying the
+// adapter's own message.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// ProviderSearch is one provider's outcome in a fan-out search: its hits or its error.
+type ProviderSearch struct {
+	Provider ProviderName
+	Hits     []SearchHit
+	Err      error
+}
+
 type Registry struct {
 	providers map[ProviderName]Provider
 }
@@ -0,0 +1,155 @@
+package metadata
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/cache"
+	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
+)
+
+func NewRefresher(svc *Service, interval time.Duration) *Refresher {
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	return &Refresher{svc: svc, interval: interval}
+}
+
+// Run refreshes ongoing shows once at startup and then on every interval until ctx is cancelled.
+func (r *Refresher) Run(ctx context.Context) {
+	log.Printf("metadata refresher started interval=%s", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("metadata refresher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Refresher) runOnce(ctx context.Context) {
+	summary, err := r.svc.RefreshOngoingShows(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("metadata refresher: %v", err)
+		}
+		return
+	}
+	if summary.Skipped {
+		log.Printf("metadata refresher: another instance holds the refresh lock, skipping")
+		return
+	}
+	log.Printf("metadata refresher: shows=%d updated=%d finished=%d episodesAdded=%d episodesUpdated=%d failed=%d",
+		summary.Shows, summary.Updated, summary.Finished, summary.EpisodesAdded, summary.EpisodesUpdated, summary.Failed)
+}
+
+// RefreshOngoingShows refreshes every show whose status is ongoing. A failing show is
+// logged and counted; it does not stop the pass.
+func (s *Service) RefreshOngoingShows(ctx context.Context) (RefreshSummary, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return RefreshSummary{}, err
+	}
+	defer conn.Release()
+
+	// The lock belongs to the session, so it is taken and released on the same connection.
+	q := sqlc.New(conn)
+	locked, err := q.TryLockShowsRefresh(ctx, refreshLockKey)
+	if err != nil {
+		return RefreshSummary{}, err
+	}
+	if !locked {
+		return RefreshSummary{Skipped: true}, nil
+	}
+	defer func() {
+		if _, err := q.UnlockShowsRefresh(context.Background(), refreshLockKey); err != nil {
+			log.Printf("metadata refresher failed to release lock: %v", err)
+		}
+	}()
+
+	shows, err := s.showSvc.ListShowsByStatus(ctx, showmodel.StatusOngoing)
+	if err != nil {
+		return RefreshSummary{}, err
+	}
+
+	summary := RefreshSummary{Shows: len(shows)}
+	for _, item := range shows {
+		if ctx.Err() != nil {
+			return summary, ctx.Err()
+		}
+
+		result, err := s.RefreshShow(ctx, item)
+		if err != nil {
+			summary.Failed++
+			log.Printf("metadata refresher: show %s: %v", item.InternalShowID, err)
+			continue
+		}
+		if result.Updated {
+			summary.Updated++
+		}
+		if result.Finished {
+			summary.Finished++
+		}
+		summary.EpisodesAdded += result.EpisodesAdded
+		summary.EpisodesUpdated += result.EpisodesUpdated
+	}
+	return summary, nil
+}
+
+// RefreshShow re-fetches a stored show from its provider, saves changed fields, links
+// provider ids it did not know yet and syncs its episodes: new ones are inserted and
+// stored ones take the provider's changes, e.g. a title or air date announced late.
+func (s *Service) RefreshShow(ctx context.Context, item sqlc.Show) (RefreshResult, error) {
+	result := RefreshResult{InternalShowID: item.InternalShowID}
+
+	provider, externalID, err := showProviderRef(item)
+	if err != nil {
+		return result, err
+	}
+
+	// A refresh exists to see provider changes, so it must not be answered from the cache.
+	ctx = cache.WithoutCache(ctx)
+	latest, err := s.worker.GetShow(ctx, provider, externalID)
+	if err != nil {
+		return result, err
+	}
+	episodes, err := s.listAllEpisodes(ctx, provider, externalID)
+	if err != nil {
+		return result, err
+	}
+
+	current, err := showmodel.ToShow(item)
+	if err != nil {
+		return result, err
+	}
+	if err := s.showSvc.LinkExternalIDs(ctx, item.InternalShowID, showmodel.Show(latest)); err != nil {
+		return result, err
+	}
+	merged := showmodel.MergeShow(current, showmodel.Show(latest))
+	if !reflect.DeepEqual(current, merged) {
+		if _, err := s.showSvc.UpdateShow(ctx, item.InternalShowID, merged); err != nil {
+			return result, err
+		}
+		result.Updated = true
+		result.Finished = current.Status != showmodel.StatusFinished && merged.Status == showmodel.StatusFinished
+	}
+
+	synced, err := s.episodeSvc.SyncEpisodes(ctx, item.InternalShowID, toImportEpisodes(episodes))
+	if err != nil {
+		return result, err
+	}
+	result.EpisodesAdded = len(synced.Created)
+	result.EpisodesUpdated = len(synced.Updated)
+
+	return result, nil
+}
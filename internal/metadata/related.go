@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"context"
+	"log"
+
+	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
+)
+
+// RelatedShows walks the provider's franchise relations breadth first from externalID,
+// following them up to depth hops and stopping at maxRelatedShows shows. Every show is
+// marked with the local show it is linked to. A related show whose own relations cannot
+// be loaded stays in the graph and the result is marked Truncated.
+func (s *Service) RelatedShows(ctx context.Context, provider worker.ProviderName, externalID string, depth int) (RelatedGraph, error) {
+	root, err := s.worker.ListRelations(ctx, provider, externalID)
+	if err != nil {
+		return RelatedGraph{}, err
+	}
+
+	graph := RelatedGraph{Root: root.Show.ExternalID}
+	index := map[string]int{root.Show.ExternalID: 0}
+	graph.Nodes = append(graph.Nodes, RelatedShow{Show: root.Show, Format: root.Format})
+	edges := make(map[relatedEdgeKey]struct{})
+
+	frontier := []worker.ShowRelations{root}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		added := make([]string, 0)
+		for _, item := range frontier {
+			from := item.Show.ExternalID
+			for _, relation := range item.Relations {
+				to := relation.Show.ExternalID
+				if _, ok := index[to]; !ok {
+					if len(graph.Nodes) >= maxRelatedShows {
+						graph.Truncated = true
+						continue
+					}
+					index[to] = len(graph.Nodes)
+					graph.Nodes = append(graph.Nodes, RelatedShow{Show: relation.Show, Format: relation.Format, Depth: level})
+					added = append(added, to)
+				}
+
+				key := relatedEdgeKey{from: from, to: to}
+				if _, ok := edges[key]; ok {
+					continue
+				}
+				edges[key] = struct{}{}
+				graph.Edges = append(graph.Edges, RelatedEdge{From: from, To: to, Type: relation.Type})
+			}
+		}
+
+		frontier = nil
+		if level == depth {
+			break
+		}
+		for _, id := range added {
+			item, err := s.worker.ListRelations(ctx, provider, id)
+			if err != nil {
+				if ctx.Err() != nil {
+					return RelatedGraph{}, ctx.Err()
+				}
+				log.Printf("metadata related: relations of %s unavailable: %v", id, err)
+				graph.Truncated = true
+				continue
+			}
+			frontier = append(frontier, item)
+		}
+	}
+
+	if err := s.markRelatedInLibrary(ctx, graph.Nodes); err != nil {
+		return RelatedGraph{}, err
+	}
+	return graph, nil
+}
+
+// markRelatedInLibrary sets InternalShowID on every node linked to a local show through
+// its own or a linked external id.
+func (s *Service) markRelatedInLibrary(ctx context.Context, nodes []RelatedShow) error {
+	refs := make([]showmodel.ExternalRef, 0, len(nodes))
+	for _, node := range nodes {
+		refs = append(refs, showmodel.ShowExternalRefs(node.Show)...)
+	}
+	known, err := s.showSvc.KnownExternalIDs(ctx, refs)
+	if err != nil {
+		return err
+	}
+
+	showByRef := make(map[string]string, len(known))
+	for _, row := range known {
+		showByRef[row.Provider+":"+row.ExternalID] = row.ShowID
+	}
+	for i := range nodes {
+		for _, ref := range showmodel.ShowExternalRefs(nodes[i].Show) {
+			if showID, ok := showByRef[ref.String()]; ok {
+				nodes[i].InternalShowID = &showID
+				break
+			}
+		}
+	}
+	return nil
+}
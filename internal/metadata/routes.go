@@ -1,11 +1,20 @@
 package metadata
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
 
 func RegisterRoutes(r *gin.Engine, h *Handler) {
-	r.GET("/metadata/search", h.BindSearch(), h.Search)
-	r.GET("/metadata/discover", h.BindDiscover(), h.Discover)
-	r.GET("/metadata/show/:externalId", h.BindExternalID(), h.GetShow)
-	r.POST("/metadata/show/:externalId", h.BindExternalID(), h.AddShow)
-	r.GET("/metadata/episodes/:externalId", h.BindExternalID(), h.BindEpisodesOpts(), h.ListEpisodes)
+	r.GET("/metadata/providers", h.ListProviders)
+	r.GET("/metadata/search", h.CacheHeader(), h.BindSearch(), h.Search)
+	r.GET("/metadata/discover", h.CacheHeader(), h.BindDiscover(), h.Discover)
+	r.GET("/metadata/show/:externalId", h.CacheHeader(), h.BindExternalID(), h.GetShow)
+	r.POST("/metadata/show/:externalId", h.BindExternalID(), h.BindOnConflict(), h.AddShow)
+	r.POST("/metadata/show/:externalId/async", h.BindExternalID(), h.BindOnConflict(), h.AddShowAsync)
+	r.GET("/metadata/show/:externalId/related", h.CacheHeader(), h.BindExternalID(), h.BindRelatedDepth(), h.RelatedShows)
+	r.POST("/metadata/:action", httpx.RequireParam("action", "shows:bulk-add"), h.BindBulkAddShows(), h.BulkAddShows)
+	r.GET("/metadata/bulk-add-jobs/:internalJobBulkAddId", h.BindBulkAddJobID(), h.GetBulkAddJob)
+	r.GET("/metadata/episodes/:externalId", h.CacheHeader(), h.BindExternalID(), h.BindEpisodesOpts(), h.ListEpisodes)
+	r.DELETE("/metadata/cache", h.BindPurgeCache(), h.PurgeCache)
 }
@@ -2,30 +2,46 @@ package metadata
 
 import (
 	"context"
-	"strings"
+	"errors"
+	"fmt"
+	"sort"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/db"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	episodemodel "github.com/keithics/devops-dashboard/api/internal/episode"
 	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/cache"
 	showmodel "github.com/keithics/devops-dashboard/api/internal/show"
-	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+	"github.com/keithics/devops-dashboard/api/internal/showjob"
+	"github.com/keithics/devops-dashboard/api/internal/utils/fuzzy"
 )
 
-func NewService(workerService *worker.Service, showService *showmodel.Service) *Service {
+func NewService(pool *pgxpool.Pool, workerService *worker.Service, showService *showmodel.Service, episodeService *episodemodel.Service, jobService *showjob.Service, providerCache *cache.Cache) *Service {
 	return &Service{
-		worker:  workerService,
-		showSvc: showService,
+		pool:       pool,
+		q:          sqlc.New(pool),
+		worker:     workerService,
+		showSvc:    showService,
+		episodeSvc: episodeService,
+		jobSvc:     jobService,
+		cache:      providerCache,
 	}
 }
 
-func NewHandler(svc *Service) *Handler {
-	return &Handler{svc: svc}
+func NewHandler(svc *Service, opts HandlerOptions) *Handler {
+	return &Handler{svc: svc, opts: normalizeHandlerOptions(opts)}
 }
 
-func (s *Service) Search(ctx context.Context, provider worker.ProviderName, query string, opts worker.SearchOpts) ([]worker.SearchHit, error) {
+// Search returns the provider's hits that match query with a score of at least minScore,
+// best match first. Hits with equal scores keep the provider's order.
+func (s *Service) Search(ctx context.Context, provider worker.ProviderName, query string, opts worker.SearchOpts, minScore float64) ([]ScoredSearchHit, error) {
 	items, err := s.worker.Search(ctx, provider, query, opts)
 	if err != nil {
 		return nil, err
 	}
-	return filterTitleContains(query, items), nil
+	return rankSearchHits(query, items, minScore), nil
 }
 
 func (s *Service) Discover(ctx context.Context, provider worker.ProviderName, opts worker.DiscoverOpts) (worker.DiscoverResult, error) {
@@ -40,66 +56,182 @@ func (s *Service) ListEpisodes(ctx context.Context, provider worker.ProviderName
 	return s.worker.ListEpisodes(ctx, provider, externalID, opts)
 }
 
-func (s *Service) AddShowByExternalID(ctx context.Context, provider worker.ProviderName, externalID string) (AddShowResponse, error) {
+// DescribeProviders reports what every registered provider supports and how its
+// upstream is doing.
+func (s *Service) DescribeProviders() []worker.ProviderDescription {
+	return s.worker.Describe()
+}
+
+// PurgeCache drops the provider's cached responses, or only those for externalID when it
+// is set. It purges nothing when caching is disabled.
+func (s *Service) PurgeCache(ctx context.Context, provider worker.ProviderName, externalID string) (int64, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	return s.cache.Purge(ctx, provider, externalID)
+}
+
+// AddShowByExternalID creates the show and all of its provider episodes in one transaction.
+// When the show already exists, onConflict decides between failing with a duplicate error
+// and importing the episodes onto the existing show; created is false in the latter case.
+func (s *Service) AddShowByExternalID(ctx context.Context, provider worker.ProviderName, externalID string, onConflict string) (AddShowResponse, bool, error) {
+	return s.addShow(ctx, provider, externalID, onConflict, true)
+}
+
+// addShow is AddShowByExternalID with the episode import optional.
+func (s *Service) addShow(ctx context.Context, provider worker.ProviderName, externalID string, onConflict string, importEpisodes bool) (AddShowResponse, bool, error) {
 	item, err := s.worker.GetShow(ctx, provider, externalID)
 	if err != nil {
-		return AddShowResponse{}, err
+		return AddShowResponse{}, false, err
 	}
 
-	created, err := s.showSvc.CreateShow(ctx, showmodel.Show(item))
+	var episodes []worker.Episode
+	if importEpisodes {
+		episodes, err = s.listAllEpisodes(ctx, provider, item.ExternalID)
+		if err != nil {
+			return AddShowResponse{}, false, err
+		}
+	}
+
+	// Pre hooks call their receivers over HTTP, so they run before the transaction opens.
+	prepared, err := s.showSvc.PrepareCreateShow(ctx, showmodel.Show(item), onConflict)
 	if err != nil {
-		return AddShowResponse{}, err
-	}
-
-	return AddShowResponse{
-		InternalShowID: created.InternalShowID,
-		ShowResponse: ShowResponse{
-			ExternalID:     item.ExternalID,
-			TitlePreferred: item.TitlePreferred,
-			TitleOriginal:  item.TitleOriginal,
-			AltTitles:      item.AltTitles,
-			Type:           item.Type,
-			Status:         item.Status,
-			Synopsis:       item.Synopsis,
-			StartDate:      item.StartDate,
-			EndDate:        item.EndDate,
-			PosterUrl:      item.PosterUrl,
-			BannerUrl:      item.BannerUrl,
-			SeasonCount:    item.SeasonCount,
-			EpisodeCount:   item.EpisodeCount,
-		},
-		CreatedAt: created.CreatedAt,
-		UpdatedAt: created.UpdatedAt,
-	}, nil
+		return AddShowResponse{}, false, err
+	}
+
+	var stored sqlc.Show
+	var created bool
+	var imported []sqlc.Episode
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		stored, created, err = s.showSvc.WithTx(tx).InsertShow(ctx, prepared)
+		if err != nil {
+			return err
+		}
+		imported, err = s.episodeSvc.WithTx(tx).ImportEpisodes(ctx, stored.InternalShowID, toImportEpisodes(episodes))
+		return err
+	})
+	if err != nil {
+		return AddShowResponse{}, false, err
+	}
+
+	response, err := toAddShowResponse(stored, len(imported))
+	if err != nil {
+		return AddShowResponse{}, false, err
+	}
+	return response, created, nil
 }
 
-func filterTitleContains(query string, items []worker.SearchHit) []worker.SearchHit {
-	normalizedQuery := normalizeutil.LowerString(query)
-	if normalizedQuery == "" {
-		return []worker.SearchHit{}
+// EnqueueShowByExternalID creates the show from provider data and queues a job that imports
+// its episodes in the background. An existing show is handled as chosen by onConflict.
+// created is false when an active job already existed.
+func (s *Service) EnqueueShowByExternalID(ctx context.Context, provider worker.ProviderName, externalID string, onConflict string) (EnqueueShowResponse, bool, error) {
+	item, err := s.worker.GetShow(ctx, provider, externalID)
+	if err != nil {
+		return EnqueueShowResponse{}, false, err
+	}
+
+	// The pre hooks run before EnqueueShow opens its transaction, so they are called even
+	// when an active job turns out to exist already.
+	prepared, err := s.showSvc.PrepareCreateShow(ctx, showmodel.Show(item), onConflict)
+	if err != nil {
+		return EnqueueShowResponse{}, false, err
 	}
 
-	filtered := make([]worker.SearchHit, 0, len(items))
-	for _, item := range items {
-		if !hasTitleContainsMatch(item, normalizedQuery) {
-			continue
+	job, created, err := s.jobSvc.EnqueueShow(ctx, item.ExternalID, func(ctx context.Context, tx pgx.Tx) (string, error) {
+		stored, _, err := s.showSvc.WithTx(tx).InsertShow(ctx, prepared)
+		if err != nil {
+			return "", err
 		}
-		filtered = append(filtered, item)
+		return stored.InternalShowID, nil
+	})
+	if err != nil {
+		return EnqueueShowResponse{}, false, err
 	}
-	return filtered
+
+	return EnqueueShowResponse{
+		InternalShowID:    job.ShowID,
+		InternalJobShowID: job.InternalJobShowID,
+		Status:            job.Status,
+		RetryCount:        job.RetryCount,
+	}, created, nil
 }
 
-func hasTitleContainsMatch(item worker.SearchHit, normalizedQuery string) bool {
-	if strings.Contains(normalizeutil.LowerString(item.TitlePreferred), normalizedQuery) {
-		return true
+// ImportShow fetches every provider episode for an existing show and stores the ones not yet in the library.
+func (s *Service) ImportShow(ctx context.Context, internalShowID string) error {
+	item, err := s.showSvc.GetShowByID(ctx, internalShowID)
+	if err != nil {
+		return err
 	}
-	if item.TitleOriginal != nil && strings.Contains(normalizeutil.LowerString(*item.TitleOriginal), normalizedQuery) {
-		return true
+
+	provider, externalID, err := showProviderRef(item)
+	if err != nil {
+		return err
 	}
-	for _, altTitle := range item.AltTitles {
-		if strings.Contains(normalizeutil.LowerString(altTitle), normalizedQuery) {
-			return true
+
+	episodes, err := s.listAllEpisodes(ctx, provider, externalID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.episodeSvc.ImportEpisodes(ctx, internalShowID, toImportEpisodes(episodes))
+	return err
+}
+
+// listAllEpisodes takes the whole list in one call from providers that offer it, and
+// otherwise reads the provider's episode pages until one comes back empty. Pages can be
+// short before the end, e.g. when the provider drops specials, so a short page does not
+// end the list. A show with more than importEpisodesMaxPages pages fails with
+// ErrTooManyEpisodes rather than being imported in part.
+func (s *Service) listAllEpisodes(ctx context.Context, provider worker.ProviderName, externalID string) ([]worker.Episode, error) {
+	maxEpisodes := importEpisodesMaxPages * importEpisodesPageSize
+	items, err := s.worker.ListAllEpisodes(ctx, provider, externalID)
+	switch {
+	case err == nil:
+		if len(items) > maxEpisodes {
+			return nil, fmt.Errorf("%w: %s has more than %d episodes", ErrTooManyEpisodes, externalID, maxEpisodes)
 		}
+		return items, nil
+	case !errors.Is(err, worker.ErrUnsupported):
+		return nil, err
+	}
+
+	out := make([]worker.Episode, 0)
+	for page := 1; page <= importEpisodesMaxPages; page++ {
+		items, err := s.worker.ListEpisodes(ctx, provider, externalID, worker.ListEpisodesOpts{
+			Page:  page,
+			Limit: importEpisodesPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return out, nil
+		}
+		out = append(out, items...)
+	}
+	return nil, fmt.Errorf("%w: %s has more than %d episodes", ErrTooManyEpisodes, externalID, maxEpisodes)
+}
+
+func rankSearchHits(query string, items []worker.SearchHit, minScore float64) []ScoredSearchHit {
+	ranked := make([]ScoredSearchHit, 0, len(items))
+	for _, item := range items {
+		score := fuzzy.BestMatchScore(query, searchHitTitles(item))
+		if score < minScore {
+			continue
+		}
+		ranked = append(ranked, ScoredSearchHit{Hit: item, Score: score})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+func searchHitTitles(item worker.SearchHit) []string {
+	titles := make([]string, 0, len(item.AltTitles)+2)
+	titles = append(titles, item.TitlePreferred)
+	if item.TitleOriginal != nil {
+		titles = append(titles, *item.TitleOriginal)
 	}
-	return false
+	return append(titles, item.AltTitles...)
 }
@@ -1,10 +1,18 @@
 package metadata
 
 import (
+	"errors"
 	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/episode"
+	"github.com/keithics/devops-dashboard/api/internal/httperr"
 	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/cache"
 	"github.com/keithics/devops-dashboard/api/internal/show"
+	"github.com/keithics/devops-dashboard/api/internal/showjob"
 )
 
 const (
@@ -14,15 +22,247 @@ const (
 	ctxSearchOptsKey   = "metadata.search.opts"
 	ctxDiscoverOptsKey = "metadata.discover.opts"
 	ctxEpisodesOptsKey = "metadata.episodes.opts"
+	ctxOnConflictKey   = "metadata.on_conflict"
+	ctxMinScoreKey     = "metadata.search.min_score"
+	ctxSearchTargetKey = "metadata.search.target"
+	ctxCacheStatusKey  = "metadata.cache.status"
+	ctxRelatedDepthKey = "metadata.related.depth"
+	ctxBulkAddShowsKey = "metadata.bulk_add.items"
+	ctxBulkAddOptsKey  = "metadata.bulk_add.opts"
+	ctxBulkAddAsyncKey = "metadata.bulk_add.async"
+	ctxBulkAddJobIDKey = "metadata.bulk_add.job_id"
+)
+
+// Operation names reported by GET /metadata/providers.
+const (
+	operationSearch   = "search"
+	operationDiscover = "discover"
+	operationShow     = "show"
+	operationEpisodes = "episodes"
+	operationRelated  = "related"
+)
+
+// Bulk add item outcomes.
+const (
+	BulkAddStatusCreated  = "created"
+	BulkAddStatusExisting = "existing"
+	BulkAddStatusSkipped  = "skipped"
+	BulkAddStatusError    = "error"
+)
+
+const (
+	// bulkOnConflictSkip, the bulk add default, leaves shows already in the library
+	// untouched and reports them as skipped.
+	bulkOnConflictSkip = "skip"
+
+	maxBulkAddShows      = 50
+	maxBulkAddShowsAsync = 500
+	// bulkAddConcurrency is how many items of a batch are added at once. Provider rate
+	// limits still apply through the shared transport.
+	bulkAddConcurrency = 4
+
+	defaultBulkAddPollInterval = 5 * time.Second
+	bulkAddJobTimeout          = time.Hour
+	// bulkAddStaleAfter is how long a processing job may stay locked before another
+	// worker takes it over; it must exceed bulkAddJobTimeout.
+	bulkAddStaleAfter = 2 * time.Hour
+)
+
+const (
+	defaultRelatedDepth = 2
+	maxRelatedDepth     = 3
+	// maxRelatedShows bounds the franchise graph, and with it the provider calls one
+	// request can make.
+	maxRelatedShows = 50
+)
+
+const (
+	// searchTypeAll fans a search out to every registered provider.
+	searchTypeAll = "all"
+
+	// defaultSearchMinScore is the match score a provider search hit needs to be returned
+	// when neither METADATA_SEARCH_MIN_SCORE nor the minScore query param says otherwise.
+	defaultSearchMinScore = 0.5
+	defaultSearchTimeout  = 5 * time.Second
+
+	// mergeTitleThreshold is the title similarity at which hits from different providers
+	// with the same start year are treated as one show.
+	mergeTitleThreshold = 0.9
+)
+
+const (
+	importEpisodesPageSize = 50
+	// importEpisodesMaxPages caps an import at 20000 episodes, well above the longest
+	// running shows, so a provider that never returns an empty page cannot loop forever.
+	importEpisodesMaxPages = 400
+)
+
+// ErrTooManyEpisodes reports a provider show with more episodes than one import takes.
+var ErrTooManyEpisodes = errors.New("too many provider episodes to import")
+
+const (
+	defaultRefreshInterval = 6 * time.Hour
+	// refreshLockKey is the session advisory lock held while a refresh pass runs,
+	// so only one API instance refreshes at a time.
+	refreshLockKey int64 = 0x5e7a_0004
 )
 
 type Handler struct {
-	svc *Service
+	svc  *Service
+	opts HandlerOptions
+}
+
+type HandlerOptions struct {
+	SearchMinScore float64
+	SearchTimeout  time.Duration
+}
+
+// searchTarget is the parsed search type: one provider, or a federated fan-out.
+type searchTarget struct {
+	Providers []worker.ProviderName
+	Federated bool
 }
 
 type Service struct {
-	worker  *worker.Service
-	showSvc *show.Service
+	pool       *pgxpool.Pool
+	q          *sqlc.Queries
+	worker     *worker.Service
+	showSvc    *show.Service
+	episodeSvc *episode.Service
+	jobSvc     *showjob.Service
+	// cache is nil when provider response caching is disabled.
+	cache *cache.Cache
+}
+
+// cacheHeaderWriter sets the X-Cache header from the request's cache status just before
+// the response headers are sent.
+type cacheHeaderWriter struct {
+	gin.ResponseWriter
+	status *cache.Status
+}
+
+// BulkAddWorker processes the job_bulk_adds queue.
+type BulkAddWorker struct {
+	svc          *Service
+	id           string
+	pollInterval time.Duration
+}
+
+type Refresher struct {
+	svc      *Service
+	interval time.Duration
+}
+
+type RefreshResult struct {
+	InternalShowID  string
+	Updated         bool
+	Finished        bool
+	EpisodesAdded   int
+	EpisodesUpdated int
+}
+
+type RefreshSummary struct {
+	Skipped         bool
+	Shows           int
+	Updated         int
+	Finished        int
+	Failed          int
+	EpisodesAdded   int
+	EpisodesUpdated int
+}
+
+// RelatedGraph is a show's franchise: Root is its external id, Nodes are in the order
+// they were reached and Edges point from a show to the show it is related to.
+type RelatedGraph struct {
+	Root      string
+	Nodes     []RelatedShow
+	Edges     []RelatedEdge
+	Truncated bool
+}
+
+// RelatedShow is one show in the graph, Depth hops from the root. InternalShowID is set
+// when the show is already in the library.
+type RelatedShow struct {
+	Show           worker.Show
+	Format         string
+	Depth          int
+	InternalShowID *string
+}
+
+// RelatedEdge says To is From's Type, e.g. its sequel.
+type RelatedEdge struct {
+	From string
+	To   string
+	Type string
+}
+
+type relatedEdgeKey struct {
+	from string
+	to   string
+}
+
+// BulkAddOptions apply to every item of a bulk add. OnConflict is bulkOnConflictSkip or
+// one of the show.OnConflict* policies.
+type BulkAddOptions struct {
+	OnConflict     string
+	ImportEpisodes bool
+}
+
+// BulkAddShowResult is the outcome of one bulk add item. Show is set for created and
+// existing shows, and InternalShowID for skipped ones.
+type BulkAddShowResult struct {
+	Index          int
+	Provider       worker.ProviderName
+	ExternalID     string
+	Status         string
+	Show           *AddShowResponse
+	InternalShowID string
+	Err            error
+}
+
+// BulkAddShowsResult lists the item outcomes in request order with per-status totals.
+type BulkAddShowsResult struct {
+	Items    []BulkAddShowResult
+	Created  int
+	Existing int
+	Skipped  int
+	Failed   int
+}
+
+// bulkAddShowItem is one element of the bulk add body. Type defaults to the provider
+// named by the external id's prefix.
+type bulkAddShowItem struct {
+	ExternalID string `json:"externalId"`
+	Type       string `json:"type"`
+}
+
+// ScoredSearchHit is a provider search hit with its fuzzy match score against the query.
+type ScoredSearchHit struct {
+	Hit   worker.SearchHit
+	Score float64
+}
+
+// FederatedSearchResult holds merged hits from several providers plus the error of
+// every provider that failed.
+type FederatedSearchResult struct {
+	Items          []FederatedSearchHit
+	ProviderErrors map[worker.ProviderName]error
+}
+
+// FederatedSearchHit is one show found by one or more providers. Show comes from the
+// best scoring provider with blanks filled in from the others.
+type FederatedSearchHit struct {
+	Show           worker.Show
+	Score          float64
+	Providers      []worker.ProviderName
+	ExternalIDs    []string
+	InternalShowID *string
+}
+
+// searchCandidate is a ranked hit waiting to be merged, tagged with its provider.
+type searchCandidate struct {
+	provider worker.ProviderName
+	hit      ScoredSearchHit
 }
 
 type SearchHitResponse struct {
@@ -39,6 +279,20 @@ type SearchHitResponse struct {
 	BannerUrl      *string  `json:"bannerUrl,omitempty"`
 	SeasonCount    *int64   `json:"seasonCount,omitempty"`
 	EpisodeCount   *int64   `json:"episodeCount,omitempty"`
+	LinkedIDs      []string `json:"linkedIds,omitempty"`
+	MatchScore     float64  `json:"matchScore"`
+}
+
+type FederatedSearchHitResponse struct {
+	SearchHitResponse
+	Providers      []string `json:"providers"`
+	ExternalIDs    []string `json:"externalIds"`
+	InternalShowID *string  `json:"internalShowId,omitempty"`
+}
+
+type FederatedSearchResponse struct {
+	Items          []FederatedSearchHitResponse `json:"items"`
+	ProviderErrors map[string]string            `json:"providerErrors"`
 }
 
 type ShowResponse struct {
@@ -55,6 +309,7 @@ type ShowResponse struct {
 	BannerUrl      *string  `json:"bannerUrl,omitempty"`
 	SeasonCount    *int64   `json:"seasonCount,omitempty"`
 	EpisodeCount   *int64   `json:"episodeCount,omitempty"`
+	LinkedIDs      []string `json:"linkedIds,omitempty"`
 }
 
 type DiscoverResponse struct {
@@ -71,6 +326,8 @@ type EpisodeResponse struct {
 	SeasonNumber   int64   `json:"seasonNumber"`
 	EpisodeNumber  int64   `json:"episodeNumber"`
 	Title          string  `json:"title"`
+	Type           string  `json:"type,omitempty"`
+	AbsoluteNumber *int64  `json:"absoluteNumber,omitempty"`
 	AirDate        *string `json:"airDate,omitempty"`
 	RuntimeMinutes *int64  `json:"runtimeMinutes,omitempty"`
 }
@@ -78,6 +335,98 @@ type EpisodeResponse struct {
 type AddShowResponse struct {
 	InternalShowID string `json:"internalShowId"`
 	ShowResponse
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	EpisodesImported int64     `json:"episodesImported"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type RelatedShowResponse struct {
+	ShowResponse
+	Format         string  `json:"format,omitempty"`
+	Depth          int     `json:"depth"`
+	InLibrary      bool    `json:"inLibrary"`
+	InternalShowID *string `json:"internalShowId,omitempty"`
+}
+
+type RelatedEdgeResponse struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	RelationType string `json:"relationType"`
+}
+
+type RelatedShowsResponse struct {
+	Root      string                `json:"root"`
+	Nodes     []RelatedShowResponse `json:"nodes"`
+	Edges     []RelatedEdgeResponse `json:"edges"`
+	Truncated bool                  `json:"truncated"`
+}
+
+type BulkAddShowResultResponse struct {
+	Index          int               `json:"index"`
+	ExternalID     string            `json:"externalId"`
+	Type           string            `json:"type,omitempty"`
+	Status         string            `json:"status"`
+	Show           *AddShowResponse  `json:"show,omitempty"`
+	InternalShowID string            `json:"internalShowId,omitempty"`
+	Error          *httperr.APIError `json:"error,omitempty"`
+}
+
+type BulkAddShowsResponse struct {
+	Items    []BulkAddShowResultResponse `json:"items"`
+	Created  int                         `json:"created"`
+	Existing int                         `json:"existing"`
+	Skipped  int                         `json:"skipped"`
+	Failed   int                         `json:"failed"`
+}
+
+type BulkAddJobResponse struct {
+	InternalJobBulkAddID string                `json:"internalJobBulkAddId"`
+	Status               string                `json:"status"`
+	Total                int                   `json:"total"`
+	OnConflict           string                `json:"onConflict"`
+	ImportEpisodes       bool                  `json:"importEpisodes"`
+	Result               *BulkAddShowsResponse `json:"result,omitempty"`
+	ErrorMessage         *string               `json:"errorMessage,omitempty"`
+	CreatedAt            time.Time             `json:"createdAt"`
+	UpdatedAt            time.Time             `json:"updatedAt"`
+}
+
+type ProviderResponse struct {
+	Type        string                   `json:"type"`
+	IDPrefix    string                   `json:"idPrefix"`
+	Configured  bool                     `json:"configured"`
+	ConfigError string                   `json:"configError,omitempty"`
+	Operations  []string                 `json:"operations"`
+	Features    ProviderFeaturesResponse `json:"features"`
+	Health      ProviderHealthResponse   `json:"health"`
+}
+
+type ProviderFeaturesResponse struct {
+	SeasonFilter  bool `json:"seasonFilter"`
+	EpisodeTitles bool `json:"episodeTitles"`
+	Related       bool `json:"related"`
+}
+
+type ProviderHealthResponse struct {
+	LastRequestAt *time.Time `json:"lastRequestAt,omitempty"`
+	LastLatencyMs *int64     `json:"lastLatencyMs,omitempty"`
+	LastErrorAt   *time.Time `json:"lastErrorAt,omitempty"`
+	LastError     *string    `json:"lastError,omitempty"`
+	CircuitOpen   bool       `json:"circuitOpen"`
+}
+
+type upstreamErrorDetails struct {
+	Provider          string `json:"provider"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds,omitempty"`
+}
+
+type PurgeCacheResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+type EnqueueShowResponse struct {
+	InternalShowID    string `json:"internalShowId"`
+	InternalJobShowID string `json:"internalJobShowId"`
+	Status            string `json:"status"`
+	RetryCount        int32  `json:"retryCount"`
 }
@@ -1,13 +1,26 @@
 package metadata
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/episode"
+	"github.com/keithics/devops-dashboard/api/internal/hooks"
 	"github.com/keithics/devops-dashboard/api/internal/httperr"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
 	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/metadata/provider/transport"
+	"github.com/keithics/devops-dashboard/api/internal/show"
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
 )
 
 func parseProvider(raw string) (worker.ProviderName, error) {
@@ -28,6 +41,50 @@ func parseProvider(raw string) (worker.ProviderName, error) {
 	}
 }
 
+// parseSearchTarget accepts a single provider, "all" for every registered provider, or a
+// comma separated list of providers. Only a single provider keeps the plain list response.
+func parseSearchTarget(raw string, registered []worker.ProviderName) (searchTarget, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == searchTypeAll {
+		return searchTarget{Providers: registered, Federated: true}, nil
+	}
+	if !strings.Contains(value, ",") {
+		provider, err := parseProvider(value)
+		if err != nil {
+			return searchTarget{}, errors.New("type must be all, one of anidb|anilist|tvdb or a comma separated list of them")
+		}
+		return searchTarget{Providers: []worker.ProviderName{provider}}, nil
+	}
+
+	target := searchTarget{Federated: true}
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		provider, err := parseProvider(part)
+		if err != nil {
+			return searchTarget{}, errors.New("type must be all, one of anidb|anilist|tvdb or a comma separated list of them")
+		}
+		if !slices.Contains(target.Providers, provider) {
+			target.Providers = append(target.Providers, provider)
+		}
+	}
+	if len(target.Providers) == 0 {
+		return searchTarget{}, errors.New("type must name at least one provider")
+	}
+	return target, nil
+}
+
+func normalizeHandlerOptions(opts HandlerOptions) HandlerOptions {
+	if opts.SearchMinScore <= 0 || opts.SearchMinScore > 1 {
+		opts.SearchMinScore = defaultSearchMinScore
+	}
+	if opts.SearchTimeout <= 0 {
+		opts.SearchTimeout = defaultSearchTimeout
+	}
+	return opts
+}
+
 func validateQuery(query string) error {
 	if query == "" {
 		return errors.New("query is required")
@@ -42,29 +99,285 @@ func validateExternalID(externalID string) error {
 	return nil
 }
 
+func parseRelatedDepth(raw string) (int, error) {
+	depth := httpx.ParsePositiveInt(raw, defaultRelatedDepth)
+	if depth > maxRelatedDepth {
+		return 0, fmt.Errorf("depth must be between 1 and %d", maxRelatedDepth)
+	}
+	return depth, nil
+}
+
+func normalizeBulkAddShowItems(items []bulkAddShowItem) {
+	for i := range items {
+		items[i].ExternalID = normalizeutil.String(items[i].ExternalID)
+		items[i].Type = strings.ToLower(normalizeutil.String(items[i].Type))
+	}
+}
+
+// validateBulkAddShowItems bounds the batch and rejects an id listed twice, since the
+// items are added concurrently.
+func validateBulkAddShowItems(items []bulkAddShowItem, async bool) error {
+	limit := maxBulkAddShows
+	if async {
+		limit = maxBulkAddShowsAsync
+	}
+	if len(items) == 0 || len(items) > limit {
+		return fmt.Errorf("shows must contain between 1 and %d items", limit)
+	}
+
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		key := strings.ToLower(item.ExternalID)
+		if !strings.Contains(key, ":") {
+			key = item.Type + ":" + key
+		}
+		if _, ok := seen[key]; ok && item.ExternalID != "" {
+			return fmt.Errorf("externalId %s is listed more than once", item.ExternalID)
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+// parseBulkOnConflict defaults to skipping shows already in the library; return and
+// update work as for a single add.
+func parseBulkOnConflict(raw string) (string, error) {
+	value := normalizeutil.LowerString(raw)
+	if value == "" || value == bulkOnConflictSkip {
+		return bulkOnConflictSkip, nil
+	}
+	if value == show.OnConflictReturn || value == show.OnConflictUpdate {
+		return value, nil
+	}
+	return "", errors.New("onConflict must be one of skip|return|update")
+}
+
+func parseBoolQuery(raw string, name string, fallback bool) (bool, error) {
+	raw = normalizeutil.String(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return value, nil
+}
+
+func validateBulkAddJobID(id string) error {
+	return httpx.ValidateVar(id, "required,uuid4", "internalJobBulkAddId is invalid")
+}
+
+// resolveBulkAddProvider takes the item's type when given and otherwise the provider
+// named by its external id's prefix, so picks from a related graph can be sent as is.
+func resolveBulkAddProvider(item bulkAddShowItem) (worker.ProviderName, error) {
+	if err := validateExternalID(item.ExternalID); err != nil {
+		return "", worker.Errorf(worker.ErrInvalidExternalID, "%s", err)
+	}
+	if item.Type != "" {
+		provider, err := parseProvider(item.Type)
+		if err != nil {
+			return "", worker.Errorf(worker.ErrUnsupported, "%s", err)
+		}
+		return provider, nil
+	}
+	return worker.ProviderFromExternalID(item.ExternalID)
+}
+
+// validateCacheProviderType insists on an explicit type, so a purge never falls back to
+// the anidb default.
+func validateCacheProviderType(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return errors.New("type is required")
+	}
+	return nil
+}
+
+// abortProviderErr answers with providerHTTPError, passing the provider's retry hint on
+// in Retry-After when known.
 func abortProviderErr(c *gin.Context, internalMessage string, err error) {
-	message := strings.ToLower(err.Error())
-	if strings.Contains(message, "not implemented") || strings.Contains(message, "not supported") {
-		httperr.Abort(c, httperr.BadRequest(err.Error()))
-		return
+	httpErr := providerHTTPError(internalMessage, err)
+	if details, ok := httpErr.Details.(upstreamErrorDetails); ok && details.RetryAfterSeconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(details.RetryAfterSeconds))
 	}
-	httperr.Abort(c, httperr.Internal(internalMessage).WithCause(err))
+	httperr.Abort(c, httpErr)
 }
 
-func getSearchInput(c *gin.Context) (worker.ProviderName, string, worker.SearchOpts, bool) {
-	provider, ok := httpx.AbortIfMissingContext[worker.ProviderName](c, ctxProviderTypeKey)
+// providerHTTPError maps the provider error kinds, and the duplicate and pre-hook errors
+// of adding a show, to their HTTP status and stable code. Throttling and outages carry
+// the provider and its retry hint as details; an open circuit answers 503 since the
+// request never reached the provider.
+func providerHTTPError(internalMessage string, err error) *httperr.HTTPError {
+	if httpErr, ok := show.DuplicateHTTPError(err); ok {
+		return httpErr
+	}
+	if httpErr, ok := hooks.HTTPError(err); ok {
+		return httpErr
+	}
+
+	var upstreamErr *transport.UpstreamError
+	errors.As(err, &upstreamErr)
+
+	var httpErr *httperr.HTTPError
+	switch {
+	case errors.Is(err, ErrTooManyEpisodes):
+		return httperr.ImportTooLarge(err.Error()).WithCause(err)
+	case errors.Is(err, worker.ErrNotFound):
+		httpErr = httperr.ProviderNotFound(err.Error())
+	case errors.Is(err, worker.ErrInvalidExternalID):
+		httpErr = httperr.InvalidExternalID(err.Error())
+	case errors.Is(err, worker.ErrUnsupported):
+		httpErr = httperr.ProviderUnsupported(err.Error())
+	case errors.Is(err, worker.ErrRateLimited):
+		httpErr = httperr.ProviderRateLimited(providerErrMessage(err, upstreamErr))
+	case errors.Is(err, worker.ErrUpstreamUnavailable):
+		httpErr = httperr.ProviderUnavailable(providerErrMessage(err, upstreamErr))
+		if upstreamErr != nil && upstreamErr.CircuitOpen {
+			httpErr.Status = http.StatusServiceUnavailable
+		}
+	default:
+		return httperr.Internal(internalMessage).WithCause(err)
+	}
+
+	if upstreamErr != nil {
+		details := upstreamErrorDetails{Provider: upstreamErr.Provider}
+		if upstreamErr.RetryAfter > 0 {
+			details.RetryAfterSeconds = int(math.Ceil(upstreamErr.RetryAfter.Seconds()))
+		}
+		httpErr.WithDetails(details)
+	}
+	return httpErr.WithCause(err)
+}
+
+// providerErrMessage prefers the transport's own message, which leaves out the request
+// URL that the HTTP client wraps around it.
+func providerErrMessage(err error, upstreamErr *transport.UpstreamError) string {
+	if upstreamErr != nil {
+		return upstreamErr.Error()
+	}
+	return err.Error()
+}
+
+func toProviderResponse(item worker.ProviderDescription) ProviderResponse {
+	capabilities := item.Capabilities
+	response := ProviderResponse{
+		Type:        string(item.Provider),
+		IDPrefix:    capabilities.IDPrefix,
+		Configured:  capabilities.Configured,
+		ConfigError: capabilities.ConfigError,
+		Operations:  []string{},
+		Features: ProviderFeaturesResponse{
+			SeasonFilter:  capabilities.SeasonFilter,
+			EpisodeTitles: capabilities.EpisodeTitles,
+			Related:       capabilities.Related,
+		},
+		Health: ProviderHealthResponse{CircuitOpen: item.Health.CircuitOpen},
+	}
+
+	for _, operation := range []struct {
+		name      string
+		supported bool
+	}{
+		{operationSearch, capabilities.Search},
+		{operationDiscover, capabilities.Discover},
+		{operationShow, capabilities.GetShow},
+		{operationEpisodes, capabilities.ListEpisodes},
+		{operationRelated, capabilities.Related},
+	} {
+		if operation.supported {
+			response.Operations = append(response.Operations, operation.name)
+		}
+	}
+
+	if !item.Health.LastRequestAt.IsZero() {
+		lastRequestAt := item.Health.LastRequestAt
+		latencyMs := item.Health.LastLatency.Milliseconds()
+		response.Health.LastRequestAt = &lastRequestAt
+		response.Health.LastLatencyMs = &latencyMs
+	}
+	if !item.Health.LastErrorAt.IsZero() {
+		lastErrorAt := item.Health.LastErrorAt
+		lastError := item.Health.LastError
+		response.Health.LastErrorAt = &lastErrorAt
+		response.Health.LastError = &lastError
+	}
+	return response
+}
+
+func getSearchInput(c *gin.Context) (searchTarget, string, worker.SearchOpts, float64, bool) {
+	target, ok := httpx.AbortIfMissingContext[searchTarget](c, ctxSearchTargetKey)
 	if !ok {
-		return "", "", worker.SearchOpts{}, false
+		return searchTarget{}, "", worker.SearchOpts{}, 0, false
 	}
 	query, ok := httpx.AbortIfMissingContext[string](c, ctxQueryKey)
 	if !ok {
-		return "", "", worker.SearchOpts{}, false
+		return searchTarget{}, "", worker.SearchOpts{}, 0, false
 	}
 	opts, ok := httpx.AbortIfMissingContext[worker.SearchOpts](c, ctxSearchOptsKey)
 	if !ok {
-		return "", "", worker.SearchOpts{}, false
+		return searchTarget{}, "", worker.SearchOpts{}, 0, false
 	}
-	return provider, query, opts, true
+	minScore, ok := httpx.AbortIfMissingContext[float64](c, ctxMinScoreKey)
+	if !ok {
+		return searchTarget{}, "", worker.SearchOpts{}, 0, false
+	}
+	return target, query, opts, minScore, true
+}
+
+func parseMinScore(raw string, fallback float64) (float64, error) {
+	raw = normalizeutil.String(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 || value > 1 {
+		return 0, errors.New("minScore must be a number between 0 and 1")
+	}
+	return value, nil
+}
+
+func toSearchHitResponse(item ScoredSearchHit) SearchHitResponse {
+	return SearchHitResponse{
+		ExternalID:     item.Hit.ExternalID,
+		TitlePreferred: item.Hit.TitlePreferred,
+		TitleOriginal:  item.Hit.TitleOriginal,
+		AltTitles:      item.Hit.AltTitles,
+		Type:           item.Hit.Type,
+		Status:         item.Hit.Status,
+		Synopsis:       item.Hit.Synopsis,
+		StartDate:      item.Hit.StartDate,
+		EndDate:        item.Hit.EndDate,
+		PosterUrl:      item.Hit.PosterUrl,
+		BannerUrl:      item.Hit.BannerUrl,
+		SeasonCount:    item.Hit.SeasonCount,
+		EpisodeCount:   item.Hit.EpisodeCount,
+		LinkedIDs:      item.Hit.LinkedIDs,
+		MatchScore:     item.Score,
+	}
+}
+
+func toFederatedSearchResponse(result FederatedSearchResult) FederatedSearchResponse {
+	items := make([]FederatedSearchHitResponse, 0, len(result.Items))
+	for _, item := range result.Items {
+		providers := make([]string, 0, len(item.Providers))
+		for _, provider := range item.Providers {
+			providers = append(providers, string(provider))
+		}
+		hit := toSearchHitResponse(ScoredSearchHit{Hit: item.Show, Score: item.Score})
+		hit.LinkedIDs = nil
+		items = append(items, FederatedSearchHitResponse{
+			SearchHitResponse: hit,
+			Providers:         providers,
+			ExternalIDs:       item.ExternalIDs,
+			InternalShowID:    item.InternalShowID,
+		})
+	}
+
+	providerErrors := make(map[string]string, len(result.ProviderErrors))
+	for provider, err := range result.ProviderErrors {
+		providerErrors[string(provider)] = err.Error()
+	}
+	return FederatedSearchResponse{Items: items, ProviderErrors: providerErrors}
 }
 
 func getDiscoverInput(c *gin.Context) (worker.ProviderName, worker.DiscoverOpts, bool) {
@@ -79,6 +392,34 @@ func getDiscoverInput(c *gin.Context) (worker.ProviderName, worker.DiscoverOpts,
 	return provider, opts, true
 }
 
+func getRelatedInput(c *gin.Context) (worker.ProviderName, string, int, bool) {
+	provider, externalID, ok := getProviderAndExternalID(c)
+	if !ok {
+		return "", "", 0, false
+	}
+	depth, ok := httpx.AbortIfMissingContext[int](c, ctxRelatedDepthKey)
+	if !ok {
+		return "", "", 0, false
+	}
+	return provider, externalID, depth, true
+}
+
+func getBulkAddInput(c *gin.Context) ([]bulkAddShowItem, BulkAddOptions, bool, bool) {
+	items, ok := httpx.AbortIfMissingContext[[]bulkAddShowItem](c, ctxBulkAddShowsKey)
+	if !ok {
+		return nil, BulkAddOptions{}, false, false
+	}
+	opts, ok := httpx.AbortIfMissingContext[BulkAddOptions](c, ctxBulkAddOptsKey)
+	if !ok {
+		return nil, BulkAddOptions{}, false, false
+	}
+	async, ok := httpx.AbortIfMissingContext[bool](c, ctxBulkAddAsyncKey)
+	if !ok {
+		return nil, BulkAddOptions{}, false, false
+	}
+	return items, opts, async, true
+}
+
 func getProviderAndExternalID(c *gin.Context) (worker.ProviderName, string, bool) {
 	provider, ok := httpx.AbortIfMissingContext[worker.ProviderName](c, ctxProviderTypeKey)
 	if !ok {
@@ -91,6 +432,18 @@ func getProviderAndExternalID(c *gin.Context) (worker.ProviderName, string, bool
 	return provider, externalID, true
 }
 
+func getAddShowInput(c *gin.Context) (worker.ProviderName, string, string, bool) {
+	provider, externalID, ok := getProviderAndExternalID(c)
+	if !ok {
+		return "", "", "", false
+	}
+	onConflict, ok := httpx.AbortIfMissingContext[string](c, ctxOnConflictKey)
+	if !ok {
+		return "", "", "", false
+	}
+	return provider, externalID, onConflict, true
+}
+
 func getEpisodesInput(c *gin.Context) (worker.ProviderName, string, worker.ListEpisodesOpts, bool) {
 	provider, externalID, ok := getProviderAndExternalID(c)
 	if !ok {
@@ -102,3 +455,179 @@ func getEpisodesInput(c *gin.Context) (worker.ProviderName, string, worker.ListE
 	}
 	return provider, externalID, opts, true
 }
+
+func toImportEpisodes(items []worker.Episode) []episode.ImportEpisode {
+	out := make([]episode.ImportEpisode, 0, len(items))
+	for _, item := range items {
+		title := normalizeutil.String(item.Title)
+		if title == "" {
+			title = fmt.Sprintf("Episode %d", item.EpisodeNumber)
+		}
+		out = append(out, episode.ImportEpisode{
+			SeasonNumber:   item.SeasonNumber,
+			EpisodeNumber:  item.EpisodeNumber,
+			Title:          title,
+			AirDate:        normalizeutil.StringPtr(item.AirDate),
+			RuntimeMinutes: item.RuntimeMinutes,
+			ExternalIDs:    toEpisodeExternalIDs(item),
+		})
+	}
+	return out
+}
+
+// toEpisodeExternalIDs keeps the numeric provider id; AniList has no episode ids, so its media id is stored.
+func toEpisodeExternalIDs(item worker.Episode) episode.ExternalIDs {
+	_, rest, _ := strings.Cut(item.ExternalID, ":")
+	head, _, _ := strings.Cut(rest, ":")
+	id, err := strconv.ParseInt(strings.TrimSpace(head), 10, 64)
+	if err != nil || id <= 0 {
+		return episode.ExternalIDs{}
+	}
+
+	switch item.Provider {
+	case worker.ProviderAniDB:
+		return episode.ExternalIDs{Anidb: &id}
+	case worker.ProviderAniList:
+		return episode.ExternalIDs{Anilist: &id}
+	case worker.ProviderTVDB:
+		return episode.ExternalIDs{Tvdb: &id}
+	default:
+		return episode.ExternalIDs{}
+	}
+}
+
+func showProviderRef(item sqlc.Show) (worker.ProviderName, string, error) {
+	externalID, err := show.ExternalIDFromShow(item)
+	if err != nil {
+		return "", "", err
+	}
+	if externalID == "" {
+		return "", "", fmt.Errorf("show %s has no external id", item.InternalShowID)
+	}
+
+	provider, err := worker.ProviderFromExternalID(externalID)
+	if err != nil {
+		return "", "", err
+	}
+	return provider, externalID, nil
+}
+
+func toAddShowResponse(item sqlc.Show, episodesImported int) (AddShowResponse, error) {
+	stored, err := show.ToShow(item)
+	if err != nil {
+		return AddShowResponse{}, err
+	}
+	return AddShowResponse{
+		InternalShowID: item.InternalShowID,
+		ShowResponse: ShowResponse{
+			ExternalID:     stored.ExternalID,
+			TitlePreferred: stored.TitlePreferred,
+			TitleOriginal:  stored.TitleOriginal,
+			AltTitles:      stored.AltTitles,
+			Type:           stored.Type,
+			Status:         stored.Status,
+			Synopsis:       stored.Synopsis,
+			StartDate:      stored.StartDate,
+			EndDate:        stored.EndDate,
+			PosterUrl:      stored.PosterUrl,
+			BannerUrl:      stored.BannerUrl,
+			SeasonCount:    stored.SeasonCount,
+			EpisodeCount:   stored.EpisodeCount,
+		},
+		EpisodesImported: int64(episodesImported),
+		CreatedAt:        item.CreatedAt,
+		UpdatedAt:        item.UpdatedAt,
+	}, nil
+}
+
+func toShowResponse(item worker.Show) ShowResponse {
+	return ShowResponse{
+		ExternalID:     item.ExternalID,
+		TitlePreferred: item.TitlePreferred,
+		TitleOriginal:  item.TitleOriginal,
+		AltTitles:      item.AltTitles,
+		Type:           item.Type,
+		Status:         item.Status,
+		Synopsis:       item.Synopsis,
+		StartDate:      item.StartDate,
+		EndDate:        item.EndDate,
+		PosterUrl:      item.PosterUrl,
+		BannerUrl:      item.BannerUrl,
+		SeasonCount:    item.SeasonCount,
+		EpisodeCount:   item.EpisodeCount,
+		LinkedIDs:      item.LinkedIDs,
+	}
+}
+
+func toRelatedShowsResponse(graph RelatedGraph) RelatedShowsResponse {
+	nodes := make([]RelatedShowResponse, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes = append(nodes, RelatedShowResponse{
+			ShowResponse:   toShowResponse(node.Show),
+			Format:         node.Format,
+			Depth:          node.Depth,
+			InLibrary:      node.InternalShowID != nil,
+			InternalShowID: node.InternalShowID,
+		})
+	}
+
+	edges := make([]RelatedEdgeResponse, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		edges = append(edges, RelatedEdgeResponse{From: edge.From, To: edge.To, RelationType: edge.Type})
+	}
+	return RelatedShowsResponse{Root: graph.Root, Nodes: nodes, Edges: edges, Truncated: graph.Truncated}
+}
+
+func toBulkAddShowsResponse(result BulkAddShowsResult) BulkAddShowsResponse {
+	items := make([]BulkAddShowResultResponse, 0, len(result.Items))
+	for _, item := range result.Items {
+		response := BulkAddShowResultResponse{
+			Index:          item.Index,
+			ExternalID:     item.ExternalID,
+			Type:           string(item.Provider),
+			Status:         item.Status,
+			Show:           item.Show,
+			InternalShowID: item.InternalShowID,
+		}
+		if item.Err != nil {
+			httpErr := providerHTTPError("failed to add metadata show", item.Err)
+			if httpErr.Status == http.StatusInternalServerError {
+				log.Printf("metadata bulk add %s: %v", item.ExternalID, item.Err)
+			}
+			response.Error = &httperr.APIError{Code: httpErr.Code, Message: httpErr.Message, Details: httpErr.Details}
+		}
+		items = append(items, response)
+	}
+	return BulkAddShowsResponse{
+		Items:    items,
+		Created:  result.Created,
+		Existing: result.Existing,
+		Skipped:  result.Skipped,
+		Failed:   result.Failed,
+	}
+}
+
+func toBulkAddJobResponse(job sqlc.JobBulkAdd) (BulkAddJobResponse, error) {
+	var items []bulkAddShowItem
+	if err := json.Unmarshal(job.Items, &items); err != nil {
+		return BulkAddJobResponse{}, err
+	}
+	response := BulkAddJobResponse{
+		InternalJobBulkAddID: job.InternalJobBulkAddID,
+		Status:               job.Status,
+		Total:                len(items),
+		OnConflict:           job.OnConflict,
+		ImportEpisodes:       job.ImportEpisodes,
+		ErrorMessage:         job.ErrorMessage,
+		CreatedAt:            job.CreatedAt,
+		UpdatedAt:            job.UpdatedAt,
+	}
+	if len(job.Result) > 0 {
+		var result BulkAddShowsResponse
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			return BulkAddJobResponse{}, err
+		}
+		response.Result = &result
+	}
+	return response, nil
+}
@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	worker "github.com/keithics/devops-dashboard/api/internal/metadata/provider"
+	"github.com/keithics/devops-dashboard/api/internal/show"
+)
+
+func TestValidateBulkAddShowItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   []bulkAddShowItem
+		async   bool
+		wantErr bool
+	}{
+		{name: "empty", items: nil, wantErr: true},
+		{name: "distinct ids", items: []bulkAddShowItem{{ExternalID: "anilist:1"}, {ExternalID: "tvdb:1"}}},
+		{name: "same id twice", items: []bulkAddShowItem{{ExternalID: "anilist:1"}, {ExternalID: "ANILIST:1"}}, wantErr: true},
+		{name: "typed id matches prefixed id", items: []bulkAddShowItem{{ExternalID: "1", Type: "anilist"}, {ExternalID: "anilist:1"}}, wantErr: true},
+		{name: "same number on other providers", items: []bulkAddShowItem{{ExternalID: "1", Type: "anilist"}, {ExternalID: "1", Type: "tvdb"}}},
+		{name: "missing ids are left to the item", items: []bulkAddShowItem{{}, {}}},
+		{name: "over the sync limit", items: make([]bulkAddShowItem, maxBulkAddShows+1), wantErr: true},
+		{name: "async takes more", items: make([]bulkAddShowItem, maxBulkAddShows+1), async: true},
+		{name: "over the async limit", items: make([]bulkAddShowItem, maxBulkAddShowsAsync+1), async: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBulkAddShowItems(tt.items, tt.async)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseBulkOnConflict(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "", want: bulkOnConflictSkip},
+		{raw: " Skip ", want: bulkOnConflictSkip},
+		{raw: "return", want: show.OnConflictReturn},
+		{raw: "UPDATE", want: show.OnConflictUpdate},
+		{raw: "reject", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseBulkOnConflict(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseBulkOnConflict(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveBulkAddProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    bulkAddShowItem
+		want    worker.ProviderName
+		wantErr error
+	}{
+		{name: "type wins", item: bulkAddShowItem{ExternalID: "21", Type: "anilist"}, want: worker.ProviderAniList},
+		{name: "prefix", item: bulkAddShowItem{ExternalID: "tvdb:81189"}, want: worker.ProviderTVDB},
+		{name: "missing id", item: bulkAddShowItem{Type: "anilist"}, wantErr: worker.ErrInvalidExternalID},
+		{name: "no type or prefix", item: bulkAddShowItem{ExternalID: "21"}, wantErr: worker.ErrInvalidExternalID},
+		{name: "unknown type", item: bulkAddShowItem{ExternalID: "21", Type: "mal"}, wantErr: worker.ErrUnsupported},
+		{name: "unknown prefix", item: bulkAddShowItem{ExternalID: "mal:21"}, wantErr: worker.ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBulkAddProvider(tt.item)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("provider = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBulkAddShowsKeepsRequestOrder(t *testing.T) {
+	// Every item fails before any provider or database call, so a bare service will do.
+	items := make([]bulkAddShowItem, 2*bulkAddConcurrency+1)
+	for i := range items {
+		items[i] = bulkAddShowItem{ExternalID: "mal:" + string(rune('a'+i))}
+	}
+
+	result := (&Service{}).BulkAddShows(context.Background(), items, BulkAddOptions{OnConflict: bulkOnConflictSkip})
+	if result.Failed != len(items) || result.Created != 0 || result.Skipped != 0 {
+		t.Errorf("totals = failed %d created %d skipped %d, want %d 0 0", result.Failed, result.Created, result.Skipped, len(items))
+	}
+	for i, item := range result.Items {
+		if item.Index != i || item.ExternalID != items[i].ExternalID {
+			t.Errorf("item %d = index %d %q, want %q", i, item.Index, item.ExternalID, items[i].ExternalID)
+		}
+		if item.Status != BulkAddStatusError || !errors.Is(item.Err, worker.ErrUnsupported) {
+			t.Errorf("item %d = %s %v, want an unsupported provider error", i, item.Status, item.Err)
+		}
+	}
+}
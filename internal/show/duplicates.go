@@ -0,0 +1,122 @@
+package show
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+	"github.com/keithics/devops-dashboard/api/internal/utils/fuzzy"
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+)
+
+// OnConflict values choose what CreateShow does when the show already exists.
+const (
+	// OnConflictReject fails with a *DuplicateShowError.
+	OnConflictReject = ""
+	// OnConflictReturn returns the existing show unchanged, apart from linking new ids.
+	OnConflictReturn = "return"
+	// OnConflictUpdate merges the request into the existing show.
+	OnConflictUpdate = "update"
+)
+
+const (
+	MatchedByExternalID = "externalId"
+	MatchedByTitle      = "title"
+
+	// duplicateTitleThreshold is the title similarity above which two shows with the
+	// same start date are treated as the same show.
+	duplicateTitleThreshold = 0.9
+)
+
+func ParseOnConflict(raw string) (string, error) {
+	value := normalizeutil.LowerString(raw)
+	switch value {
+	case OnConflictReject, OnConflictReturn, OnConflictUpdate:
+		return value, nil
+	default:
+		return "", errors.New("onConflict must be one of return|update")
+	}
+}
+
+func (e *DuplicateShowError) Error() string {
+	return fmt.Sprintf("show already exists as %s (matched by %s)", e.Existing.InternalShowID, e.MatchedBy)
+}
+
+// showTitles returns every title a show is known by.
+func showTitles(preferred string, original *string, altTitles []string) []string {
+	titles := append([]string{preferred}, altTitles...)
+	if original != nil {
+		titles = append(titles, *original)
+	}
+	return titles
+}
+
+// isTitleMatch reports whether a stored show shares a title with the given titles.
+func isTitleMatch(titles []string, item sqlc.Show) bool {
+	return fuzzy.BestSimilarity(titles, showTitles(item.TitlePreferred, item.TitleOriginal, item.AltTitles)) >= duplicateTitleThreshold
+}
+
+// isLikelyDuplicate reports whether two stored shows share a start date and a title.
+func isLikelyDuplicate(a sqlc.Show, b sqlc.Show) bool {
+	if a.StartDate == nil || b.StartDate == nil || *a.StartDate != *b.StartDate {
+		return false
+	}
+	return isTitleMatch(showTitles(a.TitlePreferred, a.TitleOriginal, a.AltTitles), b)
+}
+
+// groupByExternalID expects rows ordered by the external id they share.
+func groupByExternalID(rows []sqlc.ListShowsSharingExternalIDRow) ([]duplicateGroupResponse, error) {
+	groups := make([]duplicateGroupResponse, 0)
+	for _, row := range rows {
+		response, err := toShowResponse(showFromSharingRow(row))
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(groups) - 1
+		if last >= 0 && groups[last].Key == row.SharedExternalID {
+			groups[last].Shows = append(groups[last].Shows, response)
+			continue
+		}
+		groups = append(groups, duplicateGroupResponse{
+			MatchedBy: MatchedByExternalID,
+			Key:       row.SharedExternalID,
+			Shows:     []showResponse{response},
+		})
+	}
+	return groups, nil
+}
+
+// groupByTitle expects shows that share a start date, ordered by it, and clusters each start date around
+// its oldest unclaimed show.
+func groupByTitle(items []sqlc.Show) ([]duplicateGroupResponse, error) {
+	groups := make([]duplicateGroupResponse, 0)
+	claimed := make([]bool, len(items))
+	for i := range items {
+		if claimed[i] {
+			continue
+		}
+		members := []sqlc.Show{items[i]}
+		for j := i + 1; j < len(items) && *items[j].StartDate == *items[i].StartDate; j++ {
+			if !claimed[j] && isLikelyDuplicate(items[i], items[j]) {
+				claimed[j] = true
+				members = append(members, items[j])
+			}
+		}
+		if len(members) < 2 {
+			continue
+		}
+
+		shows, err := httpx.MapSliceE(members, toShowResponse)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, duplicateGroupResponse{
+			MatchedBy: MatchedByTitle,
+			Key:       *items[i].StartDate + " " + items[i].TitlePreferred,
+			Shows:     shows,
+		})
+	}
+	return groups, nil
+}
@@ -0,0 +1,81 @@
+package show
+
+import (
+	"testing"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+)
+
+func TestIsTitleMatch(t *testing.T) {
+	original := "葬送のフリーレン"
+	stored := sqlc.Show{
+		TitlePreferred: "Frieren: Beyond Journey's End",
+		TitleOriginal:  &original,
+		AltTitles:      []string{"Sousou no Frieren"},
+	}
+
+	tests := []struct {
+		name   string
+		titles []string
+		want   bool
+	}{
+		{name: "preferred title with other punctuation", titles: []string{"Frieren - Beyond Journeys End"}, want: true},
+		{name: "original title", titles: []string{"Something Else", "葬送のフリーレン"}, want: true},
+		{name: "alternative title with a typo", titles: []string{"Sousou no Frieran"}, want: true},
+		{name: "accents and case", titles: []string{"SOUSOU NO FRIÉREN"}, want: true},
+		{name: "sequel", titles: []string{"Frieren: Beyond Journey's End Season 2"}, want: false},
+		{name: "shared word only", titles: []string{"Frieren"}, want: false},
+		{name: "no titles", titles: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTitleMatch(tt.titles, stored); got != tt.want {
+				t.Fatalf("isTitleMatch(%q) = %v, want %v", tt.titles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLikelyDuplicate(t *testing.T) {
+	date := "2023-09-29"
+	otherDate := "2023-10-06"
+
+	tests := []struct {
+		name string
+		a    sqlc.Show
+		b    sqlc.Show
+		want bool
+	}{
+		{
+			name: "same date and title",
+			a:    sqlc.Show{TitlePreferred: "Frieren", StartDate: &date},
+			b:    sqlc.Show{TitlePreferred: "frieren!", StartDate: &date},
+			want: true,
+		},
+		{
+			name: "same title on another date",
+			a:    sqlc.Show{TitlePreferred: "Frieren", StartDate: &date},
+			b:    sqlc.Show{TitlePreferred: "Frieren", StartDate: &otherDate},
+			want: false,
+		},
+		{
+			name: "missing start date",
+			a:    sqlc.Show{TitlePreferred: "Frieren"},
+			b:    sqlc.Show{TitlePreferred: "Frieren", StartDate: &date},
+			want: false,
+		},
+		{
+			name: "same date and different title",
+			a:    sqlc.Show{TitlePreferred: "Frieren", StartDate: &date},
+			b:    sqlc.Show{TitlePreferred: "Spy x Family", StartDate: &date},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLikelyDuplicate(tt.a, tt.b); got != tt.want {
+				t.Fatalf("isLikelyDuplicate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package show
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+)
+
+const (
+	ExternalProviderAniList = "anilist"
+	ExternalProviderAniDB   = "anidb"
+	ExternalProviderTVDB    = "tvdb"
+	ExternalProviderMAL     = "mal"
+	ExternalProviderIMDB    = "imdb"
+	ExternalProviderTMDB    = "tmdb"
+)
+
+const externalRefFormat = "provider:id with provider one of anilist|anidb|tvdb|mal|imdb|tmdb"
+
+var errInvalidExternalRef = errors.New("externalId must be " + externalRefFormat)
+
+// NewExternalRef validates a provider name and provider id pair.
+func NewExternalRef(provider string, id string) (ExternalRef, error) {
+	ref := ExternalRef{
+		Provider: normalizeutil.LowerString(provider),
+		ID:       normalizeutil.String(id),
+	}
+
+	switch ref.Provider {
+	case ExternalProviderAniList, ExternalProviderAniDB, ExternalProviderTVDB,
+		ExternalProviderMAL, ExternalProviderIMDB, ExternalProviderTMDB:
+	default:
+		return ExternalRef{}, errInvalidExternalRef
+	}
+	if err := httpx.ValidateVar(ref.ID, "required,max=128", "externalId is invalid"); err != nil {
+		return ExternalRef{}, err
+	}
+	return ref, nil
+}
+
+// ParseExternalRef parses a prefixed id such as "anilist:21" or "imdb:tt0903747".
+func ParseExternalRef(value string) (ExternalRef, error) {
+	provider, id, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return ExternalRef{}, errInvalidExternalRef
+	}
+	return NewExternalRef(provider, id)
+}
+
+func (r ExternalRef) String() string {
+	return r.Provider + ":" + r.ID
+}
+
+// ShowExternalRefs collects the references a show should be linked under: its primary
+// external id, when it names a known provider, followed by every linked id.
+func ShowExternalRefs(item Show) []ExternalRef {
+	values := append([]string{item.ExternalID}, item.LinkedIDs...)
+	refs := make([]ExternalRef, 0, len(values))
+	for _, value := range values {
+		ref, err := ParseExternalRef(value)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func validateLinkedIDs(values []string) error {
+	for i, value := range values {
+		if _, err := ParseExternalRef(value); err != nil {
+			return fmt.Errorf("linkedIds[%d] must be %s", i, externalRefFormat)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/hooks"
 	"github.com/keithics/devops-dashboard/api/internal/httperr"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
 )
@@ -11,23 +12,38 @@ import (
 // CreateShow godoc
 //
 //	@Summary		Create show
-//	@Description	Create a new show record
+//	@Description	Create a new show record. A show sharing an external id, or a start date and a similar title, is a conflict unless onConflict says otherwise.
 //	@Tags			shows
 //	@Accept			json
 //	@Produce		json
-//	@Param			payload	body		createShowRequest	true	"Show payload"
-//	@Success		201		{object}	showResponse
-//	@Failure		400		{object}	httperr.APIErrorResponse
-//	@Failure		500		{object}	httperr.APIErrorResponse
+//	@Param			onConflict	query		string				false	"Conflict policy: return|update (default: reject with 409)"
+//	@Param			payload		body		createShowRequest	true	"Show payload"
+//	@Success		200			{object}	showResponse		"Existing show returned or updated"
+//	@Success		201			{object}	showResponse		"New show created"
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		409			{object}	httperr.APIErrorResponse
+//	@Failure		422			{object}	httperr.APIErrorResponse
+//	@Failure		502			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
 //	@Router			/shows [post]
 func (h *Handler) CreateShow(c *gin.Context) {
 	req, ok := httpx.AbortIfMissingContext[createShowRequest](c, ctxCreateShowRequestKey)
 	if !ok {
 		return
 	}
+	onConflict, ok := httpx.AbortIfMissingContext[string](c, ctxOnConflictKey)
+	if !ok {
+		return
+	}
 
-	created, err := h.svc.CreateShow(c.Request.Context(), req)
+	item, created, err := h.svc.CreateShow(c.Request.Context(), req, onConflict)
 	if err != nil {
+		if AbortIfDuplicateErr(c, err) {
+			return
+		}
+		if hooks.AbortIfHookErr(c, err) {
+			return
+		}
 		if httpx.AbortIfDBErr(c, err, "failed to create show") {
 			return
 		}
@@ -35,26 +51,45 @@ func (h *Handler) CreateShow(c *gin.Context) {
 		return
 	}
 
-	response, err := toShowResponse(created)
+	response, err := toShowResponse(item)
 	if err != nil {
 		httperr.Abort(c, httperr.Internal("failed to format show response").WithCause(err))
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, response)
 }
 
 // ListShows godoc
 //
 //	@Summary		List shows
-//	@Description	List all shows
+//	@Description	List shows one page at a time, filtered and sorted. Pass nextCursor back as cursor, with the same sort and order, to get the next page.
 //	@Tags			shows
 //	@Produce		json
-//	@Success		200	{array}		showResponse
-//	@Failure		500	{object}	httperr.APIErrorResponse
+//	@Param			type			query		string	false	"Show type: anime|tv|movie|ova|special"
+//	@Param			status			query		string	false	"Show status: ongoing|finished"
+//	@Param			startDateFrom	query		string	false	"Earliest start date (YYYY-MM-DD)"
+//	@Param			startDateTo		query		string	false	"Latest start date (YYYY-MM-DD)"
+//	@Param			title			query		string	false	"Case-insensitive substring of any title"
+//	@Param			sort			query		string	false	"Sort: created|updated|title|startDate (default created)"
+//	@Param			order			query		string	false	"Order: asc|desc (default desc for created/updated, asc otherwise)"
+//	@Param			limit			query		int		false	"Page size (default 50, max 200)"
+//	@Param			cursor			query		string	false	"nextCursor of the previous page"
+//	@Success		200				{object}	listShowsResponse
+//	@Failure		400				{object}	httperr.APIErrorResponse
+//	@Failure		500				{object}	httperr.APIErrorResponse
 //	@Router			/shows [get]
 func (h *Handler) ListShows(c *gin.Context) {
-	items, err := h.svc.ListShows(c.Request.Context())
+	opts, ok := httpx.AbortIfMissingContext[ListShowsOpts](c, ctxListOptsKey)
+	if !ok {
+		return
+	}
+
+	page, err := h.svc.ListShows(c.Request.Context(), opts)
 	if err != nil {
 		if httpx.AbortIfDBErr(c, err, "failed to list shows") {
 			return
@@ -63,13 +98,53 @@ func (h *Handler) ListShows(c *gin.Context) {
 		return
 	}
 
-	response, err := httpx.MapSliceE(items, toShowResponse)
+	items, err := httpx.MapSliceE(page.Items, toShowResponse)
 	if err != nil {
 		httperr.Abort(c, httperr.Internal("failed to format show response").WithCause(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, listShowsResponse{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	})
+}
+
+// SearchShows godoc
+//
+//	@Summary		Search local shows
+//	@Description	Rank local shows against a free-text query using full-text search over the titles and synopsis plus trigram similarity on the titles, so typos and title variants still match
+//	@Tags			shows
+//	@Produce		json
+//	@Param			q		query		string	true	"Search text"
+//	@Param			limit	query		int		false	"Maximum results (default 20, max 100)"
+//	@Success		200		{object}	searchShowsResponse
+//	@Failure		400		{object}	httperr.APIErrorResponse
+//	@Failure		500		{object}	httperr.APIErrorResponse
+//	@Router			/shows/search [get]
+func (h *Handler) SearchShows(c *gin.Context) {
+	opts, ok := httpx.AbortIfMissingContext[SearchShowsOpts](c, ctxSearchOptsKey)
+	if !ok {
+		return
+	}
+
+	results, err := h.svc.SearchShows(c.Request.Context(), opts)
+	if err != nil {
+		if httpx.AbortIfDBErr(c, err, "failed to search shows") {
+			return
+		}
+		httperr.Abort(c, httperr.Internal("failed to search shows").WithCause(err))
+		return
+	}
+
+	items, err := httpx.MapSliceE(results, toSearchShowResultResponse)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to format show response").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, searchShowsResponse{Items: items})
 }
 
 // GetShow godoc
@@ -120,6 +195,8 @@ func (h *Handler) GetShow(c *gin.Context) {
 //	@Success		200				{object}	showResponse
 //	@Failure		400				{object}	httperr.APIErrorResponse
 //	@Failure		404				{object}	httperr.APIErrorResponse
+//	@Failure		422				{object}	httperr.APIErrorResponse
+//	@Failure		502				{object}	httperr.APIErrorResponse
 //	@Failure		500				{object}	httperr.APIErrorResponse
 //	@Router			/shows/{internalShowId} [put]
 func (h *Handler) UpdateShow(c *gin.Context) {
@@ -134,6 +211,9 @@ func (h *Handler) UpdateShow(c *gin.Context) {
 
 	updated, err := h.svc.UpdateShow(c.Request.Context(), showID, req)
 	if err != nil {
+		if hooks.AbortIfHookErr(c, err) {
+			return
+		}
 		if httpx.AbortIfDBErr(c, err, "failed to update show") {
 			return
 		}
@@ -160,6 +240,8 @@ func (h *Handler) UpdateShow(c *gin.Context) {
 //	@Success		204
 //	@Failure		400	{object}	httperr.APIErrorResponse
 //	@Failure		404	{object}	httperr.APIErrorResponse
+//	@Failure		422	{object}	httperr.APIErrorResponse
+//	@Failure		502	{object}	httperr.APIErrorResponse
 //	@Failure		500	{object}	httperr.APIErrorResponse
 //	@Router			/shows/{internalShowId} [delete]
 func (h *Handler) DeleteShow(c *gin.Context) {
@@ -170,6 +252,9 @@ func (h *Handler) DeleteShow(c *gin.Context) {
 
 	err := h.svc.DeleteShow(c.Request.Context(), showID)
 	if err != nil {
+		if hooks.AbortIfHookErr(c, err) {
+			return
+		}
 		if httpx.AbortIfDBErr(c, err, "failed to delete show") {
 			return
 		}
@@ -201,3 +286,160 @@ func (h *Handler) ListWorkerData(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetShowByExternalID godoc
+//
+//	@Summary		Resolve show by external id
+//	@Description	Get the show linked to a provider id (anilist, anidb, tvdb, mal, imdb or tmdb)
+//	@Tags			shows
+//	@Produce		json
+//	@Param			provider	path		string	true	"Provider: anilist|anidb|tvdb|mal|imdb|tmdb"
+//	@Param			id			path		string	true	"Provider id"
+//	@Success		200			{object}	showResponse
+//	@Failure		400			{object}	httperr.APIErrorResponse
+//	@Failure		404			{object}	httperr.APIErrorResponse
+//	@Failure		500			{object}	httperr.APIErrorResponse
+//	@Router			/shows/by-external/{provider}/{id} [get]
+func (h *Handler) GetShowByExternalID(c *gin.Context) {
+	ref, ok := httpx.AbortIfMissingContext[ExternalRef](c, ctxExternalRefKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.ResolveExternalID(c.Request.Context(), ref)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "no show is linked to "+ref.String(), "failed to resolve show") {
+		return
+	}
+
+	response, err := toShowResponse(item)
+	if err != nil {
+		httperr.Abort(c, httperr.Internal("failed to format show response").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListExternalIDs godoc
+//
+//	@Summary		List show external ids
+//	@Description	List every provider id linked to a show
+//	@Tags			shows
+//	@Produce		json
+//	@Param			internalShowId	path		string	true	"Internal show UUID"
+//	@Success		200				{array}		externalIDResponse
+//	@Failure		400				{object}	httperr.APIErrorResponse
+//	@Failure		404				{object}	httperr.APIErrorResponse
+//	@Failure		500				{object}	httperr.APIErrorResponse
+//	@Router			/shows/{internalShowId}/external-ids [get]
+func (h *Handler) ListExternalIDs(c *gin.Context) {
+	showID, ok := httpx.AbortIfMissingContext[string](c, ctxShowIDKey)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListExternalIDs(c.Request.Context(), showID)
+	if err != nil {
+		if httpx.AbortIfDBErr(c, err, "failed to list show external ids") {
+			return
+		}
+		httperr.Abort(c, httperr.Internal("failed to list show external ids").WithCause(err))
+		return
+	}
+
+	response := make([]externalIDResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toExternalIDResponse(item))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AddExternalID godoc
+//
+//	@Summary		Link show external id
+//	@Description	Link a provider id such as "mal:5114" to a show; an id can belong to one show only
+//	@Tags			shows
+//	@Accept			json
+//	@Produce		json
+//	@Param			internalShowId	path		string					true	"Internal show UUID"
+//	@Param			payload			body		addExternalIDRequest	true	"External id payload"
+//	@Success		201				{object}	externalIDResponse
+//	@Failure		400				{object}	httperr.APIErrorResponse
+//	@Failure		404				{object}	httperr.APIErrorResponse
+//	@Failure		409				{object}	httperr.APIErrorResponse
+//	@Failure		500				{object}	httperr.APIErrorResponse
+//	@Router			/shows/{internalShowId}/external-ids [post]
+func (h *Handler) AddExternalID(c *gin.Context) {
+	showID, ok := httpx.AbortIfMissingContext[string](c, ctxShowIDKey)
+	if !ok {
+		return
+	}
+	ref, ok := httpx.AbortIfMissingContext[ExternalRef](c, ctxExternalRefKey)
+	if !ok {
+		return
+	}
+
+	created, err := h.svc.AddExternalID(c.Request.Context(), showID, ref)
+	if err != nil {
+		if httpx.AbortIfDBErr(c, err, "failed to link show external id") {
+			return
+		}
+		httperr.Abort(c, httperr.Internal("failed to link show external id").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, toExternalIDResponse(created))
+}
+
+// RemoveExternalID godoc
+//
+//	@Summary		Unlink show external id
+//	@Description	Remove a provider id such as "mal:5114" from a show
+//	@Tags			shows
+//	@Produce		json
+//	@Param			internalShowId	path	string	true	"Internal show UUID"
+//	@Param			externalId		path	string	true	"Prefixed provider id"
+//	@Success		204
+//	@Failure		400	{object}	httperr.APIErrorResponse
+//	@Failure		404	{object}	httperr.APIErrorResponse
+//	@Failure		500	{object}	httperr.APIErrorResponse
+//	@Router			/shows/{internalShowId}/external-ids/{externalId} [delete]
+func (h *Handler) RemoveExternalID(c *gin.Context) {
+	showID, ok := httpx.AbortIfMissingContext[string](c, ctxShowIDKey)
+	if !ok {
+		return
+	}
+	ref, ok := httpx.AbortIfMissingContext[ExternalRef](c, ctxExternalRefKey)
+	if !ok {
+		return
+	}
+
+	err := h.svc.RemoveExternalID(c.Request.Context(), showID, ref)
+	if httpx.AbortDBErrNotFoundMsg(c, err, "external id is not linked to this show", "failed to unlink show external id") {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDuplicates godoc
+//
+//	@Summary		List likely duplicate shows
+//	@Description	Group stored shows saved under the same external id, or sharing a start date and a similar title
+//	@Tags			shows
+//	@Produce		json
+//	@Success		200	{array}		duplicateGroupResponse
+//	@Failure		500	{object}	httperr.APIErrorResponse
+//	@Router			/shows/duplicates [get]
+func (h *Handler) ListDuplicates(c *gin.Context) {
+	response, err := h.svc.ListDuplicates(c.Request.Context())
+	if err != nil {
+		if httpx.AbortIfDBErr(c, err, "failed to list duplicate shows") {
+			return
+		}
+		httperr.Abort(c, httperr.Internal("failed to list duplicate shows").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
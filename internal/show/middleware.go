@@ -3,6 +3,7 @@ package show
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
 )
 
 func (h *Handler) BindCreateShow() gin.HandlerFunc {
@@ -17,7 +18,13 @@ func (h *Handler) BindCreateShow() gin.HandlerFunc {
 			return
 		}
 
+		onConflict, err := ParseOnConflict(c.Query("onConflict"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+
 		c.Set(ctxCreateShowRequestKey, req)
+		c.Set(ctxOnConflictKey, onConflict)
 		c.Next()
 	}
 }
@@ -49,3 +56,91 @@ func (h *Handler) BindShowID() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (h *Handler) BindExternalRef() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ref, err := NewExternalRef(c.Param("provider"), c.Param("id"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		c.Set(ctxExternalRefKey, ref)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindExternalIDParam() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ref, err := ParseExternalRef(c.Param("externalId"))
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		c.Set(ctxExternalRefKey, ref)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindAddExternalID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req addExternalIDRequest
+		if httpx.AbortIfErr(c, c.ShouldBindJSON(&req)) {
+			return
+		}
+
+		ref, err := ParseExternalRef(req.ExternalID)
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+
+		c.Set(ctxExternalRefKey, ref)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindSearchShows() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := SearchShowsOpts{
+			Query: normalizeutil.String(c.Query("q")),
+			Limit: normalizeutil.Limit(httpx.ParsePositiveInt(c.Query("limit"), defaultSearchLimit), defaultSearchLimit, maxSearchLimit),
+		}
+		if httpx.AbortIfErr(c, validateSearchShowsOpts(opts)) {
+			return
+		}
+		c.Set(ctxSearchOptsKey, opts)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindListShows() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := ListShowsOpts{
+			Type:          normalizeutil.StringValuePtr(normalizeutil.LowerString(c.Query("type"))),
+			Status:        normalizeutil.StringValuePtr(normalizeutil.LowerString(c.Query("status"))),
+			StartDateFrom: normalizeutil.StringValuePtr(c.Query("startDateFrom")),
+			StartDateTo:   normalizeutil.StringValuePtr(c.Query("startDateTo")),
+			Title:         normalizeutil.StringValuePtr(c.Query("title")),
+			Sort:          normalizeutil.String(c.DefaultQuery("sort", SortCreated)),
+			Limit:         normalizeutil.Limit(httpx.ParsePositiveInt(c.Query("limit"), defaultListLimit), defaultListLimit, maxListLimit),
+		}
+		if httpx.AbortIfErr(c, validateListShowsOpts(opts)) {
+			return
+		}
+
+		desc, err := parseSortOrder(c.Query("order"), opts.Sort)
+		if httpx.AbortIfErr(c, err) {
+			return
+		}
+		opts.Desc = desc
+
+		if raw := normalizeutil.String(c.Query("cursor")); raw != "" {
+			cursor, err := decodeShowCursor(raw, opts.Sort, opts.Desc)
+			if httpx.AbortIfErr(c, err) {
+				return
+			}
+			opts.CursorKey = cursor.Key
+			opts.CursorID = &cursor.ID
+		}
+
+		c.Set(ctxListOptsKey, opts)
+		c.Next()
+	}
+}
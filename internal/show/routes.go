@@ -3,10 +3,16 @@ package show
 import "github.com/gin-gonic/gin"
 
 func RegisterRoutes(r *gin.Engine, h *Handler) {
-	r.GET("/shows", h.ListShows)
+	r.GET("/shows", h.BindListShows(), h.ListShows)
+	r.GET("/shows/search", h.BindSearchShows(), h.SearchShows)
 	r.GET("/shows/worker", h.ListWorkerData)
+	r.GET("/shows/duplicates", h.ListDuplicates)
+	r.GET("/shows/by-external/:provider/:id", h.BindExternalRef(), h.GetShowByExternalID)
 	r.GET("/shows/:internalShowId", h.BindShowID(), h.GetShow)
 	r.POST("/shows", h.BindCreateShow(), h.CreateShow)
 	r.PUT("/shows/:internalShowId", h.BindShowID(), h.BindUpdateShow(), h.UpdateShow)
 	r.DELETE("/shows/:internalShowId", h.BindShowID(), h.DeleteShow)
+	r.GET("/shows/:internalShowId/external-ids", h.BindShowID(), h.ListExternalIDs)
+	r.POST("/shows/:internalShowId/external-ids", h.BindShowID(), h.BindAddExternalID(), h.AddExternalID)
+	r.DELETE("/shows/:internalShowId/external-ids/:externalId", h.BindShowID(), h.BindExternalIDParam(), h.RemoveExternalID)
 }
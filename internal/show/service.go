@@ -2,34 +2,41 @@ package show
 
 import (
 	"context"
+	"errors"
+	"slices"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/db"
 	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/hooks"
 	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
 )
 
-func NewHandler(q *sqlc.Queries) *Handler {
-	return NewHandlerWithHooks(q, hooks.NoopDispatcher{})
+func NewHandler(pool *pgxpool.Pool) *Handler {
+	return NewHandlerWithHooks(pool, hooks.NoopDispatcher{})
 }
 
-func NewHandlerWithHooks(q *sqlc.Queries, dispatcher hooks.Dispatcher) *Handler {
+func NewHandlerWithHooks(pool *pgxpool.Pool, dispatcher hooks.Dispatcher) *Handler {
 	if dispatcher == nil {
 		dispatcher = hooks.NoopDispatcher{}
 	}
 
-	service := NewService(q, dispatcher)
+	service := NewService(pool, dispatcher)
 
 	return &Handler{
 		svc: service,
 	}
 }
 
-func NewService(q *sqlc.Queries, dispatcher hooks.Dispatcher) *Service {
+func NewService(pool *pgxpool.Pool, dispatcher hooks.Dispatcher) *Service {
 	if dispatcher == nil {
 		dispatcher = hooks.NoopDispatcher{}
 	}
 	return &Service{
-		q:     q,
+		pool:  pool,
+		db:    pool,
+		q:     sqlc.New(pool),
 		hooks: dispatcher,
 	}
 }
@@ -38,43 +45,336 @@ func (h *Handler) Service() *Service {
 	return h.svc
 }
 
-func (s *Service) CreateShow(ctx context.Context, req Show) (sqlc.Show, error) {
-	createReq := createShowRequest(req)
+// WithTx returns a copy of the service whose queries and post hooks run inside tx.
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		db:    tx,
+		q:     s.q.WithTx(tx),
+		hooks: s.hooks.WithTx(tx),
+	}
+}
 
-	if err := s.hooks.DispatchPre(ctx, hooks.EventShowCreatePre, req); err != nil {
-		return sqlc.Show{}, err
+// inTx runs fn with a copy of the service bound to a new transaction, so a write commits
+// together with the post hooks it records. A service already bound to a transaction
+// passes itself.
+func (s *Service) inTx(ctx context.Context, fn func(tx *Service) error) error {
+	if s.pool == nil {
+		return fn(s)
 	}
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(s.WithTx(tx))
+	})
+}
 
-	externalIDs, err := marshalExternalID(createReq.ExternalID)
+// CreateShow inserts req unless a show with one of its external ids, or with a matching
+// title and start date, already exists. What happens then is chosen by onConflict; created
+// is false whenever an existing show is returned.
+func (s *Service) CreateShow(ctx context.Context, req Show, onConflict string) (sqlc.Show, bool, error) {
+	prepared, err := s.PrepareCreateShow(ctx, req, onConflict)
 	if err != nil {
-		return sqlc.Show{}, err
+		return sqlc.Show{}, false, err
+	}
+	return s.InsertShow(ctx, prepared)
+}
+
+// PrepareCreateShow runs everything CreateShow does before writing: duplicate detection
+// and the show.create.pre hooks, or the show.update.pre hooks of the show to update when
+// onConflict is OnConflictUpdate. Hooks are called over HTTP, so callers that write the
+// show inside their own transaction prepare it first and pass the result to InsertShow.
+func (s *Service) PrepareCreateShow(ctx context.Context, req Show, onConflict string) (PreparedShow, error) {
+	prepared := PreparedShow{req: req, onConflict: onConflict}
+	existing, matchedBy, err := s.FindDuplicate(ctx, req)
+	if err != nil {
+		return PreparedShow{}, err
+	}
+	if matchedBy != "" {
+		return s.prepareDuplicate(ctx, prepared, existing, matchedBy)
+	}
+
+	patched, err := s.hooks.DispatchPre(ctx, hooks.EventShowCreatePre, &prepared.req)
+	if err != nil {
+		return PreparedShow{}, err
+	}
+	if patched {
+		normalizeCreateShowRequest(&prepared.req)
+		if err := validateCreateShowRequest(prepared.req); err != nil {
+			return PreparedShow{}, hooks.InvalidPatchError(hooks.EventShowCreatePre, err)
+		}
+		// A patch can change the titles, start date or external ids checked above.
+		existing, matchedBy, err := s.FindDuplicate(ctx, prepared.req)
+		if err != nil {
+			return PreparedShow{}, err
+		}
+		if matchedBy != "" {
+			return s.prepareDuplicate(ctx, prepared, existing, matchedBy)
+		}
+	}
+	return prepared, nil
+}
+
+func (s *Service) prepareDuplicate(ctx context.Context, prepared PreparedShow, existing sqlc.Show, matchedBy string) (PreparedShow, error) {
+	switch prepared.onConflict {
+	case OnConflictReturn:
+	case OnConflictUpdate:
+		current, err := ToShow(existing)
+		if err != nil {
+			return PreparedShow{}, err
+		}
+		update, err := s.prepareUpdateShow(ctx, existing.InternalShowID, MergeShow(current, prepared.req))
+		if err != nil {
+			return PreparedShow{}, err
+		}
+		prepared.update = &update
+	default:
+		return PreparedShow{}, &DuplicateShowError{Existing: existing, MatchedBy: matchedBy}
+	}
+	prepared.existing = &existing
+	return prepared, nil
+}
+
+// InsertShow writes a show prepared by PrepareCreateShow, or links its external ids to the
+// show it duplicates and applies the prepared update. It only writes to the database.
+// Duplicate detection runs again under a lock on the show's external ids and start date,
+// so a matching show stored since it was prepared is caught: it is returned when
+// onConflict is OnConflictReturn and reported as a *DuplicateShowError otherwise, as the
+// update pre hooks cannot be called from inside the transaction.
+func (s *Service) InsertShow(ctx context.Context, prepared PreparedShow) (sqlc.Show, bool, error) {
+	if prepared.existing != nil {
+		resolved, err := s.resolveDuplicate(ctx, prepared)
+		return resolved, false, err
+	}
+
+	req := prepared.req
+	externalIDs, err := marshalExternalID(req.ExternalID)
+	if err != nil {
+		return sqlc.Show{}, false, err
+	}
+
+	var stored sqlc.Show
+	created := true
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.lockDuplicateKeys(ctx, req); err != nil {
+			return err
+		}
+		existing, matchedBy, err := tx.FindDuplicate(ctx, req)
+		if err != nil {
+			return err
+		}
+		if matchedBy != "" {
+			if prepared.onConflict != OnConflictReturn {
+				return &DuplicateShowError{Existing: existing, MatchedBy: matchedBy}
+			}
+			stored, created = existing, false
+			_, err := tx.linkExternalRefs(ctx, existing.InternalShowID, ShowExternalRefs(req))
+			return err
+		}
+
+		stored, err = tx.q.CreateShow(ctx, sqlc.CreateShowParams{
+			TitlePreferred: req.TitlePreferred,
+			TitleOriginal:  req.TitleOriginal,
+			AltTitles:      req.AltTitles,
+			Type:           req.Type,
+			Status:         req.Status,
+			Synopsis:       req.Synopsis,
+			StartDate:      req.StartDate,
+			EndDate:        req.EndDate,
+			PosterUrl:      req.PosterUrl,
+			BannerUrl:      req.BannerUrl,
+			SeasonCount:    req.SeasonCount,
+			EpisodeCount:   req.EpisodeCount,
+			ExternalIds:    externalIDs,
+		})
+		if err != nil {
+			return err
+		}
+		taken, err := tx.linkExternalRefs(ctx, stored.InternalShowID, ShowExternalRefs(req))
+		if err != nil {
+			return err
+		}
+		for _, ref := range taken {
+			// Linked by a path that does not take the lock, unless req lists the id twice;
+			// the new show is rolled back.
+			existing, err := tx.ResolveExternalID(ctx, ref)
+			if err != nil {
+				return err
+			}
+			if existing.InternalShowID != stored.InternalShowID {
+				return &DuplicateShowError{Existing: existing, MatchedBy: MatchedByExternalID}
+			}
+		}
+		return tx.hooks.DispatchPost(ctx, hooks.EventShowCreatePost, stored)
+	})
+	if err != nil {
+		return sqlc.Show{}, false, err
+	}
+	return stored, created, nil
+}
+
+// lockDuplicateKeys holds a transaction lock on every external id of req and on its start
+// date, the keys FindDuplicate matches on, so creates of the same show run one at a time.
+// Keys are locked in order so that creates sharing several of them cannot deadlock.
+func (s *Service) lockDuplicateKeys(ctx context.Context, req Show) error {
+	keys := make([]string, 0)
+	for _, ref := range ShowExternalRefs(req) {
+		keys = append(keys, "show.external_id:"+ref.String())
+	}
+	if req.StartDate != nil {
+		keys = append(keys, "show.start_date:"+*req.StartDate)
+	}
+	slices.Sort(keys)
+	for _, key := range slices.Compact(keys) {
+		if err := s.q.LockShowDuplicateKey(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindDuplicate returns the stored show req would duplicate and how it matched, first by
+// external id and then by start date plus a similar title. matchedBy is empty when there
+// is none.
+func (s *Service) FindDuplicate(ctx context.Context, req Show) (sqlc.Show, string, error) {
+	existing, found, err := s.FindShowByExternalIDs(ctx, req)
+	if err != nil {
+		return sqlc.Show{}, "", err
+	}
+	if found {
+		return existing, MatchedByExternalID, nil
+	}
+
+	if req.StartDate == nil {
+		return sqlc.Show{}, "", nil
 	}
+	candidates, err := s.q.ListShowsByStartDate(ctx, req.StartDate)
+	if err != nil {
+		return sqlc.Show{}, "", err
+	}
+	titles := showTitles(req.TitlePreferred, req.TitleOriginal, req.AltTitles)
+	for _, candidate := range candidates {
+		if isTitleMatch(titles, candidate) {
+			return candidate, MatchedByTitle, nil
+		}
+	}
+	return sqlc.Show{}, "", nil
+}
 
-	created, err := s.q.CreateShow(ctx, sqlc.CreateShowParams{
-		TitlePreferred: createReq.TitlePreferred,
-		TitleOriginal:  createReq.TitleOriginal,
-		AltTitles:      createReq.AltTitles,
-		Type:           createReq.Type,
-		Status:         createReq.Status,
-		Synopsis:       createReq.Synopsis,
-		StartDate:      createReq.StartDate,
-		EndDate:        createReq.EndDate,
-		PosterUrl:      createReq.PosterUrl,
-		BannerUrl:      createReq.BannerUrl,
-		SeasonCount:    createReq.SeasonCount,
-		EpisodeCount:   createReq.EpisodeCount,
-		ExternalIds:    externalIDs,
+func (s *Service) resolveDuplicate(ctx context.Context, prepared PreparedShow) (sqlc.Show, error) {
+	existing := *prepared.existing
+	resolved := existing
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.LinkExternalIDs(ctx, existing.InternalShowID, prepared.req); err != nil {
+			return err
+		}
+		if prepared.update == nil {
+			return nil
+		}
+		var err error
+		resolved, err = tx.updateShow(ctx, existing.InternalShowID, *prepared.update)
+		return err
 	})
 	if err != nil {
 		return sqlc.Show{}, err
 	}
+	return resolved, nil
+}
+
+// ListDuplicates groups stored shows that are likely the same: shows saved under an
+// external id that show_external_ids links to another show, then shows with the same
+// start date and a similar title.
+func (s *Service) ListDuplicates(ctx context.Context) ([]duplicateGroupResponse, error) {
+	groups := make([]duplicateGroupResponse, 0)
+
+	sharing, err := s.q.ListShowsSharingExternalID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byExternalID, err := groupByExternalID(sharing)
+	if err != nil {
+		return nil, err
+	}
+	groups = append(groups, byExternalID...)
+
+	dated, err := s.q.ListShowsSharingStartDate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byTitle, err := groupByTitle(dated)
+	if err != nil {
+		return nil, err
+	}
+	groups = append(groups, byTitle...)
+
+	return groups, nil
+}
+
+// ListShows returns one page of shows matching opts plus the total number of matches.
+func (s *Service) ListShows(ctx context.Context, opts ListShowsOpts) (ShowsPage, error) {
+	filter := newShowListQuery(opts)
+	var total int64
+	if err := s.db.QueryRow(ctx, filter.countSQL(), filter.args...).Scan(&total); err != nil {
+		return ShowsPage{}, err
+	}
+
+	query := newShowListQuery(opts)
+	if opts.CursorID != nil {
+		if err := query.addAfterCursor(opts.Sort, opts.Desc, opts.CursorKey, *opts.CursorID); err != nil {
+			return ShowsPage{}, err
+		}
+	}
+	rows, err := s.db.Query(ctx, query.pageSQL(opts.Sort, opts.Desc, opts.Limit+1), query.args...)
+	if err != nil {
+		return ShowsPage{}, err
+	}
+	listed, err := pgx.CollectRows(rows, scanShowRow)
+	if err != nil {
+		return ShowsPage{}, err
+	}
+
+	page := ShowsPage{Items: make([]sqlc.Show, 0, min(len(listed), opts.Limit)), Total: total}
+	for _, row := range listed[:min(len(listed), opts.Limit)] {
+		page.Items = append(page.Items, row.Show)
+	}
+	if len(listed) > opts.Limit {
+		last := listed[opts.Limit-1]
+		cursor, err := encodeShowCursor(showCursor{
+			Sort: opts.Sort,
+			Desc: opts.Desc,
+			Key:  showSortKey(last, opts.Sort),
+			ID:   last.Show.InternalShowID,
+		})
+		if err != nil {
+			return ShowsPage{}, err
+		}
+		page.NextCursor = &cursor
+	}
+	return page, nil
+}
 
-	s.hooks.DispatchPost(ctx, hooks.EventShowCreatePost, created)
-	return created, nil
+// SearchShows ranks local shows against a free-text query. Full-text matches on the
+// titles and synopsis are combined with trigram similarity on the titles, so typos and
+// romaji or English variants still match.
+func (s *Service) SearchShows(ctx context.Context, opts SearchShowsOpts) ([]SearchResult, error) {
+	rows, err := s.q.SearchShows(ctx, sqlc.SearchShowsParams{
+		Query:    opts.Query,
+		RowLimit: int32(opts.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, SearchResult{
+			Show:         showFromSearchRow(row),
+			Score:        row.Score,
+			MatchedField: row.MatchedField,
+		})
+	}
+	return results, nil
 }
 
-func (s *Service) ListShows(ctx context.Context) ([]sqlc.Show, error) {
-	return s.q.ListShows(ctx)
+func (s *Service) ListShowsByStatus(ctx context.Context, status string) ([]sqlc.Show, error) {
+	return s.q.ListShowsByStatus(ctx, status)
 }
 
 func (s *Service) GetShowByID(ctx context.Context, showID string) (sqlc.Show, error) {
@@ -82,58 +382,181 @@ func (s *Service) GetShowByID(ctx context.Context, showID string) (sqlc.Show, er
 }
 
 func (s *Service) UpdateShow(ctx context.Context, showID string, req updateShowRequest) (sqlc.Show, error) {
-	if err := s.hooks.DispatchPre(ctx, hooks.EventShowUpdatePre, map[string]any{
-		"internalShowId": showID,
-		"request":        req,
-	}); err != nil {
+	req, err := s.prepareUpdateShow(ctx, showID, req)
+	if err != nil {
 		return sqlc.Show{}, err
 	}
+	return s.updateShow(ctx, showID, req)
+}
 
+// prepareUpdateShow runs the show.update.pre hooks and returns the request to store.
+func (s *Service) prepareUpdateShow(ctx context.Context, showID string, req updateShowRequest) (updateShowRequest, error) {
+	patched, err := s.hooks.DispatchPre(ctx, hooks.EventShowUpdatePre, &updateShowHookPayload{
+		InternalShowID: showID,
+		Request:        &req,
+	})
+	if err != nil {
+		return updateShowRequest{}, err
+	}
+	if patched {
+		normalizeUpdateShowRequest(&req)
+		if err := validateUpdateShowRequest(req); err != nil {
+			return updateShowRequest{}, hooks.InvalidPatchError(hooks.EventShowUpdatePre, err)
+		}
+	}
+	return req, nil
+}
+
+func (s *Service) updateShow(ctx context.Context, showID string, req updateShowRequest) (sqlc.Show, error) {
 	externalIDs, err := marshalExternalID(req.ExternalID)
 	if err != nil {
 		return sqlc.Show{}, err
 	}
 
-	updated, err := s.q.UpdateShow(ctx, sqlc.UpdateShowParams{
-		InternalShowID: showID,
-		TitlePreferred: req.TitlePreferred,
-		TitleOriginal:  req.TitleOriginal,
-		AltTitles:      req.AltTitles,
-		Type:           req.Type,
-		Status:         req.Status,
-		Synopsis:       req.Synopsis,
-		StartDate:      req.StartDate,
-		EndDate:        req.EndDate,
-		PosterUrl:      req.PosterUrl,
-		BannerUrl:      req.BannerUrl,
-		SeasonCount:    req.SeasonCount,
-		EpisodeCount:   req.EpisodeCount,
-		ExternalIds:    externalIDs,
+	var updated sqlc.Show
+	err = s.inTx(ctx, func(tx *Service) error {
+		updated, err = tx.q.UpdateShow(ctx, sqlc.UpdateShowParams{
+			InternalShowID: showID,
+			TitlePreferred: req.TitlePreferred,
+			TitleOriginal:  req.TitleOriginal,
+			AltTitles:      req.AltTitles,
+			Type:           req.Type,
+			Status:         req.Status,
+			Synopsis:       req.Synopsis,
+			StartDate:      req.StartDate,
+			EndDate:        req.EndDate,
+			PosterUrl:      req.PosterUrl,
+			BannerUrl:      req.BannerUrl,
+			SeasonCount:    req.SeasonCount,
+			EpisodeCount:   req.EpisodeCount,
+			ExternalIds:    externalIDs,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.linkExternalRefs(ctx, updated.InternalShowID, ShowExternalRefs(req)); err != nil {
+			return err
+		}
+		return tx.hooks.DispatchPost(ctx, hooks.EventShowUpdatePost, updated)
 	})
 	if err != nil {
 		return sqlc.Show{}, err
 	}
-
-	s.hooks.DispatchPost(ctx, hooks.EventShowUpdatePost, updated)
 	return updated, nil
 }
 
 func (s *Service) DeleteShow(ctx context.Context, showID string) error {
-	if err := s.hooks.DispatchPre(ctx, hooks.EventShowDeletePre, map[string]any{
+	if _, err := s.hooks.DispatchPre(ctx, hooks.EventShowDeletePre, map[string]any{
 		"internalShowId": showID,
 	}); err != nil {
 		return err
 	}
 
-	_, err := s.q.DeleteShow(ctx, showID)
-	if err != nil {
-		return err
+	return s.inTx(ctx, func(tx *Service) error {
+		if _, err := tx.q.DeleteShow(ctx, showID); err != nil {
+			return err
+		}
+		return tx.hooks.DispatchPost(ctx, hooks.EventShowDeletePost, map[string]any{
+			"internalShowId": showID,
+		})
+	})
+}
+
+// ResolveExternalID returns the show linked to ref; it returns pgx.ErrNoRows when no show is.
+func (s *Service) ResolveExternalID(ctx context.Context, ref ExternalRef) (sqlc.Show, error) {
+	return s.q.GetShowByExternalID(ctx, sqlc.GetShowByExternalIDParams{
+		Provider:   ref.Provider,
+		ExternalID: ref.ID,
+	})
+}
+
+// FindShowByExternalIDs resolves the primary and linked ids of item in order and returns
+// the first show already linked to one of them. found is false when none is known yet.
+func (s *Service) FindShowByExternalIDs(ctx context.Context, item Show) (sqlc.Show, bool, error) {
+	for _, ref := range ShowExternalRefs(item) {
+		existing, err := s.ResolveExternalID(ctx, ref)
+		if err == nil {
+			return existing, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.Show{}, false, err
+		}
 	}
+	return sqlc.Show{}, false, nil
+}
 
-	s.hooks.DispatchPost(ctx, hooks.EventShowDeletePost, map[string]any{
-		"internalShowId": showID,
+// LinkExternalIDs links the primary and linked ids of item to showID. Ids that already
+// belong to a show, this one or another, are left as they are.
+func (s *Service) LinkExternalIDs(ctx context.Context, showID string, item Show) error {
+	_, err := s.linkExternalRefs(ctx, showID, ShowExternalRefs(item))
+	return err
+}
+
+// linkExternalRefs links refs to showID and returns the ones that were already linked, to
+// this show or another, and were left as they are.
+func (s *Service) linkExternalRefs(ctx context.Context, showID string, refs []ExternalRef) ([]ExternalRef, error) {
+	var taken []ExternalRef
+	for _, ref := range refs {
+		linked, err := s.q.LinkShowExternalID(ctx, sqlc.LinkShowExternalIDParams{
+			Provider:   ref.Provider,
+			ExternalID: ref.ID,
+			ShowID:     showID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if linked == 0 {
+			taken = append(taken, ref)
+		}
+	}
+	return taken, nil
+}
+
+// KnownExternalIDs returns every external id of the local shows linked to any of refs,
+// ordered by show.
+func (s *Service) KnownExternalIDs(ctx context.Context, refs []ExternalRef) ([]sqlc.ShowExternalID, error) {
+	if len(refs) == 0 {
+		return []sqlc.ShowExternalID{}, nil
+	}
+	providers := make([]string, 0, len(refs))
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		providers = append(providers, ref.Provider)
+		ids = append(ids, ref.ID)
+	}
+	return s.q.ListShowExternalIDsForRefs(ctx, sqlc.ListShowExternalIDsForRefsParams{
+		Providers:   providers,
+		ExternalIds: ids,
 	})
-	return nil
+}
+
+func (s *Service) ListExternalIDs(ctx context.Context, showID string) ([]sqlc.ShowExternalID, error) {
+	if _, err := s.q.GetShowByID(ctx, showID); err != nil {
+		return nil, err
+	}
+	return s.q.ListShowExternalIDs(ctx, showID)
+}
+
+// AddExternalID links ref to showID. Linking an id that already belongs to a show fails
+// with a unique violation.
+func (s *Service) AddExternalID(ctx context.Context, showID string, ref ExternalRef) (sqlc.ShowExternalID, error) {
+	if _, err := s.q.GetShowByID(ctx, showID); err != nil {
+		return sqlc.ShowExternalID{}, err
+	}
+	return s.q.CreateShowExternalID(ctx, sqlc.CreateShowExternalIDParams{
+		Provider:   ref.Provider,
+		ExternalID: ref.ID,
+		ShowID:     showID,
+	})
+}
+
+func (s *Service) RemoveExternalID(ctx context.Context, showID string, ref ExternalRef) error {
+	_, err := s.q.DeleteShowExternalID(ctx, sqlc.DeleteShowExternalIDParams{
+		ShowID:     showID,
+		Provider:   ref.Provider,
+		ExternalID: ref.ID,
+	})
+	return err
 }
 
 func (s *Service) ListWorkerData(ctx context.Context) ([]workerDataResponse, error) {
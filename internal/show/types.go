@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
 	"github.com/keithics/devops-dashboard/api/internal/hooks"
 )
@@ -12,6 +13,24 @@ const (
 	ctxCreateShowRequestKey = "show.create.request"
 	ctxUpdateShowRequestKey = "show.update.request"
 	ctxShowIDKey            = "show.id"
+	ctxExternalRefKey       = "show.external.ref"
+	ctxOnConflictKey        = "show.create.on_conflict"
+	ctxListOptsKey          = "show.list.opts"
+	ctxSearchOptsKey        = "show.search.opts"
+)
+
+const (
+	SortCreated   = "created"
+	SortUpdated   = "updated"
+	SortTitle     = "title"
+	SortStartDate = "startDate"
+
+	defaultListLimit = 50
+	maxListLimit     = 200
+	cursorTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
 )
 
 var errInvalidShowID = errors.New("invalid show id")
@@ -21,6 +40,10 @@ type Handler struct {
 }
 
 type Service struct {
+	// pool is nil in copies bound to a transaction by WithTx.
+	pool *pgxpool.Pool
+	// db runs the GET /shows queries, which are built per request rather than by sqlc.
+	db    sqlc.DBTX
 	q     *sqlc.Queries
 	hooks hooks.Dispatcher
 }
@@ -39,12 +62,102 @@ type Show struct {
 	BannerUrl      *string  `json:"bannerUrl,omitempty"`
 	SeasonCount    *int64   `json:"seasonCount,omitempty"`
 	EpisodeCount   *int64   `json:"episodeCount,omitempty"`
+	// LinkedIDs are further provider ids ("mal:5114", "imdb:tt0903747") the show is
+	// known by. They are stored in the show_external_ids table and are not echoed back
+	// on show responses; see /shows/{internalShowId}/external-ids.
+	LinkedIDs []string `json:"linkedIds,omitempty"`
+}
+
+// ExternalRef identifies a show at one provider.
+type ExternalRef struct {
+	Provider string
+	ID       string
+}
+
+// DuplicateShowError is returned by CreateShow when the show already exists and the
+// conflict policy is OnConflictReject.
+type DuplicateShowError struct {
+	Existing  sqlc.Show
+	MatchedBy string
+}
+
+// PreparedShow is a show create that has been through duplicate detection and its pre
+// hooks, ready for InsertShow. existing is set when it duplicates a stored show, and update
+// when that show is to be updated.
+type PreparedShow struct {
+	req        Show
+	onConflict string
+	existing   *sqlc.Show
+	update     *updateShowRequest
+}
+
+// ListShowsOpts filters, sorts and pages GET /shows. CursorKey and CursorID come from the
+// previous page's cursor and must have been issued for the same Sort and Desc; CursorKey
+// is nil after a show without a start date.
+type ListShowsOpts struct {
+	Type          *string
+	Status        *string
+	StartDateFrom *string
+	StartDateTo   *string
+	Title         *string
+	Sort          string
+	Desc          bool
+	Limit         int
+	CursorKey     *string
+	CursorID      *string
+}
+
+type ShowsPage struct {
+	Items      []sqlc.Show
+	NextCursor *string
+	Total      int64
+}
+
+type SearchShowsOpts struct {
+	Query string
+	Limit int
+}
+
+// SearchResult is one ranked local search hit. MatchedField names the show field that
+// scored highest: titlePreferred, titleOriginal, altTitles or synopsis.
+type SearchResult struct {
+	Show         sqlc.Show
+	Score        float64
+	MatchedField string
+}
+
+// showCursor is the opaque nextCursor: the sort key and id of the last show on a page.
+// Timestamps are kept as RFC 3339 with microseconds.
+type showCursor struct {
+	Sort string  `json:"s"`
+	Desc bool    `json:"d"`
+	Key  *string `json:"k"`
+	ID   string  `json:"id"`
+}
+
+// showListQuery collects the conditions and positional arguments of a GET /shows query.
+type showListQuery struct {
+	conditions []string
+	args       []any
+}
+
+// showListRow is a GET /shows row: the show and its title sort key.
+type showListRow struct {
+	Show     sqlc.Show
+	TitleKey string
 }
 
 type createShowRequest = Show
 
 type updateShowRequest = Show
 
+// updateShowHookPayload is the show.update.pre payload. A pre hook can patch Request;
+// the show being updated stays the one in the path.
+type updateShowHookPayload struct {
+	InternalShowID string             `json:"internalShowId"`
+	Request        *updateShowRequest `json:"request"`
+}
+
 type showResponse struct {
 	InternalShowID string `json:"internalShowId"`
 	Show
@@ -52,6 +165,44 @@ type showResponse struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+type listShowsResponse struct {
+	Items      []showResponse `json:"items"`
+	NextCursor *string        `json:"nextCursor"`
+	Total      int64          `json:"total"`
+}
+
+type searchShowResultResponse struct {
+	Show         showResponse `json:"show"`
+	Score        float64      `json:"score"`
+	MatchedField string       `json:"matchedField"`
+}
+
+type searchShowsResponse struct {
+	Items []searchShowResultResponse `json:"items"`
+}
+
+type duplicateShowDetails struct {
+	InternalShowID string `json:"internalShowId"`
+	MatchedBy      string `json:"matchedBy"`
+}
+
+type duplicateGroupResponse struct {
+	MatchedBy string         `json:"matchedBy"`
+	Key       string         `json:"key"`
+	Shows     []showResponse `json:"shows"`
+}
+
+type addExternalIDRequest struct {
+	ExternalID string `json:"externalId"`
+}
+
+type externalIDResponse struct {
+	Provider   string    `json:"provider"`
+	ID         string    `json:"id"`
+	ExternalID string    `json:"externalId"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
 type workerDataResponse struct {
 	InternalShowID string             `json:"internalShowId"`
 	Show           workerShowResponse `json:"show"`
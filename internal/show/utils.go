@@ -1,10 +1,18 @@
 package show
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/httperr"
 	"github.com/keithics/devops-dashboard/api/internal/httpx"
 	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
 )
@@ -20,6 +28,7 @@ func normalizeCreateShowRequest(req *createShowRequest) {
 		&req.PosterUrl,
 		&req.BannerUrl,
 		&req.AltTitles,
+		&req.LinkedIDs,
 	)
 }
 
@@ -34,6 +43,7 @@ func normalizeUpdateShowRequest(req *updateShowRequest) {
 		&req.PosterUrl,
 		&req.BannerUrl,
 		&req.AltTitles,
+		&req.LinkedIDs,
 	)
 }
 
@@ -47,6 +57,7 @@ func normalizeShowFields(
 	posterURL **string,
 	bannerURL **string,
 	altTitles *[]string,
+	linkedIDs *[]string,
 ) {
 	*externalID = normalizeutil.String(*externalID)
 	*titlePreferred = normalizeutil.String(*titlePreferred)
@@ -57,6 +68,7 @@ func normalizeShowFields(
 	*posterURL = normalizeutil.StringPtr(*posterURL)
 	*bannerURL = normalizeutil.StringPtr(*bannerURL)
 	*altTitles = normalizeutil.Strings(*altTitles)
+	*linkedIDs = normalizeutil.Strings(*linkedIDs)
 }
 
 func validateCreateShowRequest(req createShowRequest) error {
@@ -69,6 +81,7 @@ func validateCreateShowRequest(req createShowRequest) error {
 		req.EndDate,
 		req.SeasonCount,
 		req.EpisodeCount,
+		req.LinkedIDs,
 	)
 }
 
@@ -82,6 +95,7 @@ func validateUpdateShowRequest(req updateShowRequest) error {
 		req.EndDate,
 		req.SeasonCount,
 		req.EpisodeCount,
+		req.LinkedIDs,
 	)
 }
 
@@ -94,6 +108,7 @@ func validateShowPayload(
 	endDate *string,
 	seasonCount *int64,
 	episodeCount *int64,
+	linkedIDs []string,
 ) error {
 	if externalID != "" {
 		if err := httpx.ValidateVar(externalID, "max=128", "externalId is invalid"); err != nil {
@@ -121,9 +136,324 @@ func validateShowPayload(
 	if err := validateOptionalInt64(episodeCount, "gte=0", "episodeCount is invalid"); err != nil {
 		return err
 	}
+	if err := validateLinkedIDs(linkedIDs); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateListShowsOpts(opts ListShowsOpts) error {
+	if opts.Type != nil {
+		if err := httpx.ValidateVar(*opts.Type, "oneof=anime tv movie ova special", "type is invalid"); err != nil {
+			return err
+		}
+	}
+	if opts.Status != nil {
+		if err := httpx.ValidateVar(*opts.Status, "oneof=ongoing finished", "status is invalid"); err != nil {
+			return err
+		}
+	}
+	if err := httpx.ValidateOptionalDate(opts.StartDateFrom, "startDateFrom is invalid"); err != nil {
+		return err
+	}
+	if err := httpx.ValidateOptionalDate(opts.StartDateTo, "startDateTo is invalid"); err != nil {
+		return err
+	}
+	if opts.Title != nil {
+		if err := httpx.ValidateVar(*opts.Title, "max=200", "title is invalid"); err != nil {
+			return err
+		}
+	}
+	return httpx.ValidateVar(opts.Sort, "oneof=created updated title startDate", "sort must be one of created|updated|title|startDate")
+}
+
+// parseSortOrder defaults to newest first for timestamps and A to Z for titles and dates.
+func parseSortOrder(raw string, sort string) (bool, error) {
+	switch normalizeutil.LowerString(raw) {
+	case "":
+		return sort == SortCreated || sort == SortUpdated, nil
+	case "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	default:
+		return false, errors.New("order must be one of asc|desc")
+	}
+}
+
+func encodeShowCursor(cursor showCursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeShowCursor rejects cursors that are malformed or were issued for another sort.
+func decodeShowCursor(value string, sort string, desc bool) (showCursor, error) {
+	errInvalid := errors.New("cursor is invalid")
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return showCursor{}, errInvalid
+	}
+	var cursor showCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return showCursor{}, errInvalid
+	}
+	if err := validateShowID(cursor.ID); err != nil {
+		return showCursor{}, errInvalid
+	}
+	if cursor.Key == nil && cursor.Sort != SortStartDate {
+		return showCursor{}, errInvalid
+	}
+	if cursor.Key != nil && (cursor.Sort == SortCreated || cursor.Sort == SortUpdated) {
+		if _, err := time.Parse(cursorTimeLayout, *cursor.Key); err != nil {
+			return showCursor{}, errInvalid
+		}
+	}
+	if cursor.Sort != sort || cursor.Desc != desc {
+		return showCursor{}, errors.New("cursor does not match sort and order")
+	}
+	return cursor, nil
+}
+
+// likePattern turns a title substring into an ILIKE operand with wildcards escaped.
+func likePattern(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(*value)
+	return &escaped
+}
+
+// showListColumns are the sqlc.Show columns in the order scanShowRow reads them, then the
+// title sort key as SQL lowers it.
+const showListColumns = `s.internal_show_id,
+  s.title_preferred,
+  s.title_original,
+  s.alt_titles,
+  s.type,
+  s.status,
+  s.synopsis,
+  s.start_date,
+  s.end_date,
+  s.poster_url,
+  s.banner_url,
+  s.season_count,
+  s.episode_count,
+  s.external_ids,
+  s.created_at,
+  s.updated_at,
+  lower(s.title_preferred)`
+
+// showSortColumns are what each sort orders on, next to internal_show_id. Every one leads
+// a shows index, see migrations 000015 and 000023.
+var showSortColumns = map[string]string{
+	SortCreated:   "s.created_at",
+	SortUpdated:   "s.updated_at",
+	SortTitle:     "lower(s.title_preferred)",
+	SortStartDate: "s.start_date",
+}
+
+// newShowListQuery adds a condition only for the filters that are set, so each
+// combination is planned on its own instead of through optional parameters.
+//
+// GET /shows is built here rather than as a sqlc query because sqlc cannot parameterise
+// ORDER BY: one query would have to order on a CASE over the sort and direction, and an
+// ORDER BY CASE matches none of the sort indexes, nor does a "narg IS NULL OR" filter
+// once the plan is cached, so every page would sort the whole table.
+func newShowListQuery(opts ListShowsOpts) *showListQuery {
+	q := &showListQuery{}
+	if opts.Type != nil {
+		q.conditions = append(q.conditions, "s.type = "+q.arg(*opts.Type))
+	}
+	if opts.Status != nil {
+		q.conditions = append(q.conditions, "s.status = "+q.arg(*opts.Status))
+	}
+	if opts.StartDateFrom != nil {
+		q.conditions = append(q.conditions, "s.start_date >= "+q.arg(*opts.StartDateFrom))
+	}
+	if opts.StartDateTo != nil {
+		q.conditions = append(q.conditions, "s.start_date <= "+q.arg(*opts.StartDateTo))
+	}
+	if title := likePattern(opts.Title); title != nil {
+		pattern := "'%' || " + q.arg(*title) + "::text || '%'"
+		q.conditions = append(q.conditions, fmt.Sprintf(`(
+    s.title_preferred ILIKE %[1]s
+    OR s.title_original ILIKE %[1]s
+    OR EXISTS (
+      SELECT 1
+      FROM unnest(s.alt_titles) AS alt(title)
+      WHERE alt.title ILIKE %[1]s
+    )
+  )`, pattern))
+	}
+	return q
+}
+
+func (q *showListQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// addAfterCursor keeps the shows that come after the cursor in the page order. Shows
+// without a start date sort last A to Z and first Z to A, as NULLs do in the index.
+func (q *showListQuery) addAfterCursor(sort string, desc bool, key *string, id string) error {
+	column := showSortColumns[sort]
+	cmp := ">"
+	if desc {
+		cmp = "<"
+	}
+
+	if key == nil {
+		if sort != SortStartDate {
+			return errors.New("cursor has no sort key")
+		}
+		if desc {
+			q.conditions = append(q.conditions, fmt.Sprintf("(%s IS NOT NULL OR s.internal_show_id < %s::uuid)", column, q.arg(id)))
+		} else {
+			q.conditions = append(q.conditions, fmt.Sprintf("(%s IS NULL AND s.internal_show_id > %s::uuid)", column, q.arg(id)))
+		}
+		return nil
+	}
+
+	var value any = *key
+	cast := "::text"
+	if sort == SortCreated || sort == SortUpdated {
+		at, err := time.Parse(cursorTimeLayout, *key)
+		if err != nil {
+			return err
+		}
+		value, cast = at, "::timestamptz"
+	}
+	condition := fmt.Sprintf("(%s, s.internal_show_id) %s (%s%s, %s::uuid)", column, cmp, q.arg(value), cast, q.arg(id))
+	if sort == SortStartDate && !desc {
+		condition = fmt.Sprintf("(%s OR %s IS NULL)", condition, column)
+	}
+	q.conditions = append(q.conditions, condition)
 	return nil
 }
 
+func (q *showListQuery) where() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return "\nWHERE " + strings.Join(q.conditions, "\n  AND ")
+}
+
+func (q *showListQuery) countSQL() string {
+	return "SELECT COUNT(*)\nFROM shows s" + q.where()
+}
+
+func (q *showListQuery) pageSQL(sort string, desc bool, limit int) string {
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("SELECT\n  %s\nFROM shows s%s\nORDER BY %s %s, s.internal_show_id %s\nLIMIT %s",
+		showListColumns, q.where(), showSortColumns[sort], direction, direction, q.arg(limit))
+}
+
+func scanShowRow(row pgx.CollectableRow) (showListRow, error) {
+	var i showListRow
+	err := row.Scan(
+		&i.Show.InternalShowID,
+		&i.Show.TitlePreferred,
+		&i.Show.TitleOriginal,
+		&i.Show.AltTitles,
+		&i.Show.Type,
+		&i.Show.Status,
+		&i.Show.Synopsis,
+		&i.Show.StartDate,
+		&i.Show.EndDate,
+		&i.Show.PosterUrl,
+		&i.Show.BannerUrl,
+		&i.Show.SeasonCount,
+		&i.Show.EpisodeCount,
+		&i.Show.ExternalIds,
+		&i.Show.CreatedAt,
+		&i.Show.UpdatedAt,
+		&i.TitleKey,
+	)
+	return i, err
+}
+
+// showSortKey is the cursor key of row under sort. The title key comes from the query, so
+// it compares the way lower(title_preferred) does in SQL.
+func showSortKey(row showListRow, sort string) *string {
+	var key string
+	switch sort {
+	case SortUpdated:
+		key = row.Show.UpdatedAt.UTC().Format(cursorTimeLayout)
+	case SortTitle:
+		key = row.TitleKey
+	case SortStartDate:
+		return row.Show.StartDate
+	default:
+		key = row.Show.CreatedAt.UTC().Format(cursorTimeLayout)
+	}
+	return &key
+}
+
+func showFromSearchRow(row sqlc.SearchShowsRow) sqlc.Show {
+	return sqlc.Show{
+		InternalShowID: row.InternalShowID,
+		TitlePreferred: row.TitlePreferred,
+		TitleOriginal:  row.TitleOriginal,
+		AltTitles:      row.AltTitles,
+		Type:           row.Type,
+		Status:         row.Status,
+		Synopsis:       row.Synopsis,
+		StartDate:      row.StartDate,
+		EndDate:        row.EndDate,
+		PosterUrl:      row.PosterUrl,
+		BannerUrl:      row.BannerUrl,
+		SeasonCount:    row.SeasonCount,
+		EpisodeCount:   row.EpisodeCount,
+		ExternalIds:    row.ExternalIds,
+		CreatedAt:      row.CreatedAt,
+		UpdatedAt:      row.UpdatedAt,
+	}
+}
+
+func showFromSharingRow(row sqlc.ListShowsSharingExternalIDRow) sqlc.Show {
+	return sqlc.Show{
+		InternalShowID: row.InternalShowID,
+		TitlePreferred: row.TitlePreferred,
+		TitleOriginal:  row.TitleOriginal,
+		AltTitles:      row.AltTitles,
+		Type:           row.Type,
+		Status:         row.Status,
+		Synopsis:       row.Synopsis,
+		StartDate:      row.StartDate,
+		EndDate:        row.EndDate,
+		PosterUrl:      row.PosterUrl,
+		BannerUrl:      row.BannerUrl,
+		SeasonCount:    row.SeasonCount,
+		EpisodeCount:   row.EpisodeCount,
+		ExternalIds:    row.ExternalIds,
+		CreatedAt:      row.CreatedAt,
+		UpdatedAt:      row.UpdatedAt,
+	}
+}
+
+func validateSearchShowsOpts(opts SearchShowsOpts) error {
+	return httpx.ValidateVar(opts.Query, "required,max=200", "q is invalid")
+}
+
+func toSearchShowResultResponse(item SearchResult) (searchShowResultResponse, error) {
+	show, err := toShowResponse(item.Show)
+	if err != nil {
+		return searchShowResultResponse{}, err
+	}
+	return searchShowResultResponse{
+		Show:         show,
+		Score:        item.Score,
+		MatchedField: item.MatchedField,
+	}, nil
+}
+
 func validateShowID(id string) error {
 	return httpx.ValidateVar(id, "required,uuid4", "internalShowId is invalid")
 }
@@ -158,29 +488,107 @@ func unmarshalExternalID(raw []byte) (string, error) {
 	return strings.TrimSpace(payload.ExternalID), nil
 }
 
-func toShowResponse(show sqlc.Show) (showResponse, error) {
+func ExternalIDFromShow(show sqlc.Show) (string, error) {
+	return unmarshalExternalID(show.ExternalIds)
+}
+
+func ToShow(show sqlc.Show) (Show, error) {
 	externalID, err := unmarshalExternalID(show.ExternalIds)
+	if err != nil {
+		return Show{}, err
+	}
+	return Show{
+		ExternalID:     externalID,
+		TitlePreferred: show.TitlePreferred,
+		TitleOriginal:  show.TitleOriginal,
+		AltTitles:      normalizeutil.Strings(show.AltTitles),
+		Type:           show.Type,
+		Status:         show.Status,
+		Synopsis:       show.Synopsis,
+		StartDate:      show.StartDate,
+		EndDate:        show.EndDate,
+		PosterUrl:      show.PosterUrl,
+		BannerUrl:      show.BannerUrl,
+		SeasonCount:    show.SeasonCount,
+		EpisodeCount:   show.EpisodeCount,
+	}, nil
+}
+
+func toShowResponse(show sqlc.Show) (showResponse, error) {
+	item, err := ToShow(show)
 	if err != nil {
 		return showResponse{}, err
 	}
 	return showResponse{
 		InternalShowID: show.InternalShowID,
-		Show: Show{
-			ExternalID:     externalID,
-			TitlePreferred: show.TitlePreferred,
-			TitleOriginal:  show.TitleOriginal,
-			AltTitles:      normalizeutil.Strings(show.AltTitles),
-			Type:           show.Type,
-			Status:         show.Status,
-			Synopsis:       show.Synopsis,
-			StartDate:      show.StartDate,
-			EndDate:        show.EndDate,
-			PosterUrl:      show.PosterUrl,
-			BannerUrl:      show.BannerUrl,
-			SeasonCount:    show.SeasonCount,
-			EpisodeCount:   show.EpisodeCount,
-		},
-		CreatedAt: show.CreatedAt,
-		UpdatedAt: show.UpdatedAt,
+		Show:           item,
+		CreatedAt:      show.CreatedAt,
+		UpdatedAt:      show.UpdatedAt,
 	}, nil
 }
+
+func toExternalIDResponse(item sqlc.ShowExternalID) externalIDResponse {
+	return externalIDResponse{
+		Provider:   item.Provider,
+		ID:         item.ExternalID,
+		ExternalID: ExternalRef{Provider: item.Provider, ID: item.ExternalID}.String(),
+		CreatedAt:  item.CreatedAt,
+	}
+}
+
+// MergeShow overlays newer data, e.g. from a provider, on a stored show. Fields left empty
+// in latest keep their stored value, and the external ID, type and linked ids are never
+// changed; ids are linked separately.
+func MergeShow(current, latest Show) Show {
+	merged := current
+	if latest.TitlePreferred != "" {
+		merged.TitlePreferred = latest.TitlePreferred
+	}
+	if len(latest.AltTitles) > 0 {
+		merged.AltTitles = latest.AltTitles
+	}
+	if latest.Status != "" {
+		merged.Status = latest.Status
+	}
+	merged.TitleOriginal = firstNonNil(latest.TitleOriginal, current.TitleOriginal)
+	merged.Synopsis = firstNonNil(latest.Synopsis, current.Synopsis)
+	merged.StartDate = firstNonNil(latest.StartDate, current.StartDate)
+	merged.EndDate = firstNonNil(latest.EndDate, current.EndDate)
+	merged.PosterUrl = firstNonNil(latest.PosterUrl, current.PosterUrl)
+	merged.BannerUrl = firstNonNil(latest.BannerUrl, current.BannerUrl)
+	merged.SeasonCount = firstNonNil(latest.SeasonCount, current.SeasonCount)
+	merged.EpisodeCount = firstNonNil(latest.EpisodeCount, current.EpisodeCount)
+	return merged
+}
+
+func firstNonNil[T any](values ...*T) *T {
+	for _, value := range values {
+		if value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// AbortIfDuplicateErr answers a *DuplicateShowError with 409 and the existing show id.
+func AbortIfDuplicateErr(c *gin.Context, err error) bool {
+	httpErr, ok := DuplicateHTTPError(err)
+	if !ok {
+		return false
+	}
+	httperr.Abort(c, httpErr)
+	return true
+}
+
+// DuplicateHTTPError is the 409 for a *DuplicateShowError, for callers that report the
+// error somewhere other than the response status.
+func DuplicateHTTPError(err error) (*httperr.HTTPError, bool) {
+	var duplicate *DuplicateShowError
+	if !errors.As(err, &duplicate) {
+		return nil, false
+	}
+	return httperr.Conflict("show already exists").WithCause(err).WithDetails(duplicateShowDetails{
+		InternalShowID: duplicate.Existing.InternalShowID,
+		MatchedBy:      duplicate.MatchedBy,
+	}), true
+}
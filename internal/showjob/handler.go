@@ -0,0 +1,69 @@
+package showjob
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httperr"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
+
+// ListJobs godoc
+//
+//	@Summary		List show jobs
+//	@Description	List show import jobs, newest first, optionally filtered by status
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			status	query		string	false	"Job status: pending|processing|completed|failed"
+//	@Param			limit	query		int		false	"Limit (default 50, max 200)"
+//	@Success		200		{array}		JobResponse
+//	@Failure		400		{object}	httperr.APIErrorResponse
+//	@Failure		500		{object}	httperr.APIErrorResponse
+//	@Router			/jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	opts, ok := httpx.AbortIfMissingContext[ListOpts](c, ctxListOptsKey)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.List(c.Request.Context(), opts)
+	if err != nil {
+		if httpx.AbortIfDBErr(c, err, "failed to list jobs") {
+			return
+		}
+		httperr.Abort(c, httperr.Internal("failed to list jobs").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toJobResponses(items))
+}
+
+// GetJob godoc
+//
+//	@Summary		Get show job
+//	@Description	Get a show import job by internal job id
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			internalJobShowId	path		string	true	"Internal job UUID"
+//	@Success		200					{object}	JobResponse
+//	@Failure		400					{object}	httperr.APIErrorResponse
+//	@Failure		404					{object}	httperr.APIErrorResponse
+//	@Failure		500					{object}	httperr.APIErrorResponse
+//	@Router			/jobs/{internalJobShowId} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	jobID, ok := httpx.AbortIfMissingContext[string](c, ctxJobIDKey)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.GetByID(c.Request.Context(), jobID)
+	if err != nil {
+		if httpx.AbortIfDBErr(c, err, "failed to get job") {
+			return
+		}
+		httperr.Abort(c, httperr.Internal("failed to get job").WithCause(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, ToJobResponse(item))
+}
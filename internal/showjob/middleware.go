@@ -0,0 +1,32 @@
+package showjob
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+	normalizeutil "github.com/keithics/devops-dashboard/api/internal/utils/normalize"
+)
+
+func (h *Handler) BindJobID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobID := c.Param("internalJobShowId")
+		if httpx.AbortIfErr(c, validateJobID(jobID)) {
+			return
+		}
+		c.Set(ctxJobIDKey, jobID)
+		c.Next()
+	}
+}
+
+func (h *Handler) BindListOpts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := ListOpts{
+			Status: normalizeutil.StringValuePtr(normalizeutil.LowerString(c.Query("status"))),
+			Limit:  normalizeutil.Limit(httpx.ParsePositiveInt(c.Query("limit"), defaultListLimit), defaultListLimit, maxListLimit),
+		}
+		if httpx.AbortIfErr(c, validateStatus(opts.Status)) {
+			return
+		}
+		c.Set(ctxListOptsKey, opts)
+		c.Next()
+	}
+}
@@ -0,0 +1,8 @@
+package showjob
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.Engine, h *Handler) {
+	r.GET("/jobs", h.BindListOpts(), h.ListJobs)
+	r.GET("/jobs/:internalJobShowId", h.BindJobID(), h.GetJob)
+}
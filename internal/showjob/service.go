@@ -0,0 +1,129 @@
+package showjob
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+)
+
+func NewService(pool *pgxpool.Pool) *Service {
+	return &Service{
+		pool: pool,
+		q:    sqlc.New(pool),
+	}
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// EnqueueShow creates the show and a pending job in one transaction. When a pending or
+// processing job already exists for externalID, or for the show createShow resolved to,
+// it is returned instead and created is false.
+func (s *Service) EnqueueShow(ctx context.Context, externalID string, createShow CreateShowFunc) (job sqlc.JobShow, created bool, err error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return sqlc.JobShow{}, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := s.q.WithTx(tx)
+	if err := q.LockJobShowExternalID(ctx, externalID); err != nil {
+		return sqlc.JobShow{}, false, err
+	}
+
+	existing, err := q.GetActiveJobShowByExternalID(ctx, externalID)
+	if err == nil {
+		return existing, false, tx.Commit(ctx)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return sqlc.JobShow{}, false, err
+	}
+
+	showID, err := createShow(ctx, tx)
+	if err != nil {
+		return sqlc.JobShow{}, false, err
+	}
+
+	existing, err = q.GetActiveJobShowByShowID(ctx, showID)
+	if err == nil {
+		return existing, false, tx.Commit(ctx)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return sqlc.JobShow{}, false, err
+	}
+
+	job, err = q.CreateJobShow(ctx, showID)
+	if err != nil {
+		return sqlc.JobShow{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return sqlc.JobShow{}, false, err
+	}
+	return job, true, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, jobID string) (sqlc.JobShow, error) {
+	return s.q.GetJobShowByID(ctx, jobID)
+}
+
+func (s *Service) List(ctx context.Context, opts ListOpts) ([]sqlc.JobShow, error) {
+	return s.q.ListJobShows(ctx, sqlc.ListJobShowsParams{
+		Status:   opts.Status,
+		RowLimit: int32(opts.Limit),
+	})
+}
+
+// ClaimNext locks the oldest runnable pending job for workerID; it returns pgx.ErrNoRows when the queue is empty.
+func (s *Service) ClaimNext(ctx context.Context, workerID string) (sqlc.JobShow, error) {
+	return s.q.ClaimNextJobShow(ctx, &workerID)
+}
+
+func (s *Service) Complete(ctx context.Context, jobID string) (sqlc.JobShow, error) {
+	return s.q.CompleteJobShow(ctx, jobID)
+}
+
+func (s *Service) Retry(ctx context.Context, jobID string, cause error, runAfter time.Time) (sqlc.JobShow, error) {
+	return s.q.RetryJobShow(ctx, sqlc.RetryJobShowParams{
+		InternalJobShowID: jobID,
+		ErrorMessage:      errorMessage(cause),
+		RunAfter:          runAfter,
+	})
+}
+
+func (s *Service) Fail(ctx context.Context, jobID string, cause error) (sqlc.JobShow, error) {
+	return s.q.FailJobShow(ctx, sqlc.FailJobShowParams{
+		InternalJobShowID: jobID,
+		ErrorMessage:      errorMessage(cause),
+	})
+}
+
+// Requeue returns a claimed job to the pending queue without counting an attempt, for
+// imports interrupted by a shutdown.
+func (s *Service) Requeue(ctx context.Context, jobID string) (sqlc.JobShow, error) {
+	return s.q.RequeueJobShow(ctx, jobID)
+}
+
+// ReleaseStale handles jobs locked longer than staleAfter, e.g. by a crashed or hung
+// worker. Each counts as a failed attempt: it goes back to the pending queue, or fails
+// once maxRetries is reached or when the show already has another pending job.
+func (s *Service) ReleaseStale(ctx context.Context, staleAfter time.Duration, maxRetries int) (released int64, failed int64, err error) {
+	lockedBefore := time.Now().Add(-staleAfter)
+	failed, err = s.q.FailStaleJobShows(ctx, sqlc.FailStaleJobShowsParams{
+		ErrorMessage: staleJobError,
+		LockedBefore: lockedBefore,
+		MaxRetries:   int32(maxRetries),
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	released, err = s.q.ReleaseStaleJobShows(ctx, sqlc.ReleaseStaleJobShowsParams{
+		ErrorMessage: staleJobError,
+		LockedBefore: lockedBefore,
+	})
+	return released, failed, err
+}
@@ -0,0 +1,92 @@
+package showjob
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultConcurrency  = 1
+	defaultMaxRetries   = 5
+	defaultJobTimeout   = 5 * time.Minute
+	defaultStaleAfter   = 15 * time.Minute
+	baseRetryDelay      = 30 * time.Second
+	maxRetryDelay       = time.Hour
+	maxErrorMessageLen  = 1000
+	defaultListLimit    = 50
+	maxListLimit        = 200
+)
+
+const (
+	// finishTimeout bounds the write of a job's result, which still runs after the
+	// worker's context is cancelled at shutdown.
+	finishTimeout = 10 * time.Second
+	staleJobError = "worker stopped responding"
+)
+
+const (
+	ctxJobIDKey    = "showjob.id"
+	ctxListOptsKey = "showjob.list.opts"
+)
+
+// Importer runs the metadata import for the show referenced by a job.
+type Importer interface {
+	ImportShow(ctx context.Context, internalShowID string) error
+}
+
+// CreateShowFunc returns the internal id of the show a new job points at, inserting it
+// with tx when no stored show matches.
+type CreateShowFunc func(ctx context.Context, tx pgx.Tx) (string, error)
+
+type Handler struct {
+	svc *Service
+}
+
+type Service struct {
+	pool *pgxpool.Pool
+	q    *sqlc.Queries
+}
+
+type ListOpts struct {
+	Status *string
+	Limit  int
+}
+
+type JobResponse struct {
+	InternalJobShowID string     `json:"internalJobShowId"`
+	InternalShowID    string     `json:"internalShowId"`
+	Status            string     `json:"status"`
+	RetryCount        int32      `json:"retryCount"`
+	ErrorMessage      *string    `json:"errorMessage,omitempty"`
+	RunAfter          time.Time  `json:"runAfter"`
+	LockedAt          *time.Time `json:"lockedAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+type WorkerOptions struct {
+	PollInterval time.Duration
+	Concurrency  int
+	MaxRetries   int
+	JobTimeout   time.Duration
+	StaleAfter   time.Duration
+}
+
+type Worker struct {
+	svc      *Service
+	importer Importer
+	id       string
+	opts     WorkerOptions
+}
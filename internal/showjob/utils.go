@@ -0,0 +1,83 @@
+package showjob
+
+import (
+	"time"
+
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/httpx"
+)
+
+func normalizeWorkerOptions(opts WorkerOptions) WorkerOptions {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.MaxRetries < 1 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.JobTimeout <= 0 {
+		opts.JobTimeout = defaultJobTimeout
+	}
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = defaultStaleAfter
+	}
+	return opts
+}
+
+// retryDelay doubles the base delay for every previous attempt, capped at maxRetryDelay.
+func retryDelay(retryCount int32) time.Duration {
+	delay := baseRetryDelay
+	for i := int32(0); i < retryCount; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+func errorMessage(err error) *string {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	if len(message) > maxErrorMessageLen {
+		message = message[:maxErrorMessageLen]
+	}
+	return &message
+}
+
+func validateJobID(id string) error {
+	return httpx.ValidateVar(id, "required,uuid4", "internalJobShowId is invalid")
+}
+
+func validateStatus(status *string) error {
+	if status == nil {
+		return nil
+	}
+	return httpx.ValidateVar(*status, "oneof=pending processing completed failed", "status must be one of pending|processing|completed|failed")
+}
+
+func ToJobResponse(job sqlc.JobShow) JobResponse {
+	return JobResponse{
+		InternalJobShowID: job.InternalJobShowID,
+		InternalShowID:    job.ShowID,
+		Status:            job.Status,
+		RetryCount:        job.RetryCount,
+		ErrorMessage:      job.ErrorMessage,
+		RunAfter:          job.RunAfter,
+		LockedAt:          job.LockedAt,
+		CreatedAt:         job.CreatedAt,
+		UpdatedAt:         job.UpdatedAt,
+	}
+}
+
+func toJobResponses(items []sqlc.JobShow) []JobResponse {
+	out := make([]JobResponse, 0, len(items))
+	for _, item := range items {
+		out = append(out, ToJobResponse(item))
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package showjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/keithics/devops-dashboard/api/internal/db/sqlc"
+	"github.com/keithics/devops-dashboard/api/internal/utils/workerid"
+)
+
+func NewWorker(svc *Service, importer Importer, opts WorkerOptions) *Worker {
+	return &Worker{
+		svc:      svc,
+		importer: importer,
+		id:       workerid.New(),
+		opts:     normalizeWorkerOptions(opts),
+	}
+}
+
+// Run polls the job queue until ctx is cancelled. Several workers, in one
+// process or many, can share a database because claims use SKIP LOCKED.
+func (w *Worker) Run(ctx context.Context) {
+	log.Printf("show job worker %s started concurrency=%d", w.id, w.opts.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("show job worker %s stopped", w.id)
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) drain(ctx context.Context) {
+	released, failed, err := w.svc.ReleaseStale(ctx, w.opts.StaleAfter, w.opts.MaxRetries)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("show job worker %s failed to release stale jobs: %v", w.id, err)
+	} else if released > 0 || failed > 0 {
+		log.Printf("show job worker %s released %d and failed %d stale jobs", w.id, released, failed)
+	}
+
+	for ctx.Err() == nil {
+		processed, err := w.processNext(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("show job worker %s: %v", w.id, err)
+			}
+			return
+		}
+		if !processed {
+			return
+		}
+	}
+}
+
+func (w *Worker) processNext(ctx context.Context) (bool, error) {
+	job, err := w.svc.ClaimNext(ctx, w.id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim job: %w", err)
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, w.opts.JobTimeout)
+	importErr := w.importer.ImportShow(jobCtx, job.ShowID)
+	cancel()
+
+	// The result is written even when ctx was cancelled meanwhile, so a shutdown does not
+	// leave the job processing until it goes stale.
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+	if err := w.finish(finishCtx, job, importErr, ctx.Err() != nil); err != nil {
+		return true, fmt.Errorf("record result for job %s: %w", job.InternalJobShowID, err)
+	}
+	return true, nil
+}
+
+// finish records the outcome of an import. An import interrupted by a shutdown goes back
+// to the queue without counting as a failed attempt.
+func (w *Worker) finish(ctx context.Context, job sqlc.JobShow, importErr error, interrupted bool) error {
+	if importErr != nil && interrupted {
+		log.Printf("show job %s interrupted by shutdown, requeued", job.InternalJobShowID)
+		_, err := w.svc.Requeue(ctx, job.InternalJobShowID)
+		return err
+	}
+	if importErr == nil {
+		_, err := w.svc.Complete(ctx, job.InternalJobShowID)
+		return err
+	}
+
+	attempt := int(job.RetryCount) + 1
+	if attempt >= w.opts.MaxRetries {
+		log.Printf("show job %s failed permanently after %d attempts: %v", job.InternalJobShowID, attempt, importErr)
+		_, err := w.svc.Fail(ctx, job.InternalJobShowID, importErr)
+		return err
+	}
+
+	delay := retryDelay(job.RetryCount)
+	log.Printf("show job %s attempt %d failed, retrying in %s: %v", job.InternalJobShowID, attempt, delay, importErr)
+	_, err := w.svc.Retry(ctx, job.InternalJobShowID, importErr, time.Now().Add(delay))
+	return err
+}
@@ -0,0 +1,196 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize lowercases value, folds compatibility forms and Latin accents ("Pokémon",
+// full-width letters) and reduces punctuation and repeated whitespace to single spaces,
+// so "Frieren: Beyond Journey's End" and "frieren beyond journeys end" compare equal.
+func Normalize(value string) string {
+	var b strings.Builder
+	b.Grow(len(value))
+	space := false
+	latin := false
+	for _, r := range norm.NFKD.String(strings.ToLower(value)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Accents decompose into marks after their base letter. Only Latin accents are
+			// dropped; marks such as kana dakuten change the letter itself.
+			if !latin {
+				b.WriteRune(r)
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			latin = unicode.Is(unicode.Latin, r)
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// Similarity returns how alike a and b are after normalization, from 0 (nothing in common)
+// to 1 (equal), based on the Levenshtein distance over runes.
+func Similarity(a string, b string) float64 {
+	ra := []rune(Normalize(a))
+	rb := []rune(Normalize(b))
+	if len(ra) == 0 && len(rb) == 0 {
+		return 0
+	}
+	longest := max(len(ra), len(rb))
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// BestSimilarity returns the highest Similarity between any value of left and any of right.
+func BestSimilarity(left []string, right []string) float64 {
+	best := 0.0
+	for _, a := range left {
+		for _, b := range right {
+			best = max(best, Similarity(a, b))
+			if best == 1 {
+				return best
+			}
+		}
+	}
+	return best
+}
+
+// MatchScore rates how well query matches title, from 0 to 1. Containment scores 1;
+// otherwise the better of token-set and trigram similarity is used, so reordered or
+// missing words ("shingeki kyojin") and typos still score well.
+func MatchScore(query string, title string) float64 {
+	q := Normalize(query)
+	t := Normalize(title)
+	if q == "" || t == "" {
+		return 0
+	}
+	if strings.Contains(t, q) {
+		return 1
+	}
+	return max(tokenSetSimilarity(q, t), trigramSimilarity(q, t))
+}
+
+// BestMatchScore returns the highest MatchScore of query against any of titles.
+func BestMatchScore(query string, titles []string) float64 {
+	best := 0.0
+	for _, title := range titles {
+		best = max(best, MatchScore(query, title))
+		if best == 1 {
+			return best
+		}
+	}
+	return best
+}
+
+// tokenSetSimilarity compares the words a and b share against each side's leftovers,
+// ignoring word order and duplicates. Both inputs must already be normalized.
+func tokenSetSimilarity(a string, b string) float64 {
+	wordsA := wordSet(a)
+	wordsB := wordSet(b)
+
+	var common, onlyA, onlyB []string
+	for word := range wordsA {
+		if wordsB[word] {
+			common = append(common, word)
+		} else {
+			onlyA = append(onlyA, word)
+		}
+	}
+	for word := range wordsB {
+		if !wordsA[word] {
+			onlyB = append(onlyB, word)
+		}
+	}
+	sort.Strings(common)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+
+	base := strings.Join(common, " ")
+	withA := strings.TrimSpace(base + " " + strings.Join(onlyA, " "))
+	withB := strings.TrimSpace(base + " " + strings.Join(onlyB, " "))
+	return max(ratio(base, withA), ratio(base, withB), ratio(withA, withB))
+}
+
+// trigramSimilarity is the share of three-letter sequences a and b have in common, with
+// words padded the way pg_trgm pads them. Both inputs must already be normalized.
+func trigramSimilarity(a string, b string) float64 {
+	gramsA := trigrams(a)
+	gramsB := trigrams(b)
+	if len(gramsA) == 0 || len(gramsB) == 0 {
+		return 0
+	}
+	shared := 0
+	for gram := range gramsA {
+		if gramsB[gram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(gramsA)+len(gramsB)-shared)
+}
+
+func wordSet(value string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(value) {
+		words[word] = true
+	}
+	return words
+}
+
+func trigrams(value string) map[string]bool {
+	grams := make(map[string]bool)
+	for _, word := range strings.Fields(value) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			grams[string(padded[i:i+3])] = true
+		}
+	}
+	return grams
+}
+
+// ratio is the Levenshtein similarity of two normalized strings.
+func ratio(a string, b string) float64 {
+	ra := []rune(a)
+	rb := []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	longest := max(len(ra), len(rb))
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a []rune, b []rune) int {
+	if len(a) == 0 {
+		return len(b)
+	}
+	if len(b) == 0 {
+		return len(a)
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,120 @@
+package fuzzy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "punctuation and apostrophes", value: "Frieren: Beyond Journey's End", want: "frieren beyond journeys end"},
+		{name: "typographic apostrophe", value: "It’s MyGO!!!!!", want: "its mygo"},
+		{name: "latin accents", value: "Pokémon Café", want: "pokemon cafe"},
+		{name: "full-width forms", value: "ＳＰＹ×ＦＡＭＩＬＹ", want: "spy family"},
+		{name: "repeated and edge whitespace", value: "  Re:Zero  -  Starting\tLife  ", want: "re zero starting life"},
+		{name: "digits kept", value: "Mob Psycho 100", want: "mob psycho 100"},
+		{name: "only punctuation", value: "?!...", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.value); got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeKeepsKanaMarks(t *testing.T) {
+	if Normalize("ガッコウ") == Normalize("カッコウ") {
+		t.Fatal("dakuten must not be dropped like a Latin accent")
+	}
+	if Normalize("がっこうぐらし！") != Normalize("がっこうぐらし") {
+		t.Fatal("kana titles differing only in punctuation must normalize equal")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want float64
+	}{
+		{name: "equal after normalization", a: "Steins;Gate", b: "steins gate", want: 1},
+		{name: "accent and case", a: "POKÉMON", b: "pokemon", want: 1},
+		{name: "one letter typo", a: "Sousou no Frieren", b: "Sousou no Frieran", want: 1 - 1.0/17},
+		{name: "extra words", a: "Attack on Titan", b: "Attack on Titan Season 2", want: 1 - 9.0/24},
+		{name: "nothing in common", a: "Naruto", b: "Bleach", want: 0},
+		{name: "both empty", a: "", b: "!!", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); !approxEqual(got, tt.want) {
+				t.Fatalf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestSimilarity(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  []string
+		right []string
+		want  float64
+	}{
+		{name: "alternative title matches", left: []string{"Shingeki no Kyojin", "Attack on Titan"}, right: []string{"attack on titan"}, want: 1},
+		{name: "best pair wins", left: []string{"Naruto", "Frieren"}, right: []string{"Bleach", "Frieran"}, want: 1 - 1.0/7},
+		{name: "empty side", left: []string{"Naruto"}, right: nil, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BestSimilarity(tt.left, tt.right); !approxEqual(got, tt.want) {
+				t.Fatalf("BestSimilarity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		title   string
+		atLeast float64
+		below   float64
+	}{
+		{name: "containment", query: "titan", title: "Attack on Titan", atLeast: 1, below: 2},
+		{name: "missing word", query: "shingeki kyojin", title: "Shingeki no Kyojin", atLeast: 1, below: 2},
+		{name: "reordered words", query: "journey frieren", title: "Frieren Journey", atLeast: 0.99, below: 2},
+		{name: "typo", query: "frieran", title: "Frieren", atLeast: 0.7, below: 1},
+		{name: "unrelated", query: "one piece", title: "Naruto", atLeast: 0, below: 0.3},
+		{name: "empty query", query: "", title: "Naruto", atLeast: 0, below: 0.01},
+		{name: "punctuation only title", query: "naruto", title: "...", atLeast: 0, below: 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchScore(tt.query, tt.title)
+			if got < tt.atLeast || got >= tt.below {
+				t.Fatalf("MatchScore(%q, %q) = %v, want in [%v, %v)", tt.query, tt.title, got, tt.atLeast, tt.below)
+			}
+		})
+	}
+}
+
+func TestBestMatchScore(t *testing.T) {
+	if got := BestMatchScore("titan", []string{"Naruto", "Attack on Titan"}); got != 1 {
+		t.Fatalf("BestMatchScore = %v, want 1", got)
+	}
+	if got := BestMatchScore("titan", nil); got != 0 {
+		t.Fatalf("BestMatchScore without titles = %v, want 0", got)
+	}
+}
+
+func approxEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
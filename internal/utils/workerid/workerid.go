@@ -0,0 +1,15 @@
+package workerid
+
+import (
+	"fmt"
+	"os"
+)
+
+// New names this process in the locked_by column of claimed jobs and deliveries.
+func New() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}